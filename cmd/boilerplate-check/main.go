@@ -32,9 +32,11 @@ func main() {
 
 	commands.AddAll(rootCmd)
 
+	// Violations exit with a different status than failures of the
+	// tool itself, so that CI can tell them apart.
 	err := rootCmd.Execute()
 	if err != nil {
-		fmt.Printf("ERROR: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(commands.ExitCode(err))
 	}
 }
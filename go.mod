@@ -3,6 +3,10 @@ module github.com/mattmoor/boilerplate-check
 go 1.14
 
 require (
+	github.com/fsnotify/fsnotify v1.4.9
 	github.com/google/go-cmp v0.2.0
 	github.com/spf13/cobra v1.0.0
+	golang.org/x/sys v0.10.0 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+	sigs.k8s.io/yaml v1.2.0
 )
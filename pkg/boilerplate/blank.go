@@ -0,0 +1,55 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import "fmt"
+
+// WithLeadingBlankLines lets blank lines at the top of a file, as some
+// editors and generators emit, precede the header without counting
+// against the lines searched for it (see WithMaxHeaderLines).
+func WithLeadingBlankLines() Option {
+	return func(c *Checker) {
+		c.skipLeadingBlank = true
+	}
+}
+
+// WithoutLeadingBlankLines reports blank lines at the top of a file that
+// precede its header, with a fix that removes them.
+func WithoutLeadingBlankLines() Option {
+	return func(c *Checker) {
+		c.forbidLeadingBlank = true
+	}
+}
+
+// checkLeadingBlanks returns a violation if the first n lines of the file,
+// which are blank, precede the header at start.
+func (c *Checker) checkLeadingBlanks(path string, n, start int) []Violation {
+	if n == 0 || n > start {
+		return nil
+	}
+	detail := fmt.Sprintf("lines 1-%d are blank and precede the boilerplate at line %d", n, start+1)
+	if n == 1 {
+		detail = fmt.Sprintf("line 1 is blank and precedes the boilerplate at line %d", start+1)
+	}
+	return []Violation{{
+		Path:   path,
+		Line:   1,
+		Kind:   Misplaced,
+		Detail: detail,
+		Fix:    &Edit{Start: 0, End: n},
+	}}
+}
@@ -0,0 +1,883 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Checker checks that the headers of files match a boilerplate.  The
+// boilerplate and options are prepared once by NewChecker, so a Checker
+// should be reused across files, and may be used concurrently.
+type Checker struct {
+	// canonical is the boilerplate as it is written by fixes, lines
+	// is the form of it that we compare with files, and cased is that
+	// form in the case of the boilerplate, which we report.
+	canonical  []string
+	lines      []string
+	cased      []string
+	extensions []string
+	patterns   []string
+	excludes   []*regexp.Regexp
+
+	// spdx is the license that files must identify, instead of
+	// starting with the boilerplate, and copyright is the pattern of
+	// the copyright line that must accompany it.
+	spdx      string
+	copyright *regexp.Regexp
+
+	// forbidden is whether files must not start with the boilerplate.
+	forbidden bool
+
+	// comments holds the comment styles that headers must be written
+	// in, by extension or base name.
+	comments map[string]CommentStyle
+
+	// holder, if any, is the copyright holder that headers must name.
+	holder string
+
+	// generatedFiles, if any, matches the paths of the files that must
+	// have a line that generatedMarker matches.
+	generatedFiles  *regexp.Regexp
+	generatedMarker *regexp.Regexp
+
+	// aliases are the pairs of old and new text that are substituted
+	// into lines, in order, before they are compared.
+	aliases [][2]string
+
+	maxHeaderLines           int
+	maxHeaderBytes           int
+	maxLineLength            int
+	matchAnywhere            bool
+	allowLeadingLines        bool
+	skipLeadingBlank         bool
+	forbidLeadingBlank       bool
+	requireAtTop             bool
+	ignoreTrailingWhitespace bool
+	ignoreLeadingWhitespace  bool
+	ignoreCase               bool
+	normalizeUnicode         bool
+	literalYears             bool
+	tabWidth                 int
+	collapseBlankLines       bool
+	requireCurrentYear       bool
+	yearLists                bool
+	minYear, maxYear         int
+	allMismatches            bool
+	forbidDuplicates         bool
+	columns                  bool
+	blockAnchor              bool
+	requireTrailingBlank     bool
+	goPackage                bool
+	rewriteHeaders           bool
+	forbidRemnants           bool
+	exactLength              bool
+	foundLines               int
+
+	// wildcards holds, for each line of the boilerplate, whether it is
+	// a WildcardLine.
+	wildcards []bool
+
+	// openers match the lines that may open a header instead of the
+	// first line of the boilerplate.
+	openers []*regexp.Regexp
+
+	// diffContext is the number of lines of context around changes
+	// in the diffs of mismatched headers, or -1 for a diff of the
+	// rest of the header.
+	diffContext int
+}
+
+// Option configures optional behavior of a Checker.
+type Option func(*Checker)
+
+// WithLeadingLines permits shebang, build tag, and blank lines to
+// precede the boilerplate.
+func WithLeadingLines() Option {
+	return func(c *Checker) {
+		c.allowLeadingLines = true
+	}
+}
+
+// WithMaxHeaderLines sets how many lines (after any allowed leading
+// lines) are searched for the start of the header, 10 by default.
+func WithMaxHeaderLines(n int) Option {
+	return func(c *Checker) {
+		c.maxHeaderLines = n
+	}
+}
+
+// WithMaxHeaderBytes searches the first n bytes of a file for the start
+// of the header, instead of a number of lines, so that the cost of
+// checking files with very long lines stays predictable.  Leading lines
+// count against the n bytes, as does the newline ending each line.
+func WithMaxHeaderBytes(n int) Option {
+	return func(c *Checker) {
+		c.maxHeaderBytes = n
+	}
+}
+
+// WithMaxLineLength lets lines of files be as long as n bytes, instead of
+// bufio.MaxScanTokenSize, so that files with very long lines, such as
+// minified ones, can be checked.  A file with a longer line among those
+// that are read fails to be checked, with an error that says so.
+func WithMaxLineLength(n int) Option {
+	return func(c *Checker) {
+		c.maxLineLength = n
+	}
+}
+
+// WithMatchAnywhere searches the whole file for the header, instead of
+// only its leading lines.  The search stops at the first place that the
+// header matches in full.
+func WithMatchAnywhere() Option {
+	return func(c *Checker) {
+		c.matchAnywhere = true
+	}
+}
+
+// WithHeaderAtTop requires that nothing but blank lines (and, with
+// WithLeadingLines, shebang and build tag lines) precede the header.
+func WithHeaderAtTop() Option {
+	return func(c *Checker) {
+		c.requireAtTop = true
+	}
+}
+
+// WithoutTrailingWhitespace ignores spaces and tabs at the end of
+// lines when comparing them with the boilerplate.
+func WithoutTrailingWhitespace() Option {
+	return func(c *Checker) {
+		c.ignoreTrailingWhitespace = true
+	}
+}
+
+// WithoutLeadingWhitespace ignores spaces and tabs at the beginning of
+// lines when comparing them with the boilerplate.
+func WithoutLeadingWhitespace() Option {
+	return func(c *Checker) {
+		c.ignoreLeadingWhitespace = true
+	}
+}
+
+// WithTabWidth treats each tab in the indentation of a line as n spaces
+// when comparing it with the boilerplate, so that headers indented with
+// tabs match a boilerplate indented with spaces, and vice versa.  Tabs
+// after the first character that is not a space or tab still match only
+// tabs.
+func WithTabWidth(n int) Option {
+	return func(c *Checker) {
+		c.tabWidth = n
+	}
+}
+
+// WithoutCaseSensitivity ignores differences in case when comparing
+// lines with the boilerplate.  Fixes still write the boilerplate as is.
+func WithoutCaseSensitivity() Option {
+	return func(c *Checker) {
+		c.ignoreCase = true
+	}
+}
+
+// WithNormalizedUnicode lets curly quotes, en and em dashes, and
+// non-breaking spaces match the ASCII quotes, hyphens, and spaces they
+// stand for, as when a word processor has smartened the punctuation of a
+// header.  Fixes still write the boilerplate as is.
+func WithNormalizedUnicode() Option {
+	return func(c *Checker) {
+		c.normalizeUnicode = true
+	}
+}
+
+// WithAlias substitutes new for each occurrence of old in the lines of
+// files (and the boilerplate) before they are compared, so that headers
+// naming a former copyright holder still match during a migration.
+// Aliases are matched after years become YYYY, as do the years in old
+// and new, and are applied in the order they are given, each to the
+// result of the one before.  Fixes still write the boilerplate as is.
+func WithAlias(old, new string) Option {
+	return func(c *Checker) {
+		c.aliases = append(c.aliases, [2]string{old, new})
+	}
+}
+
+// WithLiteralYears stops years in headers and the boilerplate becoming
+// YYYY before they are compared, so that a header must have the very
+// years of the boilerplate (and YYYY in the boilerplate matches only
+// YYYY).  The other options that relax how lines compare still apply.
+func WithLiteralYears() Option {
+	return func(c *Checker) {
+		c.literalYears = true
+	}
+}
+
+// WithCollapsedBlankLines lets the blank lines that end the boilerplate
+// match any number of blank lines, including none, so that files need not
+// agree on how the header is separated from what follows.  Blank lines
+// within the boilerplate must still match exactly, and fixes still write
+// the boilerplate as is.
+func WithCollapsedBlankLines() Option {
+	return func(c *Checker) {
+		c.collapseBlankLines = true
+	}
+}
+
+// WithTrailingBlankLine reports a header that matches in full, but is
+// followed directly by anything but a blank line, e.g. code.  A header
+// whose boilerplate ends in a blank line is always followed by one, and
+// so is one at the end of the file.  The fix inserts a blank line.
+func WithTrailingBlankLine() Option {
+	return func(c *Checker) {
+		c.requireTrailingBlank = true
+	}
+}
+
+// WithAllMismatches reports each line of the header that differs from
+// the boilerplate, instead of only the first (with a diff of the rest).
+func WithAllMismatches() Option {
+	return func(c *Checker) {
+		c.allMismatches = true
+	}
+}
+
+// WithoutDuplicates reports a second header that starts within the
+// maximum number of header lines after the end of the first, as a bad
+// merge might leave behind.
+func WithoutDuplicates() Option {
+	return func(c *Checker) {
+		c.forbidDuplicates = true
+	}
+}
+
+// WithBlockAnchor reports a mismatched header at the line it starts at,
+// with a diff of the whole header, instead of at the first line that
+// differs with a diff from there on.  That suits people reading the
+// whole file, but a reviewdog filtered to a change's diff drops it when
+// the header's first line isn't part of the change.  It has no effect
+// with WithAllMismatches, and leaves the column unset.
+func WithBlockAnchor() Option {
+	return func(c *Checker) {
+		c.blockAnchor = true
+	}
+}
+
+// WithFoundLines shows the first n lines of the file that were searched
+// in the Found of Missing and Incomplete violations, so that a header
+// that starts too far down to be found, or that differs from its first
+// line on, can be told apart from one that is truly missing.
+func WithFoundLines(n int) Option {
+	return func(c *Checker) {
+		c.foundLines = n
+	}
+}
+
+// WithFilePatterns also checks files whose base names match one of the
+// given glob patterns (see filepath.Match), e.g. "Dockerfile", whatever
+// their extensions.
+func WithFilePatterns(patterns ...string) Option {
+	return func(c *Checker) {
+		c.patterns = append(c.patterns, patterns...)
+	}
+}
+
+// NewChecker returns a Checker for files with one of the given extensions
+// (without the leading ".") whose paths match none of excludes, which
+// should start with the given lines of boilerplate.
+func NewChecker(boilerplate, extensions []string, excludes []*regexp.Regexp, opts ...Option) *Checker {
+	c := &Checker{
+		canonical:      make([]string, 0, len(boilerplate)),
+		lines:          make([]string, 0, len(boilerplate)),
+		cased:          make([]string, 0, len(boilerplate)),
+		extensions:     make([]string, 0, len(extensions)),
+		excludes:       excludes,
+		maxHeaderLines: 10,
+		maxLineLength:  bufio.MaxScanTokenSize,
+		diffContext:    -1,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	for i, alias := range c.aliases {
+		c.aliases[i] = [2]string{c.normalizeYears(alias[0]), c.normalizeYears(alias[1])}
+	}
+	for _, line := range boilerplate {
+		c.canonical = append(c.canonical, c.normalizeYears(line))
+		c.cased = append(c.cased, c.normalizeCased(line))
+		c.lines = append(c.lines, c.normalize(line))
+		c.wildcards = append(c.wildcards, isWildcard(line))
+	}
+	if c.collapseBlankLines {
+		// Only compare through the last non-blank line.
+		for len(c.lines) > 1 && strings.TrimSpace(c.lines[len(c.lines)-1]) == "" {
+			c.lines = c.lines[:len(c.lines)-1]
+		}
+		c.cased = c.cased[:len(c.lines)]
+	}
+	for _, ext := range extensions {
+		// filepath.Ext returns the leading "."
+		c.extensions = append(c.extensions, "."+ext)
+	}
+	return c
+}
+
+// normalize returns the form of line that is compared: typographic
+// punctuation is replaced by ASCII if we normalize unicode, years and
+// ranges (or, if we allow them, lists) of years are replaced by YYYY,
+// aliases are substituted, tabs in the indentation are expanded if we
+// have a tab width, any whitespace we ignore is trimmed, and the line is
+// lowercased if we ignore case.
+func (c *Checker) normalize(line string) string {
+	line = c.normalizeCased(line)
+	if c.ignoreCase {
+		line = strings.ToLower(line)
+	}
+	return line
+}
+
+// normalizeCased normalizes line as normalize does, but keeps its case,
+// so that it can be reported as written (with YYYY for its years).
+func (c *Checker) normalizeCased(line string) string {
+	line = c.normalizeYears(line)
+	if c.normalizeUnicode {
+		line = strings.Map(toASCII, line)
+	}
+	if !c.literalYears {
+		line = strings.ReplaceAll(line, "YYYY-YYYY", "YYYY")
+	}
+	if c.yearLists {
+		line = collapseYears(line)
+	}
+	for _, alias := range c.aliases {
+		line = strings.ReplaceAll(line, alias[0], alias[1])
+	}
+	if c.tabWidth > 0 {
+		line = c.expandIndent(line)
+	}
+	if c.ignoreTrailingWhitespace {
+		line = strings.TrimRight(line, " \t")
+	}
+	if c.ignoreLeadingWhitespace {
+		line = strings.TrimLeft(line, " \t")
+	}
+	return line
+}
+
+// shown returns the lines of the boilerplate and of the header (given as
+// normalized lines and as raw) to show in a diff.  When we ignore case,
+// the lines we compare are lowercased, so the header's lines are shown
+// in their own case instead, and those that match as the boilerplate's.
+func (c *Checker) shown(lines, raw []string) ([]string, []string) {
+	if !c.ignoreCase {
+		return c.lines, lines
+	}
+	got := make([]string, len(lines))
+	for i := range lines {
+		if i < len(c.lines) && lines[i] == c.lines[i] {
+			got[i] = c.cased[i]
+		} else {
+			got[i] = c.normalizeCased(raw[i])
+		}
+	}
+	return c.cased, got
+}
+
+// expandIndent replaces each tab in the indentation of line with
+// tabWidth spaces.
+func (c *Checker) expandIndent(line string) string {
+	indent := len(line) - len(strings.TrimLeft(line, " \t"))
+	if !strings.Contains(line[:indent], "\t") {
+		return line
+	}
+	spaces := strings.Repeat(" ", c.tabWidth)
+	return strings.ReplaceAll(line[:indent], "\t", spaces) + line[indent:]
+}
+
+// SkipReason returns why the file at path should not be checked,
+// or "" if it should be.
+func (c *Checker) SkipReason(path string) string {
+	// Check whether the file extension or name matches.
+	if !c.hasExtension(filepath.Ext(path)) && !c.hasPattern(filepath.Base(path)) {
+		if len(c.patterns) > 0 {
+			return "extension or pattern"
+		}
+		return "extension"
+	}
+
+	// Check whether the file is excluded by a pattern.
+	return c.ExcludeReason(path)
+}
+
+// ExcludeReason returns why the file at path is excluded by a pattern,
+// or "" if it is not, whatever its extension.
+func (c *Checker) ExcludeReason(path string) string {
+	for _, exclude := range c.excludes {
+		if exclude.MatchString(path) {
+			return "exclude " + exclude.String()
+		}
+	}
+	return ""
+}
+
+func (c *Checker) hasExtension(ext string) bool {
+	for _, want := range c.extensions {
+		if ext == want {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Checker) hasPattern(name string) bool {
+	for _, pattern := range c.patterns {
+		// Patterns are validated by our callers.
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Check reads the file at path from r, and returns the ways in which
+// its header does not match the boilerplate.  An error reading r is
+// returned rather than being mistaken for the end of the file.
+func (c *Checker) Check(path string, r io.Reader) ([]Violation, error) {
+	return c.check(path, r, nil)
+}
+
+// check is Check, explaining each step to t unless it is nil.
+func (c *Checker) check(path string, r io.Reader, t *tracer) ([]Violation, error) {
+	h := c.newHeader(r)
+	if c.spdx != "" {
+		t.printf("searching for an %s %s line", spdxPrefix, c.spdx)
+		return c.checkSPDX(path, h)
+	}
+
+	// Find the line that best starts the header: when several could (e.g.
+	// a stray "/*" comment precedes it), use the one followed by the most
+	// matching lines.  Lines of an allowed prologue do not count against
+	// the number of lines we scan.  We only hold on to the lines of the
+	// best start and those we have yet to scan past, so memory is bounded
+	// by the length of the boilerplate rather than how far we scan.
+	start, best := -1, -1
+	var lines, raw, following []string
+	var found strings.Builder
+	prologue, content, searched, blanks := 0, -1, 0, 0
+	generated, marked := c.generated(path), false
+	for i := 0; c.searches(h, i, prologue); i++ {
+		h.discard(i)
+		line, ok := h.line(i)
+		if !ok {
+			break
+		}
+		searched = i + 1
+		if generated && c.generatedMarker.MatchString(h.raw[i-h.base]) {
+			marked = true
+		}
+		if i < c.foundLines {
+			fmt.Fprintf(&found, "%d | %s\n", i+1, h.raw[i-h.base])
+		}
+		blank := strings.TrimSpace(line) == ""
+		if blanks == i && blank {
+			blanks++
+		}
+		if content < 0 && !(blank || (c.allowLeadingLines && isPrologue(line))) {
+			content = i
+		}
+		if c.matches(0, line) {
+			score := c.score(h, i)
+			if t != nil {
+				t.printf("line %d may start the boilerplate: %d of its %d lines match", i+1, score, len(c.lines))
+			}
+			if score > best {
+				start, best = i, score
+				lines, raw = h.block(i, len(c.lines))
+				c.mask(lines)
+				if c.rewriteHeaders {
+					// Hold on to enough of the file to find the end of
+					// a header that is longer than the boilerplate.
+					following, _ = h.block(i, 2*len(c.lines))
+					c.mask(following)
+				}
+			}
+			// There is no better start than a complete match.
+			if best == len(c.lines) {
+				break
+			}
+			continue
+		}
+		if prologue == i && ((c.allowLeadingLines && isPrologue(line)) || (c.skipLeadingBlank && blank)) {
+			if t != nil {
+				t.printf("line %d is a leading line, which does not count against the lines searched", i+1)
+			}
+			prologue++
+		}
+	}
+	if err := h.err(); err != nil {
+		return nil, err
+	}
+	if t != nil {
+		t.explainSearch(c, searched, start, lines, raw)
+	}
+	if c.forbidden {
+		return c.checkForbidden(path, start, best), nil
+	}
+	if start < 0 {
+		// Insert the boilerplate after any prologue, separated
+		// from the rest of the file by a blank line.
+		insert := c.canonical
+		if insert[len(insert)-1] != "" {
+			insert = append(insert[:len(insert):len(insert)], "")
+		}
+		return []Violation{{
+			Path:   path,
+			Line:   prologue + 1,
+			Kind:   Missing,
+			Detail: c.denormalize(strings.Join(c.canonical, "\n")),
+			Found:  found.String(),
+			Fix:    c.insert(prologue, insert),
+		}}, nil
+	}
+
+	var violations []Violation
+	if c.forbidLeadingBlank {
+		violations = append(violations, c.checkLeadingBlanks(path, blanks, start)...)
+	}
+	if c.requireAtTop && content >= 0 && content < start {
+		violations = append(violations, Violation{
+			Path:   path,
+			Line:   content + 1,
+			Kind:   Misplaced,
+			Detail: fmt.Sprintf("line %d precedes the boilerplate at line %d", content+1, start+1),
+		})
+	}
+
+	// Most headers match in full, so only look closer at those that don't.
+	if best < len(c.lines) {
+		if i := len(lines); i < len(c.lines) {
+			// The file ended early, so append the rest of the boilerplate.
+			return append(violations, Violation{
+				Path:   path,
+				Line:   start + 1,
+				Kind:   Incomplete,
+				Detail: c.denormalize(strings.Join(c.canonical[i:], "\n")),
+				Found:  found.String(),
+				Fix:    c.insert(start+i, c.canonical[i:]),
+			}), nil
+		}
+
+		if c.blockAnchor && !c.allMismatches && !cmp.Equal(c.lines, lines) {
+			v := Violation{
+				Path: path,
+				Line: start + 1,
+				Kind: Mismatch,
+			}
+			if c.rewriteHeaders {
+				v.Fix = c.rewrite(start, following)
+			}
+			if c.diffContext >= 0 {
+				v.Detail = c.unified(start, lines, raw, 0, len(lines))
+			} else {
+				want, got := c.shown(lines, raw)
+				v.Detail = c.denormalize(cmp.Diff(want, got))
+			}
+			return append(violations, v), nil
+		}
+
+		// We comment on the first bad line instead of the first line of the comment
+		// because if the error is a change, and the first line of the comment block
+		// isn't part of the diff, then reviewdog will filter the error.
+		mismatched := false
+		for i := range lines {
+			if c.lines[i] == lines[i] {
+				continue
+			}
+			v := Violation{
+				Path:            path,
+				Line:            start + 1 + i,
+				Kind:            Mismatch,
+				BoilerplateLine: i + 1,
+			}
+			if c.columns {
+				v.Column = c.column(c.canonical[i], c.normalizeYears(raw[i]))
+			}
+			// Rewriting the header fixes every line of it at once.
+			if c.rewriteHeaders && !mismatched {
+				v.Fix = c.rewrite(start, following)
+			}
+			switch {
+			case !c.allMismatches && c.diffContext >= 0:
+				v.Detail = c.unified(start, lines, raw, i, len(lines))
+				return append(violations, v), nil
+			case !c.allMismatches:
+				want, got := c.shown(lines, raw)
+				v.Detail = c.denormalize(cmp.Diff(want[i:], got[i:]))
+				return append(violations, v), nil
+			case c.diffContext >= 0:
+				v.Detail = c.unified(start, lines, raw, i, i+1)
+			default:
+				want, got := c.shown(lines, raw)
+				v.Detail = c.denormalize(cmp.Diff(want[i:i+1], got[i:i+1]))
+			}
+			violations = append(violations, v)
+			mismatched = true
+		}
+		if mismatched {
+			return violations, nil
+		}
+	}
+	if c.forbidDuplicates {
+		violations = append(violations, c.checkDuplicate(path, h, start)...)
+	}
+	if c.forbidRemnants {
+		violations = append(violations, c.checkRemnants(path, h, start)...)
+	}
+	if c.exactLength {
+		violations = append(violations, c.checkLength(path, h, start)...)
+	}
+	if c.requireTrailingBlank {
+		violations = append(violations, c.checkSeparated(path, h, start)...)
+	}
+	if c.goPackage && filepath.Ext(path) == ".go" {
+		violations = append(violations, c.checkPackage(path, h, start)...)
+	}
+	if generated {
+		violations = append(violations, c.checkGenerated(path, h, start, marked)...)
+	}
+	if c.requireCurrentYear {
+		violations = append(violations, c.checkYears(path, start, raw)...)
+	}
+	if c.minYear > 0 {
+		violations = append(violations, c.checkYearRange(path, start, raw)...)
+	}
+	if c.holder != "" {
+		violations = append(violations, c.checkHolder(path, start, raw)...)
+	}
+	if c.comments != nil {
+		violations = append(violations, c.checkComments(path, start, raw)...)
+	}
+	return violations, nil
+}
+
+// searches returns whether the header may start at line i, after the
+// given number of lines of an allowed prologue.
+func (c *Checker) searches(h *header, i, prologue int) bool {
+	switch {
+	case c.matchAnywhere:
+		return true
+	case c.maxHeaderBytes > 0:
+		return h.offset(i) < c.maxHeaderBytes
+	default:
+		return i < prologue+c.maxHeaderLines
+	}
+}
+
+// checkDuplicate returns a violation if a second header follows the one
+// that matches in full at start.  Lest a lone comment opener be mistaken
+// for a header, a second header must match the first two lines of the
+// boilerplate.
+func (c *Checker) checkDuplicate(path string, h *header, start int) []Violation {
+	n := 2
+	if len(c.lines) < n {
+		n = len(c.lines)
+	}
+	end := start + len(c.lines)
+	for i := end; i < end+c.maxHeaderLines; i++ {
+		if _, ok := h.line(i); !ok {
+			break
+		}
+		if lines, _ := h.block(i, n); cmp.Equal(c.mask(lines), c.lines[:n]) {
+			return []Violation{{
+				Path:   path,
+				Line:   i + 1,
+				Kind:   Duplicate,
+				Detail: fmt.Sprintf("the boilerplate already starts at line %d", start+1),
+			}}
+		}
+	}
+	return nil
+}
+
+// checkSeparated returns a violation if anything but a blank line
+// directly follows the header that matches in full at start, which goes
+// on past the boilerplate for as long as its comment does.
+func (c *Checker) checkSeparated(path string, h *header, start int) []Violation {
+	if strings.TrimSpace(c.lines[len(c.lines)-1]) == "" {
+		return nil
+	}
+	end := c.headerEnd(h, start)
+	_, raw := h.block(end, 1)
+	if len(raw) == 0 || strings.TrimSpace(raw[0]) == "" {
+		return nil
+	}
+	return []Violation{{
+		Path:   path,
+		Line:   end + 1,
+		Kind:   Unseparated,
+		Detail: raw[0],
+		Fix:    &Edit{Start: end, End: end, Lines: []string{""}},
+	}}
+}
+
+// score returns how many lines of the boilerplate match the header
+// if it starts at the given line.
+func (c *Checker) score(h *header, start int) int {
+	score := 0
+	for i := range c.lines {
+		line, ok := h.line(start + i)
+		if !ok {
+			break
+		}
+		if c.matches(i, line) {
+			score++
+		}
+	}
+	return score
+}
+
+// header lazily reads and normalizes the lines of a file, holding on
+// to those from base until they are discarded.
+type header struct {
+	scanner   *bufio.Scanner
+	maxLine   int
+	normalize func(string) string
+	base      int
+	raw       []string
+	lines     []string
+
+	// starts holds the byte offsets of the lines from base, and next
+	// that of the line after them.
+	starts []int
+	next   int
+
+	// done is whether the scanner has stopped.
+	done bool
+}
+
+// newHeader returns a header that reads the lines of a file from r.
+func (c *Checker) newHeader(r io.Reader) *header {
+	scanner := bufio.NewScanner(r)
+	if c.maxLineLength != bufio.MaxScanTokenSize {
+		scanner.Buffer(nil, c.maxLineLength)
+	}
+	return &header{scanner: scanner, maxLine: c.maxLineLength, normalize: c.normalize}
+}
+
+// err returns the error, if any, that stopped us from reading the file,
+// saying which line was too long if that was the problem.
+func (h *header) err() error {
+	err := h.scanner.Err()
+	if err == bufio.ErrTooLong {
+		return fmt.Errorf("line %d is longer than %d bytes", h.base+len(h.lines)+1, h.maxLine)
+	}
+	return err
+}
+
+// line returns the normalized line at index i (counting from zero),
+// or false if the file has no such line.  Line i must not have been
+// discarded.
+func (h *header) line(i int) (string, bool) {
+	for h.base+len(h.lines) <= i {
+		// Once a scanner stops, with an error it may scan again, and
+		// return what it has buffered as a line.
+		if h.done || !h.scanner.Scan() {
+			h.done = true
+			return "", false
+		}
+		text := h.scanner.Text()
+		h.starts = append(h.starts, h.next)
+		h.next += len(text) + 1
+		if h.base+len(h.lines) == 0 {
+			// Editors and generators sometimes emit a byte order mark,
+			// which should not keep us from finding the header.
+			text = strings.TrimPrefix(text, utf8BOM)
+		}
+		h.raw = append(h.raw, text)
+		h.lines = append(h.lines, h.normalize(text))
+	}
+	return h.lines[i-h.base], true
+}
+
+// offset returns the byte offset of the line at index i, counting a
+// newline after each line.  Line i must not have been discarded, and the
+// lines before it must have been read.
+func (h *header) offset(i int) int {
+	if n := i - h.base; n < len(h.starts) {
+		return h.starts[n]
+	}
+	return h.next
+}
+
+// rawLine returns the line at index i as it appears in the file, less
+// any byte order mark, or false if the file has no such line.
+func (h *header) rawLine(i int) (string, bool) {
+	if _, ok := h.line(i); !ok {
+		return "", false
+	}
+	return h.raw[i-h.base], true
+}
+
+// block returns copies of the normalized and raw forms of up to n lines
+// starting at index i, fewer if the file ends first.
+func (h *header) block(i, n int) ([]string, []string) {
+	for n > 0 {
+		if _, ok := h.line(i + n - 1); ok {
+			break
+		}
+		n--
+	}
+	lines := make([]string, n)
+	raw := make([]string, n)
+	copy(lines, h.lines[i-h.base:])
+	copy(raw, h.raw[i-h.base:])
+	return lines, raw
+}
+
+// discard forgets the lines before index i, which may not be asked
+// for again.
+func (h *header) discard(i int) {
+	n := i - h.base
+	if n <= 0 {
+		return
+	}
+	if n > len(h.lines) {
+		n = len(h.lines)
+	}
+	h.lines, h.raw, h.starts = h.lines[n:], h.raw[n:], h.starts[n:]
+	h.base += n
+}
+
+// isPrologue returns whether the line may precede the boilerplate
+// when leading lines are allowed, e.g. a shebang or build tags.
+func isPrologue(line string) bool {
+	switch {
+	case strings.TrimSpace(line) == "":
+		return true
+	case strings.HasPrefix(line, "#!"):
+		return true
+	case strings.HasPrefix(line, "//go:build"), strings.HasPrefix(line, "// +build"):
+		return true
+	default:
+		return false
+	}
+}
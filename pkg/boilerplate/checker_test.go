@@ -0,0 +1,1212 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"bufio"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var testBoilerplate = []string{
+	"/*",
+	"Copyright 2020 Matt Moore",
+	"*/",
+	"",
+}
+
+func TestSkipReason(t *testing.T) {
+	c := NewChecker(testBoilerplate, []string{"go", "sh"},
+		[]*regexp.Regexp{regexp.MustCompile("^vendor/")})
+
+	tests := []struct {
+		path string
+		want string
+	}{{
+		path: "pkg/foo.go",
+		want: "",
+	}, {
+		path: "hack/update.sh",
+		want: "",
+	}, {
+		path: "README.md",
+		want: "extension",
+	}, {
+		path: "vendor/foo.go",
+		want: "exclude ^vendor/",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			if got := c.SkipReason(test.path); got != test.want {
+				t.Errorf("SkipReason() = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSkipReasonPatterns(t *testing.T) {
+	c := NewChecker(testBoilerplate, []string{"go"}, nil,
+		WithFilePatterns("Dockerfile", "*.mk"))
+
+	tests := []struct {
+		path string
+		want string
+	}{{
+		path: "pkg/foo.go",
+		want: "",
+	}, {
+		path: "images/base/Dockerfile",
+		want: "",
+	}, {
+		path: "hack/rules.mk",
+		want: "",
+	}, {
+		path: "Makefile",
+		want: "extension or pattern",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			if got := c.SkipReason(test.path); got != test.want {
+				t.Errorf("SkipReason() = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		boilerplate []string
+		opts        []Option
+		content     string
+		want        []Violation
+	}{{
+		name:    "matching header",
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:    "matching header with a range of years",
+		content: "/*\nCopyright 2016-2018 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:    "matching header after a byte order mark",
+		content: utf8BOM + "/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:    "matching header after a stray comment opener",
+		content: "/*\n  Stray comment\n*/\n/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:    "mismatched header after a stray comment opener",
+		content: "/*\n  Stray comment\n*/\n/*\nCopyright 2018 Matt More\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 5,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"Copyright YYYY Matt More", "*/", ""})),
+		}},
+	}, {
+		name:    "matching header with an opener",
+		opts:    []Option{WithOpeners([]*regexp.Regexp{regexp.MustCompile(`^/\* -\*- .* -\*-$`)})},
+		content: "/* -*- mode: go -*-\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:    "mismatched header with an opener",
+		opts:    []Option{WithOpeners([]*regexp.Regexp{regexp.MustCompile(`^/\* -\*- .* -\*-$`)})},
+		content: "/* -*- mode: go -*-\nCopyright 2018 Matt More\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"Copyright YYYY Matt More", "*/", ""})),
+		}},
+	}, {
+		name:    "header with another opener",
+		opts:    []Option{WithOpeners([]*regexp.Regexp{regexp.MustCompile(`^/\* -\*- .* -\*-$`)})},
+		content: "/** -*- mode: go -*-\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Missing,
+			Detail: Denormalize("/*\nCopyright YYYY Matt Moore\n*/\n"),
+			Fix: &Edit{
+				Start: 0,
+				End:   0,
+				Lines: []string{"/*", Denormalize("Copyright YYYY Matt Moore"), "*/", ""},
+			},
+		}},
+	}, {
+		name:    "missing header",
+		content: "package foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Missing,
+			Detail: Denormalize("/*\nCopyright YYYY Matt Moore\n*/\n"),
+			Fix: &Edit{
+				Lines: []string{"/*", Denormalize("Copyright YYYY Matt Moore"), "*/", ""},
+			},
+		}},
+	}, {
+		name:    "missing header after a shebang",
+		opts:    []Option{WithLeadingLines()},
+		content: "#!/bin/bash\n\necho hi\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   3,
+			Kind:   Missing,
+			Detail: Denormalize("/*\nCopyright YYYY Matt Moore\n*/\n"),
+			Fix: &Edit{
+				Start: 2,
+				End:   2,
+				Lines: []string{"/*", Denormalize("Copyright YYYY Matt Moore"), "*/", ""},
+			},
+		}},
+	}, {
+		name:    "missing header with the lines found instead",
+		opts:    []Option{WithMaxHeaderLines(2), WithFoundLines(3)},
+		content: "package foo\n\n/*\nCopyright 2018 Matt Moore\n*/\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Missing,
+			Detail: Denormalize("/*\nCopyright YYYY Matt Moore\n*/\n"),
+			// Only the lines searched are found.
+			Found: "1 | package foo\n2 | \n",
+			Fix: &Edit{
+				Lines: []string{"/*", Denormalize("Copyright YYYY Matt Moore"), "*/", ""},
+			},
+		}},
+	}, {
+		name:    "incomplete header with the lines found instead",
+		opts:    []Option{WithFoundLines(1)},
+		content: "/*\nCopyright 2018 Matt Moore\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Incomplete,
+			Detail: "*/\n",
+			Found:  "1 | /*\n",
+			Fix: &Edit{
+				Start: 2,
+				End:   2,
+				Lines: []string{"*/", ""},
+			},
+		}},
+	}, {
+		name:        "matching header with smart punctuation",
+		boilerplate: []string{"// Copyright YYYY Matt Moore", `// Licensed under the "License".`, ""},
+		opts:        []Option{WithNormalizedUnicode()},
+		content:     "// Copyright 2016\u20132018 Matt\u00A0Moore\n// Licensed under the \u201CLicense\u201D.\n\npackage foo\n",
+	}, {
+		name:        "matching header with a former copyright holder",
+		boilerplate: []string{"// Copyright YYYY Acme Inc", ""},
+		opts:        []Option{WithAlias("Matt Moore", "Acme Inc")},
+		content:     "// Copyright 2018 Matt Moore\n\npackage foo\n",
+	}, {
+		name:        "matching header with chained aliases",
+		boilerplate: []string{"// Copyright YYYY Acme Inc", ""},
+		opts:        []Option{WithAlias("Copyright YYYY Matt Moore", "Copyright YYYY Moore Inc"), WithAlias("Moore Inc", "Acme Inc")},
+		content:     "// Copyright 2016-2018 Matt Moore\n\npackage foo\n",
+	}, {
+		name:        "mismatched header with a former copyright holder",
+		boilerplate: []string{"// Copyright YYYY Acme Inc", "// All rights reserved.", ""},
+		opts:        []Option{WithAlias("Matt Moore", "Acme Inc")},
+		content:     "// Copyright 2018 Matt Moore\n// Al rights reserved.\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"// All rights reserved.", ""},
+				[]string{"// Al rights reserved.", ""})),
+		}},
+	}, {
+		name:        "matching header with the required holder",
+		boilerplate: []string{"// Copyright YYYY Acme Inc", ""},
+		opts:        []Option{WithHolder("Acme Inc")},
+		content:     "// Copyright 2016-2018 Acme Inc\n\npackage foo\n",
+	}, {
+		name:        "matching header with a former copyright holder required",
+		boilerplate: []string{"// Copyright YYYY Acme Inc", ""},
+		opts:        []Option{WithAlias("Matt Moore", "Acme Inc"), WithHolder("Acme Inc")},
+		content:     "// Copyright 2018 Matt Moore\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Misattributed,
+			Detail: `found "Matt Moore", expected "Acme Inc"`,
+		}},
+	}, {
+		name:        "holder that only starts with the required holder",
+		boilerplate: []string{"// Copyright YYYY Acme", ""},
+		opts:        []Option{WithAlias("AcmeCorp", "Acme"), WithHolder("Acme")},
+		content:     "// Copyright 2018 AcmeCorp\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Misattributed,
+			Detail: `found "AcmeCorp", expected "Acme"`,
+		}},
+	}, {
+		name:    "incomplete header",
+		content: "/*\nCopyright 2018 Matt Moore\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Incomplete,
+			Detail: "*/\n",
+			Fix: &Edit{
+				Start: 2,
+				End:   2,
+				Lines: []string{"*/", ""},
+			},
+		}},
+	}, {
+		name:    "trailing whitespace",
+		content: "/*\nCopyright 2018 Matt Moore \t\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"Copyright YYYY Matt Moore \t", "*/", ""})),
+		}},
+	}, {
+		name:    "ignored trailing whitespace",
+		opts:    []Option{WithoutTrailingWhitespace()},
+		content: "/*\nCopyright 2018 Matt Moore \t\n*/  \n\npackage foo\n",
+	}, {
+		name:    "ignored leading whitespace",
+		opts:    []Option{WithoutLeadingWhitespace()},
+		content: "/*\n\tCopyright 2018 Matt Moore\n */\n\npackage foo\n",
+	}, {
+		name:    "leading whitespace with only trailing whitespace ignored",
+		opts:    []Option{WithoutTrailingWhitespace()},
+		content: "/*\n  Copyright 2018 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"  Copyright YYYY Matt Moore", "*/", ""})),
+		}},
+	}, {
+		name:    "ignored case",
+		opts:    []Option{WithoutCaseSensitivity()},
+		content: "/*\nCOPYRIGHT 2018 MATT MOORE\n*/\n\npackage foo\n",
+	}, {
+		name:    "mismatch with case ignored",
+		opts:    []Option{WithoutCaseSensitivity()},
+		content: "/*\nCOPYRIGHT 2018 MATT MORE\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"COPYRIGHT YYYY MATT MORE", "*/", ""})),
+		}},
+	}, {
+		name:    "missing header with case ignored",
+		opts:    []Option{WithoutCaseSensitivity()},
+		content: "package foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Missing,
+			Detail: Denormalize("/*\nCopyright YYYY Matt Moore\n*/\n"),
+			Fix: &Edit{
+				Start: 0,
+				End:   0,
+				Lines: []string{"/*", Denormalize("Copyright YYYY Matt Moore"), "*/", ""},
+			},
+		}},
+	}, {
+		name:        "single blank line after header",
+		boilerplate: []string{"/*", "Copyright 2020 Matt Moore", "*/", "", ""},
+		content:     "/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 5,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{""},
+				[]string{"package foo"})),
+		}},
+	}, {
+		name:        "collapsed blank lines after header",
+		boilerplate: []string{"/*", "Copyright 2020 Matt Moore", "*/", "", ""},
+		opts:        []Option{WithCollapsedBlankLines()},
+		content:     "/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:        "collapsed blank lines at end of file",
+		boilerplate: []string{"/*", "Copyright 2020 Matt Moore", "*/", ""},
+		opts:        []Option{WithCollapsedBlankLines()},
+		content:     "/*\nCopyright 2018 Matt Moore\n*/",
+	}, {
+		name:        "blank lines within header with collapsed blank lines",
+		boilerplate: []string{"/*", "", "Copyright 2020 Matt Moore", "*/", ""},
+		opts:        []Option{WithCollapsedBlankLines()},
+		content:     "/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"", "Copyright YYYY Matt Moore", "*/"},
+				[]string{"Copyright YYYY Matt Moore", "*/", ""})),
+		}},
+	}, {
+		name:    "header after a long banner",
+		content: strings.Repeat("// banner\n", 10) + "/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Missing,
+			Detail: Denormalize("/*\nCopyright YYYY Matt Moore\n*/\n"),
+			Fix: &Edit{
+				Start: 0,
+				End:   0,
+				Lines: []string{"/*", Denormalize("Copyright YYYY Matt Moore"), "*/", ""},
+			},
+		}},
+	}, {
+		name:    "header after a long banner with a larger window",
+		opts:    []Option{WithMaxHeaderLines(11)},
+		content: strings.Repeat("// banner\n", 10) + "/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:    "header after a long line within the bytes",
+		opts:    []Option{WithMaxHeaderBytes(102)},
+		content: strings.Repeat("x", 100) + "\n/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:    "header after a long line past the bytes",
+		opts:    []Option{WithMaxHeaderBytes(101)},
+		content: strings.Repeat("x", 100) + "\n/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Missing,
+			Detail: Denormalize("/*\nCopyright YYYY Matt Moore\n*/\n"),
+			Fix: &Edit{
+				Start: 0,
+				End:   0,
+				Lines: []string{"/*", Denormalize("Copyright YYYY Matt Moore"), "*/", ""},
+			},
+		}},
+	}, {
+		name:    "header after a long banner within the bytes",
+		opts:    []Option{WithMaxHeaderBytes(1000)},
+		content: strings.Repeat("// banner\n", 10) + "/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:    "header after a long banner matched anywhere",
+		opts:    []Option{WithMatchAnywhere()},
+		content: strings.Repeat("// banner\n", 1000) + "/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:    "mismatched header matched anywhere",
+		opts:    []Option{WithMatchAnywhere()},
+		content: strings.Repeat("// banner\n", 1000) + "/*\nCopyright 2018 Matt More\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 1002,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"Copyright YYYY Matt More", "*/", ""})),
+		}},
+	}, {
+		name:    "header at top after blank lines",
+		opts:    []Option{WithHeaderAtTop()},
+		content: "\n\n/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:    "header not at top",
+		opts:    []Option{WithHeaderAtTop()},
+		content: "\n// Banner\n/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Kind:   Misplaced,
+			Detail: "line 2 precedes the boilerplate at line 3",
+		}},
+	}, {
+		name:    "header after blank lines skipped for free",
+		opts:    []Option{WithMaxHeaderLines(1), WithLeadingBlankLines()},
+		content: "\n\n\n/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:    "header after forbidden blank lines",
+		opts:    []Option{WithoutLeadingBlankLines()},
+		content: "\n \n/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Misplaced,
+			Detail: "lines 1-2 are blank and precede the boilerplate at line 3",
+			Fix:    &Edit{Start: 0, End: 2},
+		}},
+	}, {
+		name:    "header after a forbidden blank line",
+		opts:    []Option{WithoutLeadingBlankLines()},
+		content: "\n/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Misplaced,
+			Detail: "line 1 is blank and precedes the boilerplate at line 2",
+			Fix:    &Edit{Start: 0, End: 1},
+		}},
+	}, {
+		name:    "header at top with blank lines forbidden",
+		opts:    []Option{WithoutLeadingBlankLines()},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:    "header at top after a shebang",
+		opts:    []Option{WithHeaderAtTop(), WithLeadingLines()},
+		content: "#!/bin/bash\n/*\nCopyright 2018 Matt Moore\n*/\n\necho hi\n",
+	}, {
+		name:    "mismatched header not at top",
+		opts:    []Option{WithHeaderAtTop()},
+		content: "#!/bin/bash\n/*\nCopyright 2018 Matt More\n*/\n\necho hi\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Misplaced,
+			Detail: "line 1 precedes the boilerplate at line 2",
+		}, {
+			Path: "foo.go",
+			Line: 3,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"Copyright YYYY Matt More", "*/", ""})),
+		}},
+	}, {
+		name:    "several mismatches",
+		content: "/*\nCopyright 2018 Matt More\n*\\\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"Copyright YYYY Matt More", "*\\", ""})),
+		}},
+	}, {
+		name:    "several mismatches all reported",
+		opts:    []Option{WithAllMismatches()},
+		content: "/*\nCopyright 2018 Matt More\n*\\\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore"},
+				[]string{"Copyright YYYY Matt More"})),
+		}, {
+			Path:   "foo.go",
+			Line:   3,
+			Kind:   Mismatch,
+			Detail: cmp.Diff([]string{"*/"}, []string{"*\\"}),
+		}},
+	}, {
+		name:    "mismatched header with diff context",
+		opts:    []Option{WithDiffContext(1)},
+		content: "/*\nCopyright 2018 Matt More\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Kind:   Mismatch,
+			Detail: Denormalize("@@ -1,3 +1,3 @@\n /*\n-Copyright YYYY Matt Moore\n") + "+Copyright 2018 Matt More\n */\n",
+		}},
+	}, {
+		name:    "mismatched header anchored at its start",
+		opts:    []Option{WithBlockAnchor()},
+		content: "/*\nCopyright 2018 Matt More\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 1,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"/*", "Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"/*", "Copyright YYYY Matt More", "*/", ""})),
+		}},
+	}, {
+		name:    "mismatched header anchored at its start with diff context",
+		opts:    []Option{WithBlockAnchor(), WithDiffContext(0)},
+		content: "/*\nCopyright 2018 Matt More\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Mismatch,
+			Detail: Denormalize("@@ -2,1 +2,1 @@\n-Copyright YYYY Matt Moore\n") + "+Copyright 2018 Matt More\n",
+		}},
+	}, {
+		name:    "mismatched lines anchored at their start",
+		opts:    []Option{WithBlockAnchor(), WithAllMismatches()},
+		content: "/*\nCopyright 2018 Matt More\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore"},
+				[]string{"Copyright YYYY Matt More"})),
+		}},
+	}, {
+		name:    "several mismatches without diff context",
+		opts:    []Option{WithDiffContext(0)},
+		content: "/*\nCopyright 2018 Matt More\n*\\\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Kind:   Mismatch,
+			Detail: Denormalize("@@ -2,2 +2,2 @@\n-Copyright YYYY Matt Moore\n-*/\n") + "+Copyright 2018 Matt More\n+*\\\n",
+		}},
+	}, {
+		name:        "distant mismatches in separate hunks",
+		boilerplate: []string{"/*", "one", "two", "three", "four", "*/"},
+		opts:        []Option{WithDiffContext(0)},
+		content:     "/*\nOne\ntwo\nthree\nFour\n*/\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Kind:   Mismatch,
+			Detail: "@@ -2,1 +2,1 @@\n-one\n+One\n@@ -5,1 +5,1 @@\n-four\n+Four\n",
+		}},
+	}, {
+		name:    "several mismatches all reported with diff context",
+		opts:    []Option{WithAllMismatches(), WithDiffContext(0)},
+		content: "/*\nCopyright 2018 Matt More\n*\\\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Kind:   Mismatch,
+			Detail: Denormalize("@@ -2,1 +2,1 @@\n-Copyright YYYY Matt Moore\n") + "+Copyright 2018 Matt More\n",
+		}, {
+			Path:   "foo.go",
+			Line:   3,
+			Kind:   Mismatch,
+			Detail: "@@ -3,1 +3,1 @@\n-*/\n+*\\\n",
+		}},
+	}, {
+		name:        "tab indentation",
+		boilerplate: []string{"/*", "    Copyright 2020 Matt Moore", "*/", ""},
+		content:     "/*\n\tCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"    Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"\tCopyright YYYY Matt Moore", "*/", ""})),
+		}},
+	}, {
+		name:        "tab indentation with a tab width",
+		boilerplate: []string{"/*", "    Copyright 2020 Matt Moore", "*/", ""},
+		opts:        []Option{WithTabWidth(4)},
+		content:     "/*\n\tCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:        "tab indentation with another tab width",
+		boilerplate: []string{"/*", "    Copyright 2020 Matt Moore", "*/", ""},
+		opts:        []Option{WithTabWidth(2)},
+		content:     "/*\n\tCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"    Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"  Copyright YYYY Matt Moore", "*/", ""})),
+		}},
+	}, {
+		name:        "tab after the indentation with a tab width",
+		boilerplate: []string{"/*", "Copyright 2020    Matt Moore", "*/", ""},
+		opts:        []Option{WithTabWidth(4)},
+		content:     "/*\nCopyright 2018\tMatt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY    Matt Moore", "*/", ""},
+				[]string{"Copyright YYYY\tMatt Moore", "*/", ""})),
+		}},
+	}, {
+		name:    "list of years",
+		content: "/*\nCopyright 2018, 2019 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"Copyright YYYY, YYYY Matt Moore", "*/", ""})),
+		}},
+	}, {
+		name:    "list of years allowed",
+		opts:    []Option{WithYearLists()},
+		content: "/*\nCopyright 2018, 2019 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:    "stacked headers",
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\n/*\nCopyright 2019 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:    "stacked headers forbidden",
+		opts:    []Option{WithoutDuplicates()},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\n/*\nCopyright 2019 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   5,
+			Kind:   Duplicate,
+			Detail: "the boilerplate already starts at line 1",
+		}},
+	}, {
+		name:    "comment after the header is not a duplicate",
+		opts:    []Option{WithoutDuplicates()},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\n/*\nPackage foo builds widgets.\n*/\npackage foo\n",
+	}, {
+		name:    "trailing blank line in the boilerplate",
+		opts:    []Option{WithTrailingBlankLine()},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:    "trailing blank line required",
+		opts:    []Option{WithTrailingBlankLine(), WithCollapsedBlankLines()},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   4,
+			Kind:   Unseparated,
+			Detail: "package foo",
+			Fix:    &Edit{Start: 3, End: 3, Lines: []string{""}},
+		}},
+	}, {
+		name:    "trailing blank line present",
+		opts:    []Option{WithTrailingBlankLine(), WithCollapsedBlankLines()},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\n\npackage foo\n",
+	}, {
+		name:        "trailing blank line after a line comment",
+		boilerplate: []string{"// Copyright YYYY Matt Moore"},
+		opts:        []Option{WithTrailingBlankLine()},
+		content:     "// Copyright 2018 Matt Moore\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Kind:   Unseparated,
+			Detail: "package foo",
+			Fix:    &Edit{Start: 1, End: 1, Lines: []string{""}},
+		}},
+	}, {
+		name:        "trailing blank line at the end of the file",
+		boilerplate: []string{"// Copyright YYYY Matt Moore"},
+		opts:        []Option{WithTrailingBlankLine()},
+		content:     "// Copyright 2018 Matt Moore\n",
+	}, {
+		name:        "header longer than the boilerplate",
+		boilerplate: []string{"// Copyright YYYY Matt Moore"},
+		content:     "// Copyright 2018 Matt Moore\n// This file builds widgets.\n\npackage foo\n",
+	}, {
+		name:        "trailing blank line after a header longer than the boilerplate",
+		boilerplate: []string{"// Copyright YYYY Matt Moore"},
+		opts:        []Option{WithTrailingBlankLine()},
+		content:     "// Copyright 2018 Matt Moore\n// This file builds widgets.\n\npackage foo\n",
+	}, {
+		name:        "no trailing blank line after a header longer than the boilerplate",
+		boilerplate: []string{"// Copyright YYYY Matt Moore"},
+		opts:        []Option{WithTrailingBlankLine()},
+		content:     "// Copyright 2018 Matt Moore\n// This file builds widgets.\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   3,
+			Kind:   Unseparated,
+			Detail: "package foo",
+			Fix:    &Edit{Start: 2, End: 2, Lines: []string{""}},
+		}},
+	}, {
+		name:        "header longer than the boilerplate of exact length",
+		boilerplate: []string{"// Copyright YYYY Matt Moore"},
+		opts:        []Option{WithExactLength()},
+		content:     "// Copyright 2018 Matt Moore\n// This file builds widgets.\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Kind:   Overlong,
+			Detail: "// This file builds widgets.",
+		}},
+	}, {
+		name:        "header of exact length",
+		boilerplate: []string{"// Copyright YYYY Matt Moore"},
+		opts:        []Option{WithExactLength()},
+		content:     "// Copyright 2018 Matt Moore\n\n// Package foo builds widgets.\npackage foo\n",
+	}, {
+		name:    "comment after a closed boilerplate of exact length",
+		opts:    []Option{WithExactLength(), WithTrailingBlankLine()},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\n// Package foo builds widgets.\npackage foo\n",
+	}, {
+		name:    "package clause after blank lines",
+		opts:    []Option{WithGoPackageClause()},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\n\npackage foo\n",
+	}, {
+		name:    "package comment before the package clause",
+		opts:    []Option{WithGoPackageClause()},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\n// Package foo does things.\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   5,
+			Kind:   Interposed,
+			Detail: "// Package foo does things.",
+		}},
+	}, {
+		name:    "package clause at the end of the file",
+		opts:    []Option{WithGoPackageClause()},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\n",
+	}, {
+		name:    "generated code marker after the header",
+		opts:    []Option{WithGeneratedMarker(regexp.MustCompile(`\.go$`), DefaultGeneratedMarker)},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\n// Code generated by foo-gen. DO NOT EDIT.\n\npackage foo\n",
+	}, {
+		name:    "generated code marker before the header",
+		opts:    []Option{WithGeneratedMarker(regexp.MustCompile(`\.go$`), DefaultGeneratedMarker)},
+		content: "// Code generated by foo-gen. DO NOT EDIT.\n\n/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:    "generated code marker missing",
+		opts:    []Option{WithGeneratedMarker(regexp.MustCompile(`\.go$`), DefaultGeneratedMarker)},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\n// Code generated by foo-gen. Edit away.\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Unmarked,
+			Detail: "no line matches " + DefaultGeneratedMarker.String(),
+		}},
+	}, {
+		name:    "generated code marker past the header lines",
+		opts:    []Option{WithGeneratedMarker(regexp.MustCompile(`\.go$`), DefaultGeneratedMarker), WithMatchAnywhere()},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n" + strings.Repeat("\n", 12) + "// Code generated by foo-gen. DO NOT EDIT.\n",
+	}, {
+		name:    "generated code marker within the header bytes",
+		opts:    []Option{WithGeneratedMarker(regexp.MustCompile(`\.go$`), DefaultGeneratedMarker), WithMaxHeaderBytes(100)},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n" + strings.Repeat("\n", 12) + "// Code generated by foo-gen. DO NOT EDIT.\n",
+	}, {
+		name:    "generated code marker past the header bytes",
+		opts:    []Option{WithGeneratedMarker(regexp.MustCompile(`\.go$`), DefaultGeneratedMarker), WithMaxHeaderBytes(20)},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\n// Package foo does all sorts of things.\n// Code generated by foo-gen. DO NOT EDIT.\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Unmarked,
+			Detail: "no line matches " + DefaultGeneratedMarker.String(),
+		}},
+	}, {
+		name:    "generated code marker not required",
+		opts:    []Option{WithGeneratedMarker(regexp.MustCompile(`^zz_generated`), DefaultGeneratedMarker)},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:        "literal years",
+		boilerplate: []string{"// Copyright 2020 Matt Moore", ""},
+		opts:        []Option{WithLiteralYears()},
+		content:     "// Copyright 2020 Matt Moore\n\npackage foo\n",
+	}, {
+		name:        "another year with literal years",
+		boilerplate: []string{"/*", "Copyright 2020 Matt Moore", "*/", ""},
+		opts:        []Option{WithLiteralYears(), WithoutCaseSensitivity()},
+		content:     "/*\nCOPYRIGHT 2019 MATT MOORE\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: cmp.Diff(
+				[]string{"Copyright 2020 Matt Moore", "*/", ""},
+				[]string{"COPYRIGHT 2019 MATT MOORE", "*/", ""}),
+		}},
+	}, {
+		name:        "placeholder with literal years",
+		boilerplate: []string{"// Copyright YYYY Matt Moore", ""},
+		opts:        []Option{WithLiteralYears()},
+		content:     "package foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Missing,
+			Detail: "// Copyright YYYY Matt Moore\n",
+			Fix:    &Edit{Lines: []string{"// Copyright YYYY Matt Moore", ""}},
+		}},
+	}, {
+		name:        "wildcard line",
+		boilerplate: []string{"/*", "Copyright YYYY Matt Moore", "{{*}}", "*/", ""},
+		content:     "/*\nCopyright 2018 Matt Moore\nGenerated from foo.proto.\n*/\n\npackage foo\n",
+	}, {
+		name:        "wildcard first line",
+		boilerplate: []string{" {{*}} ", "Copyright YYYY Matt Moore", ""},
+		content:     "// Code generated by protoc. DO NOT EDIT.\nCopyright 2018 Matt Moore\n\npackage foo\n",
+	}, {
+		name:        "mismatched header around a wildcard line",
+		boilerplate: []string{"/*", "Copyright YYYY Matt Moore", "{{*}}", "*/", ""},
+		content:     "/*\nCopyright 2018 Matt More\nGenerated from foo.proto.\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore", "{{*}}", "*/", ""},
+				[]string{"Copyright YYYY Matt More", "{{*}}", "*/", ""})),
+		}},
+	}, {
+		name:        "missing header with a wildcard line",
+		boilerplate: []string{"/*", "Copyright YYYY Matt Moore", "{{*}}", "*/", ""},
+		content:     "package foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Missing,
+			Detail: Denormalize("/*\nCopyright YYYY Matt Moore\n{{*}}\n*/\n"),
+		}},
+	}, {
+		name:        "incomplete header before a wildcard line",
+		boilerplate: []string{"/*", "Copyright YYYY Matt Moore", "{{*}}", "*/", ""},
+		content:     "/*\nCopyright 2018 Matt Moore\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Incomplete,
+			Detail: "{{*}}\n*/\n",
+		}},
+	}, {
+		name:    "mismatched header with columns",
+		opts:    []Option{WithColumns()},
+		content: "/*\nCopyright 2018 Matt More\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Column: 23,
+			Kind:   Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"Copyright YYYY Matt More", "*/", ""})),
+		}},
+	}, {
+		name:    "mismatched header",
+		content: "/*\nCopyright 2018 Matt More\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"Copyright YYYY Matt More", "*/", ""})),
+		}},
+	}, {
+		name:        "license text after the header",
+		boilerplate: []string{"/*", "Copyright 2020 Matt Moore", "", "Licensed under the Apache License, Version 2.0.", "*/", ""},
+		opts:        []Option{WithRemnants()},
+		content:     "/*\nCopyright 2018 Matt Moore\n\nLicensed under the Apache License, Version 2.0.\n*/\n\n// Licensed under the Apache License, Version 2.0.\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   7,
+			Kind:   Remnant,
+			Detail: "// Licensed under the Apache License, Version 2.0.",
+		}},
+	}, {
+		name:    "copyright line after the header",
+		opts:    []Option{WithRemnants()},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\n// Package foo builds widgets.\n// Copyright 2019 Matt Moore\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   6,
+			Kind:   Remnant,
+			Detail: "// Copyright 2019 Matt Moore",
+		}},
+	}, {
+		name:    "package documentation after the header",
+		opts:    []Option{WithRemnants()},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\n// Package foo is Copyright Matt Moore.\npackage foo\n",
+	}, {
+		name:    "license text after the code",
+		opts:    []Option{WithRemnants()},
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n\n// Copyright 2019 Matt Moore\n",
+	}, {
+		name:    "rewritten mismatched header",
+		opts:    []Option{WithRewrites()},
+		content: "#!/bin/sh\n/*\nCopyright 2018 Matt More\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 3,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"Copyright YYYY Matt More", "*/", ""})),
+			Fix: &Edit{
+				Start: 1,
+				End:   5,
+				Lines: []string{"/*", Denormalize("Copyright YYYY Matt Moore"), "*/", ""},
+			},
+		}},
+	}, {
+		name:    "rewritten header with a line too many",
+		opts:    []Option{WithRewrites()},
+		content: "/*\nCopyright 2018 Matt More\nAll rights reserved.\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"Copyright YYYY Matt More", "All rights reserved.", "*/"})),
+			Fix: &Edit{
+				Start: 0,
+				End:   5,
+				Lines: []string{"/*", Denormalize("Copyright YYYY Matt Moore"), "*/", ""},
+			},
+		}},
+	}, {
+		name:    "rewritten header with a line too few",
+		opts:    []Option{WithRewrites()},
+		content: "/*\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"*/", "", "package foo"})),
+			Fix: &Edit{
+				Start: 0,
+				End:   3,
+				Lines: []string{"/*", Denormalize("Copyright YYYY Matt Moore"), "*/", ""},
+			},
+		}},
+	}, {
+		name:        "mismatched header with a wildcard line is not rewritten",
+		boilerplate: []string{"/*", "Copyright 2020 Matt Moore", "{{*}}", "*/", ""},
+		opts:        []Option{WithRewrites()},
+		content:     "/*\nCopyright 2018 Matt More\nGenerated.\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore", "{{*}}", "*/", ""},
+				[]string{"Copyright YYYY Matt More", "{{*}}", "*/", ""})),
+		}},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			boilerplate := test.boilerplate
+			if boilerplate == nil {
+				boilerplate = testBoilerplate
+			}
+			c := NewChecker(boilerplate, []string{"go"}, nil, test.opts...)
+			got, err := c.Check("foo.go", strings.NewReader(test.content))
+			if err != nil {
+				t.Fatalf("Check() = %v", err)
+			}
+			got = withoutBoilerplateLines(got)
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("Check() (-want, +got): %s", cmp.Diff(test.want, got))
+			}
+		})
+	}
+}
+
+// withoutBoilerplateLines clears the BoilerplateLine of violations, which
+// TestCheckBoilerplateLine covers, so that other tests needn't spell it out.
+func withoutBoilerplateLines(violations []Violation) []Violation {
+	for i := range violations {
+		violations[i].BoilerplateLine = 0
+	}
+	return violations
+}
+
+func TestCheckBoilerplateLine(t *testing.T) {
+	tests := []struct {
+		name        string
+		boilerplate []string
+		opts        []Option
+		content     string
+		want        []int
+	}{{
+		name:    "mismatch",
+		content: "/*\nCopyright 2018 Matt More\n*/\n\npackage foo\n",
+		want:    []int{2},
+	}, {
+		name:    "mismatch after a banner",
+		opts:    []Option{WithMatchAnywhere()},
+		content: strings.Repeat("// banner\n", 10) + "/*\nCopyright 2018 Matt More\n*/\n\npackage foo\n",
+		want:    []int{2},
+	}, {
+		name:        "mismatch at the end of the boilerplate",
+		boilerplate: []string{"/*", "Copyright 2020 Matt Moore", "*/", "", ""},
+		content:     "/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+		want:        []int{5},
+	}, {
+		name:    "all mismatches",
+		opts:    []Option{WithAllMismatches()},
+		content: "/*\nCopyright 2018 Matt More\n*\\\n\npackage foo\n",
+		want:    []int{2, 3},
+	}, {
+		name:    "missing",
+		content: "package foo\n",
+		want:    []int{0},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			boilerplate := test.boilerplate
+			if boilerplate == nil {
+				boilerplate = testBoilerplate
+			}
+			c := NewChecker(boilerplate, []string{"go"}, nil, test.opts...)
+			violations, err := c.Check("foo.go", strings.NewReader(test.content))
+			if err != nil {
+				t.Fatalf("Check() = %v", err)
+			}
+			var got []int
+			for _, v := range violations {
+				got = append(got, v.BoilerplateLine)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("BoilerplateLine = %v, wanted %v", got, test.want)
+			}
+		})
+	}
+}
+
+// errReader returns its content, and then an error instead of io.EOF.
+type errReader struct {
+	content string
+	err     error
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	if r.content == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.content)
+	r.content = r.content[n:]
+	return n, nil
+}
+
+func TestCheckReadError(t *testing.T) {
+	want := errors.New("input/output error")
+	for _, content := range []string{"", "package foo\n", "/*\nCopyright 2018 Matt Moore\n"} {
+		c := NewChecker(testBoilerplate, []string{"go"}, nil)
+		if _, err := c.Check("foo.go", &errReader{content: content, err: want}); err != want {
+			t.Errorf("Check(%q) = %v, wanted %v", content, err, want)
+		}
+	}
+}
+
+func TestCheckGoPackageClauseOtherLanguages(t *testing.T) {
+	// Only Go has a package clause to follow the header.
+	c := NewChecker(testBoilerplate, []string{"go", "sh"}, nil, WithGoPackageClause())
+	got, err := c.Check("foo.sh", strings.NewReader("/*\nCopyright 2018 Matt Moore\n*/\n\necho foo\n"))
+	if err != nil {
+		t.Fatalf("Check() = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Check() = %v, wanted no violations", got)
+	}
+}
+
+func TestCheckLongLines(t *testing.T) {
+	// Minified files may have no header, but one enormous line.
+	content := "package foo\n" + strings.Repeat("x", 100000) + "\n"
+
+	c := NewChecker(testBoilerplate, []string{"go"}, nil)
+	want := "line 2 is longer than 65536 bytes"
+	if _, err := c.Check("foo.go", strings.NewReader(content)); err == nil || err.Error() != want {
+		t.Errorf("Check() = %v, wanted %s", err, want)
+	}
+
+	c = NewChecker(testBoilerplate, []string{"go"}, nil, WithMaxLineLength(1<<20))
+	got, err := c.Check("foo.go", strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Check() = %v", err)
+	}
+	if len(got) != 1 || got[0].Kind != Missing {
+		t.Errorf("Check() = %v, wanted a Missing violation", got)
+	}
+
+	c = NewChecker(testBoilerplate, []string{"go"}, nil, WithMaxLineLength(10))
+	want = "line 2 is longer than 10 bytes"
+	if _, err := c.Check("foo.go", strings.NewReader("/*\nCopyright 2018 Matt Moore\n*/\n")); err == nil || err.Error() != want {
+		t.Errorf("Check() = %v, wanted %s", err, want)
+	}
+}
+
+func TestHeaderDiscard(t *testing.T) {
+	content := "/*\n" + strings.Repeat("// banner\n", 1000)
+	h := &header{scanner: bufio.NewScanner(strings.NewReader(content)), normalize: Normalize}
+	for i := 0; ; i++ {
+		h.discard(i)
+		if _, ok := h.line(i); !ok {
+			break
+		}
+		if got := len(h.lines); got > 1 {
+			t.Fatalf("len(lines) = %d after discarding through %d, wanted 1", got, i)
+		}
+	}
+
+	h = &header{scanner: bufio.NewScanner(strings.NewReader(content)), normalize: Normalize}
+	lines, raw := h.block(0, 3)
+	h.discard(3)
+	if want := []string{"/*", "// banner", "// banner"}; !cmp.Equal(lines, want) || !cmp.Equal(raw, want) {
+		t.Errorf("block() = %v, %v, wanted %v", lines, raw, want)
+	}
+	if line, ok := h.line(3); !ok || line != "// banner" {
+		t.Errorf("line(3) = %q, %v, wanted \"// banner\", true", line, ok)
+	}
+}
+
+// benchmarkFiles are the contents of files with and without headers.
+var benchmarkFiles = []string{
+	"/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n" + strings.Repeat("func foo() {}\n", 100),
+	"package foo\n" + strings.Repeat("func foo() {}\n", 100),
+}
+
+func BenchmarkCheck(b *testing.B) {
+	c := NewChecker(testBoilerplate, []string{"go"}, []*regexp.Regexp{regexp.MustCompile("vendor/")})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		path := "foo.go"
+		if c.SkipReason(path) != "" {
+			b.Fatal("SkipReason() != \"\"")
+		}
+		if _, err := c.Check(path, strings.NewReader(benchmarkFiles[i%len(benchmarkFiles)])); err != nil {
+			b.Fatalf("Check() = %v", err)
+		}
+	}
+}
+
+// BenchmarkCheckNewChecker is BenchmarkCheck, but prepares a Checker
+// for each file, for comparison.
+func BenchmarkCheckNewChecker(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		c := NewChecker(testBoilerplate, []string{"go"}, []*regexp.Regexp{regexp.MustCompile("vendor/")})
+		path := "foo.go"
+		if c.SkipReason(path) != "" {
+			b.Fatal("SkipReason() != \"\"")
+		}
+		if _, err := c.Check(path, strings.NewReader(benchmarkFiles[i%len(benchmarkFiles)])); err != nil {
+			b.Fatalf("Check() = %v", err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"strings"
+	"unicode"
+)
+
+// WithColumns reports the column at which each mismatched line of the
+// header first differs from the boilerplate, so that editors can point
+// at the offending character.
+func WithColumns() Option {
+	return func(c *Checker) {
+		c.columns = true
+	}
+}
+
+// column returns the column (counting runes from one) at which got, a
+// line of a file, first differs from want, the corresponding line of
+// the boilerplate, comparing them as Check does.  Both lines are in the
+// form returned by Normalize, whose years take as many runes as those
+// they replace, so the column is also that of the line in the file.
+func (c *Checker) column(want, got string) int {
+	if c.normalizeUnicode {
+		// This replaces runes one for one, so the columns stay the same.
+		want, got = strings.Map(toASCII, want), strings.Map(toASCII, got)
+	}
+	w, g := []rune(want), []rune(got)
+	i, j := 0, 0
+	switch {
+	case c.ignoreLeadingWhitespace:
+		i, j = indentLen(w), indentLen(g)
+	case c.tabWidth > 0:
+		// Indentation of the same width matches, however it is made.
+		if c.indentWidth(w) == c.indentWidth(g) {
+			i, j = indentLen(w), indentLen(g)
+		}
+	}
+	for i < len(w) && j < len(g) {
+		// Years and ranges of years match each other.
+		if wn, gn := c.yearLen(w[i:]), c.yearLen(g[j:]); wn > 0 && gn > 0 {
+			i, j = i+wn, j+gn
+			continue
+		}
+		// So do aliases and the text they stand for.
+		if wn, gn := c.aliasLen(w[i:], g[j:]); gn > 0 {
+			i, j = i+wn, j+gn
+			continue
+		}
+		if w[i] != g[j] && !(c.ignoreCase && unicode.ToLower(w[i]) == unicode.ToLower(g[j])) {
+			break
+		}
+		i, j = i+1, j+1
+	}
+	return j + 1
+}
+
+// indentLen returns the number of spaces and tabs that start r.
+func indentLen(r []rune) int {
+	n := 0
+	for n < len(r) && (r[n] == ' ' || r[n] == '\t') {
+		n++
+	}
+	return n
+}
+
+// indentWidth returns the width of the indentation of r, counting each
+// tab as tabWidth spaces.
+func (c *Checker) indentWidth(r []rune) int {
+	width := 0
+	for _, ch := range r[:indentLen(r)] {
+		if ch == '\t' {
+			width += c.tabWidth
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// aliasLen returns the number of runes of the new text of an alias at
+// the start of w, and of its old text at the start of g, or zeros if
+// there is no such alias.
+func (c *Checker) aliasLen(w, g []rune) (int, int) {
+	for _, alias := range c.aliases {
+		if strings.HasPrefix(string(g), alias[0]) && strings.HasPrefix(string(w), alias[1]) {
+			return len([]rune(alias[1])), len([]rune(alias[0]))
+		}
+	}
+	return 0, 0
+}
+
+// yearLen returns the number of runes in the normalized year, or range
+// (or, if we allow them, list) of years, at the start of r, or zero if
+// there is none.
+func (c *Checker) yearLen(r []rune) int {
+	s := string(r)
+	switch {
+	case c.yearLists:
+		// The list is made of ASCII, so has as many bytes as runes.
+		return len(matchNormalizedYearList.FindString(s))
+	case strings.HasPrefix(s, "YYYY-YYYY"):
+		return len("YYYY-YYYY")
+	case strings.HasPrefix(s, "YYYY"):
+		return len("YYYY")
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"testing"
+)
+
+func TestColumn(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+		got  string
+		col  int
+	}{{
+		name: "typo",
+		want: "Copyright YYYY Matt Moore",
+		got:  "Copyright YYYY Matt More",
+		col:  23,
+	}, {
+		name: "https",
+		want: "    http://www.apache.org/licenses/LICENSE-2.0",
+		got:  "    https://www.apache.org/licenses/LICENSE-2.0",
+		col:  9,
+	}, {
+		name: "tab",
+		want: "    http://www.apache.org/licenses/LICENSE-2.0",
+		got:  "\thttp://www.apache.org/licenses/LICENSE-2.0",
+		col:  1,
+	}, {
+		name: "tab with leading whitespace ignored",
+		opts: []Option{WithoutLeadingWhitespace()},
+		want: "    http://www.apache.org/licenses/LICENSE-2.0",
+		got:  "\thttps://www.apache.org/licenses/LICENSE-2.0",
+		col:  6,
+	}, {
+		name: "tab with a tab width",
+		opts: []Option{WithTabWidth(4)},
+		want: "    http://www.apache.org/licenses/LICENSE-2.0",
+		got:  "\thttps://www.apache.org/licenses/LICENSE-2.0",
+		col:  6,
+	}, {
+		name: "tab wider than the indentation",
+		opts: []Option{WithTabWidth(8)},
+		want: "    http://www.apache.org/licenses/LICENSE-2.0",
+		got:  "\thttp://www.apache.org/licenses/LICENSE-2.0",
+		col:  1,
+	}, {
+		name: "range of years",
+		want: "Copyright YYYY Matt Moore",
+		got:  "Copyright YYYY-YYYY Matt More",
+		col:  28,
+	}, {
+		name: "list of years",
+		opts: []Option{WithYearLists()},
+		want: "Copyright YYYY Matt Moore",
+		got:  "Copyright YYYY, YYYY Matt More",
+		col:  29,
+	}, {
+		name: "smart punctuation",
+		opts: []Option{WithNormalizedUnicode()},
+		want: `Licensed under the "License" - see it`,
+		got:  "Licensed under the \u201CLicense\u201D \u2014 se it",
+		col:  34,
+	}, {
+		name: "range of years with an en dash",
+		opts: []Option{WithNormalizedUnicode()},
+		want: "Copyright YYYY Matt Moore",
+		got:  "Copyright YYYY\u2013YYYY Matt More",
+		col:  28,
+	}, {
+		name: "alias",
+		opts: []Option{WithAlias("Matt Moore", "Acme Inc")},
+		want: "Copyright YYYY Acme Inc. All rights reserved.",
+		got:  "Copyright YYYY Matt Moore. Al rights reserved.",
+		col:  30,
+	}, {
+		name: "case",
+		opts: []Option{WithoutCaseSensitivity()},
+		want: "Copyright YYYY Matt Moore",
+		got:  "COPYRIGHT YYYY MATT MORE",
+		col:  23,
+	}, {
+		name: "multibyte runes",
+		want: "Copyright YYYY Mätt Moore",
+		got:  "Copyright YYYY Mätt More",
+		col:  23,
+	}, {
+		name: "extra characters",
+		want: "*/",
+		got:  "*/ end",
+		col:  3,
+	}, {
+		name: "missing characters",
+		want: "Copyright YYYY Matt Moore",
+		got:  "Copyright YYYY",
+		col:  15,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewChecker(testBoilerplate, []string{"go"}, nil, test.opts...)
+			if got := c.column(test.want, test.got); got != test.col {
+				t.Errorf("column() = %d, wanted %d", got, test.col)
+			}
+		})
+	}
+}
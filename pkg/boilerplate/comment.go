@@ -0,0 +1,120 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// CommentStyle describes the comment syntax of a language: comments
+// that run to the end of the line start with Line, and those that may
+// span lines start with Start and end with End.  Either may be empty if
+// the language lacks that kind of comment.
+type CommentStyle struct {
+	Line  string
+	Start string
+	End   string
+}
+
+// CommentStyles returns the comment styles of common languages, by file
+// extension (without the leading ".").
+func CommentStyles() map[string]CommentStyle {
+	c := CommentStyle{Line: "//", Start: "/*", End: "*/"}
+	hash := CommentStyle{Line: "#"}
+	return map[string]CommentStyle{
+		"go":   c,
+		"c":    c,
+		"h":    c,
+		"cc":   c,
+		"cpp":  c,
+		"py":   hash,
+		"sh":   hash,
+		"bash": hash,
+	}
+}
+
+// WithComments requires that the header of each file is within comments,
+// in the style given for its extension (without the leading ".") or, for
+// files without one, its base name.  Files that have no style are not
+// checked for comments.  This catches a boilerplate without comment syntax
+// of its own being pasted into a file as code.
+func WithComments(styles map[string]CommentStyle) Option {
+	return func(c *Checker) {
+		c.comments = styles
+	}
+}
+
+// commentStyle returns the comment style of the file at path, if any.
+func (c *Checker) commentStyle(path string) (CommentStyle, bool) {
+	if ext := filepath.Ext(path); ext != "" {
+		style, ok := c.comments[ext[1:]]
+		return style, ok
+	}
+	style, ok := c.comments[filepath.Base(path)]
+	return style, ok
+}
+
+// checkComments returns a violation for the first line of the header
+// starting at start, whose raw lines are given, that is not within a
+// comment.
+func (c *Checker) checkComments(path string, start int, raw []string) []Violation {
+	style, ok := c.commentStyle(path)
+	if !ok {
+		return nil
+	}
+	i := style.uncommented(raw)
+	if i < 0 {
+		return nil
+	}
+	return []Violation{{
+		Path:   path,
+		Line:   start + 1 + i,
+		Kind:   Uncommented,
+		Detail: Denormalize(raw[i]),
+	}}
+}
+
+// uncommented returns the index of the first of lines with anything but
+// whitespace outside of comments, or -1 if there is none.  The lines are
+// assumed to start outside of a comment.
+func (s CommentStyle) uncommented(lines []string) int {
+	inBlock := false
+	for i, line := range lines {
+		rest := strings.TrimSpace(line)
+		for rest != "" {
+			switch {
+			case inBlock:
+				end := strings.Index(rest, s.End)
+				if end < 0 {
+					rest = ""
+					continue
+				}
+				rest = strings.TrimSpace(rest[end+len(s.End):])
+				inBlock = false
+			case s.Line != "" && strings.HasPrefix(rest, s.Line):
+				rest = ""
+			case s.Start != "" && strings.HasPrefix(rest, s.Start):
+				rest = rest[len(s.Start):]
+				inBlock = true
+			default:
+				return i
+			}
+		}
+	}
+	return -1
+}
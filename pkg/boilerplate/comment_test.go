@@ -0,0 +1,132 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckComments(t *testing.T) {
+	hash := []string{"# Copyright YYYY Matt Moore", ""}
+	tests := []struct {
+		name        string
+		boilerplate []string
+		path        string
+		content     string
+		want        []Violation
+	}{{
+		name:    "block comment",
+		path:    "foo.go",
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:        "line comment",
+		boilerplate: hash,
+		path:        "foo.py",
+		content:     "# Copyright 2018 Matt Moore\n\nimport os\n",
+	}, {
+		name:        "comment of another language",
+		boilerplate: hash,
+		path:        "foo.go",
+		content:     "# Copyright 2018 Matt Moore\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Uncommented,
+			Detail: "# Copyright 2018 Matt Moore",
+		}},
+	}, {
+		name:        "block comment closed early",
+		boilerplate: []string{"/* Copyright YYYY Matt Moore */", "Licensed under the Apache License", ""},
+		path:        "foo.c",
+		content:     "/* Copyright 2018 Matt Moore */\nLicensed under the Apache License\n\nint x;\n",
+		want: []Violation{{
+			Path:   "foo.c",
+			Line:   2,
+			Kind:   Uncommented,
+			Detail: "Licensed under the Apache License",
+		}},
+	}, {
+		name:        "file with a style by name",
+		boilerplate: hash,
+		path:        "Dockerfile",
+		content:     "# Copyright 2018 Matt Moore\n\nFROM scratch\n",
+	}, {
+		name:        "file without a style",
+		boilerplate: hash,
+		path:        "foo.txt",
+		content:     "# Copyright 2018 Matt Moore\n\nsome text\n",
+	}}
+
+	styles := CommentStyles()
+	styles["Dockerfile"] = CommentStyle{Line: "#"}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			boilerplate := test.boilerplate
+			if boilerplate == nil {
+				boilerplate = testBoilerplate
+			}
+			c := NewChecker(boilerplate, []string{"go", "py", "c", "txt"}, nil, WithComments(styles))
+			got, err := c.Check(test.path, strings.NewReader(test.content))
+			if err != nil {
+				t.Fatalf("Check() = %v", err)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("Check() (-want, +got): %s", cmp.Diff(test.want, got))
+			}
+		})
+	}
+}
+
+func TestUncommented(t *testing.T) {
+	style := CommentStyle{Line: "//", Start: "/*", End: "*/"}
+	tests := []struct {
+		name  string
+		lines []string
+		want  int
+	}{{
+		name:  "line comments",
+		lines: []string{"// Copyright", "", "// License"},
+		want:  -1,
+	}, {
+		name:  "block comment",
+		lines: []string{"/*", "Copyright", "*/"},
+		want:  -1,
+	}, {
+		name:  "block comment followed by a line comment",
+		lines: []string{"/* Copyright */ // License"},
+		want:  -1,
+	}, {
+		name:  "code after a block comment",
+		lines: []string{"/*", "Copyright", "*/ package foo"},
+		want:  2,
+	}, {
+		name:  "code",
+		lines: []string{"// Copyright", "License"},
+		want:  1,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := style.uncommented(test.lines); got != test.want {
+				t.Errorf("uncommented() = %d, wanted %d", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithDiffContext describes mismatched headers with a unified diff (as
+// from diff -U) that shows n lines of context around each changed line,
+// instead of a diff of every line of the header from the first that
+// differs.
+func WithDiffContext(n int) Option {
+	return func(c *Checker) {
+		c.diffContext = n
+	}
+}
+
+// unified returns a unified diff of the boilerplate and the lines of the
+// header starting at start (and their raw text), covering the changed
+// lines from first up to last and the context around them.  The header
+// has as many lines as the boilerplate, so lines are compared in place.
+func (c *Checker) unified(start int, lines, raw []string, first, last int) string {
+	var b strings.Builder
+	for i := first; i < last; {
+		if c.lines[i] == lines[i] {
+			i++
+			continue
+		}
+		// Extend the hunk across changes whose context would overlap.
+		end := i + 1
+		for j := end; j < last && j < end+2*c.diffContext+1; j++ {
+			if c.lines[j] != lines[j] {
+				end = j + 1
+			}
+		}
+		lo, hi := i-c.diffContext, end+c.diffContext
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(lines) {
+			hi = len(lines)
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", start+lo+1, hi-lo, start+lo+1, hi-lo)
+		for j := lo; j < hi; {
+			if c.lines[j] == lines[j] {
+				fmt.Fprintf(&b, " %s\n", raw[j])
+				j++
+				continue
+			}
+			// Removals precede additions within each run of changes.
+			k := j
+			for k < hi && c.lines[k] != lines[k] {
+				k++
+			}
+			for _, want := range c.canonical[j:k] {
+				fmt.Fprintf(&b, "-%s\n", c.denormalize(want))
+			}
+			for _, got := range raw[j:k] {
+				fmt.Fprintf(&b, "+%s\n", got)
+			}
+			j = k
+		}
+		i = end
+	}
+	return b.String()
+}
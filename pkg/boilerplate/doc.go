@@ -0,0 +1,22 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package boilerplate implements checking that the headers of files
+// match a boilerplate, for use by the boilerplate-check tool or by
+// other programs that want to embed it.  A Checker reads the content of
+// each file from an io.Reader, so such programs can check files that are
+// compressed, archived, or not on disk at all.
+package boilerplate
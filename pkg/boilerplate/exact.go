@@ -0,0 +1,71 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import "strings"
+
+// WithExactLength reports a header that matches the boilerplate in full,
+// but whose comment goes on past it, e.g. with a description of the file.
+// By default, the boilerplate need only be a prefix of such a header.
+// There is no fix, since the lines that follow may well belong.
+func WithExactLength() Option {
+	return func(c *Checker) {
+		c.exactLength = true
+	}
+}
+
+// commentPrefix returns the comment markers that start the line, after
+// any indentation, e.g. "//" or "#".
+func commentPrefix(line string) string {
+	trimmed := strings.TrimLeft(line, " \t")
+	return trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, commentMarkers))]
+}
+
+// headerEnd returns the line after the header that matches in full at
+// start.  The header goes on past the boilerplate for as long as the lines
+// that directly follow it start with the same comment markers as its last
+// line, such as a description of the file after a boilerplate of "//"
+// comments.  Those after a closing "*/", or after a blank line, are
+// separate comments.
+func (c *Checker) headerEnd(h *header, start int) int {
+	end := start + len(c.lines)
+	prefix := commentPrefix(c.lines[len(c.lines)-1])
+	if prefix == "" || strings.HasSuffix(prefix, "*/") {
+		return end
+	}
+	for ; ; end++ {
+		if line, ok := h.line(end); !ok || !strings.HasPrefix(strings.TrimLeft(line, " \t"), prefix) {
+			return end
+		}
+	}
+}
+
+// checkLength returns a violation if the comment of the header that
+// matches in full at start goes on past the boilerplate.
+func (c *Checker) checkLength(path string, h *header, start int) []Violation {
+	end := start + len(c.lines)
+	if c.headerEnd(h, start) == end {
+		return nil
+	}
+	raw, _ := h.rawLine(end)
+	return []Violation{{
+		Path:   path,
+		Line:   end + 1,
+		Kind:   Overlong,
+		Detail: raw,
+	}}
+}
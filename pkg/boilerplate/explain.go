@@ -0,0 +1,72 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"fmt"
+	"io"
+)
+
+// Explain is like Check, but also writes an account of each step to w:
+// the lines that may start the header, where the search for it ended,
+// and how each line of the header compares with the boilerplate.  It is
+// meant to help see why a file is (or is not) reported.
+func (c *Checker) Explain(path string, r io.Reader, w io.Writer) ([]Violation, error) {
+	return c.check(path, r, &tracer{w: w})
+}
+
+// tracer writes the steps that Explain explains.  A nil tracer writes
+// nothing, so that Check need not explain itself.
+type tracer struct {
+	w io.Writer
+}
+
+// printf writes a line, unless t is nil.
+func (t *tracer) printf(format string, a ...interface{}) {
+	if t == nil {
+		return
+	}
+	fmt.Fprintf(t.w, format+"\n", a...)
+}
+
+// explainSearch explains the outcome of searching the first searched
+// lines for the header, which best starts at start, with the given
+// normalized and raw lines, or not at all if start is negative.  Lines
+// are quoted, to show any invisible differences.
+func (t *tracer) explainSearch(c *Checker, searched, start int, lines, raw []string) {
+	t.printf("searched %d lines for the start of the boilerplate", searched)
+	if start < 0 {
+		t.printf("no line starts the boilerplate, whose first line is %q", c.denormalize(c.canonical[0]))
+		return
+	}
+	t.printf("the boilerplate starts at line %d", start+1)
+	for i := range c.lines {
+		n := start + 1 + i
+		switch {
+		case i >= len(lines):
+			t.printf("line %d: missing, the file ends first", n)
+		case c.wildcards[i]:
+			t.printf("line %d: ok, as %s matches any line: %q", n, WildcardLine, raw[i])
+		case i == 0 && c.opens(c.normalize(raw[i])) && c.normalize(raw[i]) != c.lines[i]:
+			t.printf("line %d: ok, as an opener matches it: %q", n, raw[i])
+		case lines[i] == c.lines[i]:
+			t.printf("line %d: ok: %q", n, raw[i])
+		default:
+			t.printf("line %d: differs:\n    want: %q\n    got:  %q", n, c.denormalize(c.canonical[i]), raw[i])
+		}
+	}
+}
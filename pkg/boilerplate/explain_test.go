@@ -0,0 +1,117 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		name        string
+		boilerplate []string
+		opts        []Option
+		content     string
+		want        string
+	}{{
+		name:    "matching header",
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+		want: `line 1 may start the boilerplate: 4 of its 4 lines match
+searched 1 lines for the start of the boilerplate
+the boilerplate starts at line 1
+line 1: ok: "/*"
+line 2: ok: "Copyright 2018 Matt Moore"
+line 3: ok: "*/"
+line 4: ok: ""
+`,
+	}, {
+		name:    "mismatched header after a leading line",
+		opts:    []Option{WithLeadingLines()},
+		content: "#!/bin/sh\n/*\nCopyright 2018 Matt More\n",
+		want: `line 1 is a leading line, which does not count against the lines searched
+line 2 may start the boilerplate: 1 of its 4 lines match
+searched 3 lines for the start of the boilerplate
+the boilerplate starts at line 2
+line 2: ok: "/*"
+line 3: differs:
+    want: "` + Denormalize("Copyright YYYY Matt Moore") + `"
+    got:  "Copyright 2018 Matt More"
+line 4: missing, the file ends first
+line 5: missing, the file ends first
+`,
+	}, {
+		name:    "matching header with an opener",
+		opts:    []Option{WithOpeners([]*regexp.Regexp{regexp.MustCompile(`^/\* -\*- .* -\*-$`)})},
+		content: "/* -*- mode: go -*-\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+		want: `line 1 may start the boilerplate: 4 of its 4 lines match
+searched 1 lines for the start of the boilerplate
+the boilerplate starts at line 1
+line 1: ok, as an opener matches it: "/* -*- mode: go -*-"
+line 2: ok: "Copyright 2018 Matt Moore"
+line 3: ok: "*/"
+line 4: ok: ""
+`,
+	}, {
+		name:    "missing header",
+		opts:    []Option{WithMaxHeaderLines(2)},
+		content: "package foo\n\n/*\n",
+		want: `searched 2 lines for the start of the boilerplate
+no line starts the boilerplate, whose first line is "/*"
+`,
+	}, {
+		name:        "wildcard line",
+		boilerplate: []string{"/*", "{{*}}", "*/", ""},
+		content:     "/*\nGenerated from foo.proto.\n*/\n\npackage foo\n",
+		want: `line 1 may start the boilerplate: 4 of its 4 lines match
+searched 1 lines for the start of the boilerplate
+the boilerplate starts at line 1
+line 1: ok: "/*"
+line 2: ok, as {{*}} matches any line: "Generated from foo.proto."
+line 3: ok: "*/"
+line 4: ok: ""
+`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			boilerplate := test.boilerplate
+			if boilerplate == nil {
+				boilerplate = testBoilerplate
+			}
+			c := NewChecker(boilerplate, []string{"go"}, nil, test.opts...)
+			var got bytes.Buffer
+			want, err := c.Check("foo.go", strings.NewReader(test.content))
+			if err != nil {
+				t.Fatalf("Check() = %v", err)
+			}
+			violations, err := c.Explain("foo.go", strings.NewReader(test.content), &got)
+			if err != nil {
+				t.Fatalf("Explain() = %v", err)
+			}
+			if got.String() != test.want {
+				t.Errorf("Explain() wrote:\n%s\nwanted:\n%s", got.String(), test.want)
+			}
+			// Explaining doesn't change the outcome.
+			if len(violations) != len(want) {
+				t.Errorf("Explain() = %v, wanted %v", violations, want)
+			}
+		})
+	}
+}
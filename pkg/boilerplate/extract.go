@@ -0,0 +1,101 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrNoHeader is returned by Extract when a file does not start with a
+// comment.
+var ErrNoHeader = errors.New("no comment block found at the top of the file")
+
+// Extract reads the header of a file from r, so that it may serve as
+// the boilerplate for others.  Leading shebang, build tag, and blank
+// lines are skipped.  The header is then the next n lines or, if n is
+// zero, the comment block that begins there: a /* ... */ or <!-- ... -->
+// block, or a run of // or # comment lines.  The returned lines are
+// normalized.
+func Extract(r io.Reader, n int) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	var lines []string
+	first := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, utf8BOM)
+			first = false
+		}
+		if len(lines) == 0 && isPrologue(line) {
+			continue
+		}
+		if n == 0 && len(lines) > 0 && !continuesBlock(lines, line) {
+			break
+		}
+		lines = append(lines, Normalize(line))
+		if n > 0 && len(lines) == n {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if n == 0 && (len(lines) == 0 || !startsBlock(lines[0])) {
+		return nil, ErrNoHeader
+	}
+	return lines, nil
+}
+
+// startsBlock returns whether line starts a comment block.
+func startsBlock(line string) bool {
+	line = strings.TrimSpace(line)
+	for _, prefix := range []string{"/*", "<!--", "//", "#"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// continuesBlock returns whether line belongs to the comment block
+// made up of lines so far.
+func continuesBlock(lines []string, line string) bool {
+	start := strings.TrimSpace(lines[0])
+	last := strings.TrimSpace(lines[len(lines)-1])
+	switch {
+	case strings.HasPrefix(start, "/*"):
+		// Continue through the line that closes the comment.
+		if len(lines) == 1 {
+			last = last[len("/*"):]
+		}
+		return !strings.Contains(last, "*/")
+	case strings.HasPrefix(start, "<!--"):
+		if len(lines) == 1 {
+			last = last[len("<!--"):]
+		}
+		return !strings.Contains(last, "-->")
+	case strings.HasPrefix(start, "//"):
+		return strings.HasPrefix(strings.TrimSpace(line), "//")
+	case strings.HasPrefix(start, "#"):
+		return strings.HasPrefix(strings.TrimSpace(line), "#")
+	default:
+		return false
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		n       int
+		want    []string
+		wantErr error
+	}{{
+		name:    "block comment",
+		content: "/*\nCopyright 2019 Matt Moore\n*/\n\npackage foo\n",
+		want:    []string{"/*", "Copyright YYYY Matt Moore", "*/"},
+	}, {
+		name:    "single line block comment",
+		content: "/* Copyright 2019 Matt Moore */\npackage foo\n",
+		want:    []string{"/* Copyright YYYY Matt Moore */"},
+	}, {
+		name:    "line comments after a shebang",
+		content: "#!/bin/bash\n\n# Copyright 2019 Matt Moore\n# All rights reserved.\n\necho hi\n",
+		want:    []string{"# Copyright YYYY Matt Moore", "# All rights reserved."},
+	}, {
+		name:    "go comments after build tags",
+		content: utf8BOM + "// +build linux\n\n// Copyright 2019 Matt Moore\n\npackage foo\n",
+		want:    []string{"// Copyright YYYY Matt Moore"},
+	}, {
+		name:    "html comment",
+		content: "<!--\nCopyright 2019 Matt Moore\n-->\n<html>\n",
+		want:    []string{"<!--", "Copyright YYYY Matt Moore", "-->"},
+	}, {
+		name:    "line count",
+		content: "/*\nCopyright 2019 Matt Moore\n*/\n\npackage foo\n",
+		n:       4,
+		want:    []string{"/*", "Copyright YYYY Matt Moore", "*/", ""},
+	}, {
+		name:    "no comment",
+		content: "package foo\n",
+		wantErr: ErrNoHeader,
+	}, {
+		name:    "empty file",
+		wantErr: ErrNoHeader,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Extract(strings.NewReader(test.content), test.n)
+			if err != test.wantErr {
+				t.Fatalf("Extract() = %v, wanted %v", err, test.wantErr)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("Extract() (-want, +got): %s", cmp.Diff(test.want, got))
+			}
+		})
+	}
+}
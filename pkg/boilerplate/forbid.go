@@ -0,0 +1,48 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// NewForbiddenChecker returns a Checker for files with one of the given
+// extensions (without the leading ".") whose paths match none of
+// excludes, which should not start with the given lines of boilerplate,
+// e.g. a license that is being replaced.  Headers are found and compared
+// with the boilerplate just as NewChecker's are, but only those that
+// match in full are reported.
+func NewForbiddenChecker(boilerplate, extensions []string, excludes []*regexp.Regexp, opts ...Option) *Checker {
+	c := NewChecker(boilerplate, extensions, excludes, opts...)
+	c.forbidden = true
+	return c
+}
+
+// checkForbidden returns a violation if the header that best matches
+// the boilerplate, starting at start, matches it in full.
+func (c *Checker) checkForbidden(path string, start, best int) []Violation {
+	if start < 0 || best < len(c.lines) {
+		return nil
+	}
+	return []Violation{{
+		Path:   path,
+		Line:   start + 1,
+		Kind:   Forbidden,
+		Detail: fmt.Sprintf("lines %d through %d", start+1, start+len(c.lines)),
+	}}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckForbidden(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		content string
+		want    []Violation
+	}{{
+		name:    "forbidden header",
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Forbidden,
+			Detail: "lines 1 through 4",
+		}},
+	}, {
+		name:    "forbidden header after a shebang",
+		content: "#!/bin/bash\n/*\nCopyright 2018 Matt Moore\n*/\n\necho hi\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Kind:   Forbidden,
+			Detail: "lines 2 through 5",
+		}},
+	}, {
+		name:    "forbidden header with case ignored",
+		opts:    []Option{WithoutCaseSensitivity()},
+		content: "/*\nCOPYRIGHT 2018 MATT MOORE\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Forbidden,
+			Detail: "lines 1 through 4",
+		}},
+	}, {
+		name:    "different header",
+		content: "/*\nCopyright 2018 Someone Else\n*/\n\npackage foo\n",
+	}, {
+		name:    "incomplete header",
+		content: "/*\nCopyright 2018 Matt Moore\n",
+	}, {
+		name:    "no header",
+		content: "package foo\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewForbiddenChecker(testBoilerplate, []string{"go"}, nil, test.opts...)
+			got, err := c.Check("foo.go", strings.NewReader(test.content))
+			if err != nil {
+				t.Fatalf("Check() = %v", err)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("Check() (-want, +got): %s", cmp.Diff(test.want, got))
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Formatter renders violations for people or tools to consume.
+type Formatter interface {
+	// Format writes violations to w.
+	Format(w io.Writer, violations []Violation) error
+}
+
+// FormatterFunc adapts a function to a Formatter.
+type FormatterFunc func(w io.Writer, violations []Violation) error
+
+// Format calls f(w, violations).
+func (f FormatterFunc) Format(w io.Writer, violations []Violation) error {
+	return f(w, violations)
+}
+
+// ViolationFormatter is implemented by formatters that render each
+// violation on its own, so that violations can be rendered as they are
+// found rather than once they all are.
+type ViolationFormatter interface {
+	Formatter
+
+	// FormatViolation writes v to w, as Format writes each violation.
+	FormatViolation(w io.Writer, v Violation) error
+}
+
+// ViolationFormatterFunc adapts a function that renders one violation
+// to a ViolationFormatter.
+type ViolationFormatterFunc func(w io.Writer, v Violation) error
+
+// Format calls f(w, v) for each of violations in turn.
+func (f ViolationFormatterFunc) Format(w io.Writer, violations []Violation) error {
+	for _, v := range violations {
+		if err := f(w, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FormatViolation calls f(w, v).
+func (f ViolationFormatterFunc) FormatViolation(w io.Writer, v Violation) error {
+	return f(w, v)
+}
+
+var (
+	formattersMu sync.RWMutex
+	formatters   = map[string]Formatter{
+		"text":       ViolationFormatterFunc(formatText),
+		"json":       FormatterFunc(formatJSON),
+		"rdjsonl":    ViolationFormatterFunc(formatRDJSONL),
+		"github":     ViolationFormatterFunc(formatGitHub),
+		"sarif":      FormatterFunc(formatSARIF),
+		"checkstyle": FormatterFunc(formatCheckstyle),
+	}
+)
+
+// RegisterFormatter makes f available by name, e.g. to the --format flag
+// of boilerplate-check, alongside the built-in "text", "json", "rdjsonl",
+// "github", "sarif", and "checkstyle" formatters.  It panics if a
+// formatter is already registered by that name, so it is best called
+// from an init function.
+func RegisterFormatter(name string, f Formatter) {
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+	if _, ok := formatters[name]; ok {
+		panic(fmt.Sprintf("boilerplate: RegisterFormatter called twice for %q", name))
+	}
+	formatters[name] = f
+}
+
+// LookupFormatter returns the formatter registered by name, if any.
+func LookupFormatter(name string) (Formatter, bool) {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+	f, ok := formatters[name]
+	return f, ok
+}
+
+// FormatterNames returns the sorted names of the registered formatters.
+func FormatterNames() []string {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatText writes the violation in the "path:line: message" form that
+// reviewdog's errorformat consumes.
+func formatText(w io.Writer, v Violation) error {
+	s := v.String()
+	// Not every message ends with a newline, e.g. those of
+	// violations that concern the whole file.
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+// formatJSON writes the violations as a JSON array.
+func formatJSON(w io.Writer, violations []Violation) error {
+	if violations == nil {
+		violations = []Violation{}
+	}
+	return json.NewEncoder(w).Encode(violations)
+}
+
+// The reviewdog Diagnostic that we produce.  See:
+// https://github.com/reviewdog/reviewdog/tree/master/proto/rdf
+type rdPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column,omitempty"`
+}
+
+type rdRange struct {
+	Start rdPosition `json:"start"`
+}
+
+type rdLocation struct {
+	Path  string   `json:"path"`
+	Range *rdRange `json:"range,omitempty"`
+}
+
+type rdSource struct {
+	Name string `json:"name"`
+}
+
+type rdDiagnostic struct {
+	Message  string     `json:"message"`
+	Location rdLocation `json:"location"`
+	Severity string     `json:"severity"`
+	Source   rdSource   `json:"source"`
+}
+
+// formatRDJSONL writes the violation as a reviewdog Diagnostic on its
+// own line, for `reviewdog -f=rdjsonl`.
+func formatRDJSONL(w io.Writer, v Violation) error {
+	loc := rdLocation{Path: v.Path}
+	if v.Line != 0 {
+		loc.Range = &rdRange{Start: rdPosition{Line: v.Line, Column: v.Column}}
+	}
+	return json.NewEncoder(w).Encode(rdDiagnostic{
+		Message:  v.Message(),
+		Location: loc,
+		Severity: strings.ToUpper(v.Severity.String()),
+		Source:   rdSource{Name: "boilerplate-check"},
+	})
+}
+
+// githubData escapes the message of a workflow command, so that it
+// fits on one line.
+var githubData = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// githubProperty escapes the value of a workflow command's property.
+var githubProperty = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+// formatGitHub writes the violation as a GitHub Actions workflow
+// command, which annotates the offending line of pull requests.  See:
+// https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions
+func formatGitHub(w io.Writer, v Violation) error {
+	props := "file=" + githubProperty.Replace(v.Path)
+	if v.Line != 0 {
+		props += fmt.Sprintf(",line=%d", v.Line)
+	}
+	if v.Column != 0 {
+		props += fmt.Sprintf(",col=%d", v.Column)
+	}
+	msg := strings.TrimSuffix(v.Message(), "\n")
+	_, err := fmt.Fprintf(w, "::%s %s::%s\n", v.Severity, props, githubData.Replace(msg))
+	return err
+}
+
+// The subset of SARIF 2.1.0 that we produce.  See:
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// formatSARIF writes the violations as a SARIF log, which code scanning
+// services such as GitHub's can ingest.  Each kind of violation is a
+// rule, and the level of each result is the severity of its violation.
+func formatSARIF(w io.Writer, violations []Violation) error {
+	results := make([]sarifResult, 0, len(violations))
+	for _, v := range violations {
+		loc := sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(v.Path)},
+		}
+		if v.Line != 0 {
+			loc.Region = &sarifRegion{StartLine: v.Line, StartColumn: v.Column}
+		}
+		results = append(results, sarifResult{
+			RuleID:    v.Kind.String(),
+			Level:     v.Severity.String(),
+			Message:   sarifMessage{Text: v.Message()},
+			Locations: []sarifLocation{{PhysicalLocation: loc}},
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "boilerplate-check",
+				InformationURI: "https://github.com/mattmoor/boilerplate-check",
+			}},
+			Results: results,
+		}},
+	})
+}
+
+// The Checkstyle XML that we produce, which CI servers such as Jenkins
+// (with its warnings plugin) consume.  See:
+// https://checkstyle.org/
+type checkstyleResult struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// formatCheckstyle writes the violations as a Checkstyle XML document,
+// grouped by file in the order the files are first reported.  Each
+// message is a single line, leaving out the boilerplate or diff that
+// follows the summary of some violations.
+func formatCheckstyle(w io.Writer, violations []Violation) error {
+	result := checkstyleResult{Version: "4.3", Files: []checkstyleFile{}}
+	files := make(map[string]int, len(violations))
+	for _, v := range violations {
+		i, ok := files[v.Path]
+		if !ok {
+			i = len(result.Files)
+			files[v.Path] = i
+			result.Files = append(result.Files, checkstyleFile{Name: v.Path})
+		}
+		msg := v.message()
+		if n := strings.Index(msg, "\n"); n >= 0 {
+			msg = strings.TrimSuffix(msg[:n], ":")
+		}
+		result.Files[i].Errors = append(result.Files[i].Errors, checkstyleError{
+			Line:     v.Line,
+			Column:   v.Column,
+			Severity: v.Severity.String(),
+			Message:  msg,
+			Source:   "boilerplate-check." + v.Kind.String(),
+		})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(result); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
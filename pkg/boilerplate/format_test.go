@@ -0,0 +1,204 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormatters(t *testing.T) {
+	violations := []Violation{{
+		Path:   "foo/bar.go",
+		Line:   2,
+		Column: 23,
+		Kind:   Mismatch,
+		Detail: "-: \"Copyright 2020 Matt Moore\"\n+: \"Copyright 2020 Matt More\"\n",
+	}, {
+		Path:   "foo/baz.go",
+		Kind:   Unreadable,
+		Detail: "permission denied",
+	}}
+
+	tests := []struct {
+		name       string
+		violations []Violation
+		want       string
+	}{{
+		name:       "text",
+		violations: violations,
+		want: `foo/bar.go:2:23: found mismatched boilerplate lines:
+-: "Copyright 2020 Matt Moore"
++: "Copyright 2020 Matt More"
+foo/baz.go: could not read: permission denied
+`,
+	}, {
+		name:       "json",
+		violations: violations,
+		want: `[{"path":"foo/bar.go","line":2,"column":23,"kind":"mismatch","severity":"error","detail":"-: \"Copyright 2020 Matt Moore\"\n+: \"Copyright 2020 Matt More\"\n"},` +
+			`{"path":"foo/baz.go","line":0,"kind":"unreadable","severity":"error","detail":"permission denied"}]` + "\n",
+	}, {
+		name: "json",
+		want: "[]\n",
+	}, {
+		name:       "rdjsonl",
+		violations: violations,
+		want: `{"message":"found mismatched boilerplate lines:\n-: \"Copyright 2020 Matt Moore\"\n+: \"Copyright 2020 Matt More\"\n",` +
+			`"location":{"path":"foo/bar.go","range":{"start":{"line":2,"column":23}}},"severity":"ERROR","source":{"name":"boilerplate-check"}}` + "\n" +
+			`{"message":"could not read: permission denied","location":{"path":"foo/baz.go"},"severity":"ERROR","source":{"name":"boilerplate-check"}}` + "\n",
+	}, {
+		name:       "github",
+		violations: violations,
+		want: "::error file=foo/bar.go,line=2,col=23::found mismatched boilerplate lines:%0A-: \"Copyright 2020 Matt Moore\"%0A+: \"Copyright 2020 Matt More\"\n" +
+			"::error file=foo/baz.go::could not read: permission denied\n",
+	}, {
+		name:       "sarif",
+		violations: violations[1:],
+		want: `{
+  "version": "2.1.0",
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "boilerplate-check",
+          "informationUri": "https://github.com/mattmoor/boilerplate-check"
+        }
+      },
+      "results": [
+        {
+          "ruleId": "unreadable",
+          "level": "error",
+          "message": {
+            "text": "could not read: permission denied"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "foo/baz.go"
+                }
+              }
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`,
+	}, {
+		name:       "checkstyle",
+		violations: append(violations, Violation{Path: "foo/bar.go", Line: 1, Kind: Misplaced, Severity: SeverityWarning, Detail: "line 1 precedes the boilerplate at line 2"}),
+		want: `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3">
+  <file name="foo/bar.go">
+    <error line="2" column="23" severity="error" message="found mismatched boilerplate lines" source="boilerplate-check.mismatch"></error>
+    <error line="1" severity="warning" message="boilerplate is not at the top of the file: line 1 precedes the boilerplate at line 2" source="boilerplate-check.misplaced"></error>
+  </file>
+  <file name="foo/baz.go">
+    <error line="0" severity="error" message="could not read: permission denied" source="boilerplate-check.unreadable"></error>
+  </file>
+</checkstyle>
+`,
+	}, {
+		name: "checkstyle",
+		want: `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3"></checkstyle>
+`,
+	}}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %d", test.name, len(test.violations)), func(t *testing.T) {
+			f, ok := LookupFormatter(test.name)
+			if !ok {
+				t.Fatalf("LookupFormatter(%q) = not found", test.name)
+			}
+			out := new(bytes.Buffer)
+			if err := f.Format(out, test.violations); err != nil {
+				t.Fatalf("Format() = %v", err)
+			}
+			if got := out.String(); got != test.want {
+				t.Errorf("Format() (-want, +got): %s", cmp.Diff(test.want, got))
+			}
+		})
+	}
+}
+
+func TestSARIFRegion(t *testing.T) {
+	out := new(bytes.Buffer)
+	if err := formatSARIF(out, []Violation{{Path: "foo/bar.go", Line: 2, Column: 23, Kind: Mismatch}}); err != nil {
+		t.Fatalf("formatSARIF() = %v", err)
+	}
+	for _, want := range []string{`"startLine": 2`, `"startColumn": 23`, `"ruleId": "mismatch"`} {
+		if !bytes.Contains(out.Bytes(), []byte(want)) {
+			t.Errorf("formatSARIF() = %s\nwanted it to contain %s", out, want)
+		}
+	}
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	count := FormatterFunc(func(w io.Writer, violations []Violation) error {
+		_, err := fmt.Fprintln(w, len(violations))
+		return err
+	})
+	// Tests may be run more than once in the same process.
+	if _, ok := LookupFormatter("test-count"); !ok {
+		RegisterFormatter("test-count", count)
+	}
+
+	if got, want := FormatterNames(), []string{"checkstyle", "github", "json", "rdjsonl", "sarif", "test-count", "text"}; !cmp.Equal(got, want) {
+		t.Errorf("FormatterNames() = %v, wanted %v", got, want)
+	}
+	f, ok := LookupFormatter("test-count")
+	if !ok {
+		t.Fatal("LookupFormatter() = not found")
+	}
+	out := new(bytes.Buffer)
+	if err := f.Format(out, []Violation{{Path: "foo.go", Kind: Missing}}); err != nil {
+		t.Fatalf("Format() = %v", err)
+	}
+	if got, want := out.String(), "1\n"; got != want {
+		t.Errorf("Format() = %q, wanted %q", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterFormatter() twice didn't panic")
+		}
+	}()
+	RegisterFormatter("test-count", count)
+}
+
+func TestGitHubEscaping(t *testing.T) {
+	v := Violation{
+		Path:   "a,b:c%.go",
+		Kind:   Unreadable,
+		Detail: "100% unreadable\r\n",
+	}
+	out := new(bytes.Buffer)
+	if err := formatGitHub(out, v); err != nil {
+		t.Fatalf("formatGitHub() = %v", err)
+	}
+	if got, want := out.String(), "::error file=a%2Cb%3Ac%25.go::could not read: 100%25 unreadable%0D\n"; got != want {
+		t.Errorf("formatGitHub() = %q, wanted %q", got, want)
+	}
+}
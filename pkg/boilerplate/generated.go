@@ -0,0 +1,80 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultGeneratedMarker matches the line that marks Go files as
+// generated, per https://golang.org/s/generatedcode.
+var DefaultGeneratedMarker = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// WithGeneratedMarker requires the files whose paths files matches, and
+// whose header otherwise matches, to also have a line that marker
+// matches, as generated files do: before the header, within it, or in
+// as many lines (or bytes) after it as are searched for the header.
+// There is no fix, since only the generator knows what the line should
+// say.
+func WithGeneratedMarker(files, marker *regexp.Regexp) Option {
+	return func(c *Checker) {
+		c.generatedFiles = files
+		c.generatedMarker = marker
+	}
+}
+
+// generated returns whether the file at path must have a generated
+// code marker.
+func (c *Checker) generated(path string) bool {
+	return c.generatedFiles != nil && c.generatedFiles.MatchString(path)
+}
+
+// checkGenerated returns a violation if no line of the header that
+// matches in full at start, or of those searched after it, is a generated
+// code marker, unless marked says one preceded it.
+func (c *Checker) checkGenerated(path string, h *header, start int, marked bool) []Violation {
+	if marked {
+		return nil
+	}
+	end := start + len(c.lines)
+	for i := start; i < end || c.searchesAfter(h, i, end); i++ {
+		line, ok := h.rawLine(i)
+		if !ok {
+			break
+		}
+		if c.generatedMarker.MatchString(line) {
+			return nil
+		}
+	}
+	return []Violation{{
+		Path:   path,
+		Line:   start + 1,
+		Kind:   Unmarked,
+		Detail: fmt.Sprintf("no line matches %s", c.generatedMarker),
+	}}
+}
+
+// searchesAfter returns whether line i is among those searched after the
+// header that ends before line end: as many lines or bytes as searches
+// allows for the header itself, or the rest of the file.
+func (c *Checker) searchesAfter(h *header, i, end int) bool {
+	if c.maxHeaderBytes > 0 && !c.matchAnywhere {
+		return h.offset(i)-h.offset(end) < c.maxHeaderBytes
+	}
+	return c.searches(h, i, end)
+}
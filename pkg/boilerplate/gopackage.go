@@ -0,0 +1,54 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import "strings"
+
+// WithGoPackageClause reports a Go file whose header matches in full, but
+// is followed by anything but blank lines before its package clause, such
+// as a package comment or build constraints.  Files of other languages,
+// by their extension, are not affected.  There is no fix, since what
+// comes between may belong elsewhere.
+func WithGoPackageClause() Option {
+	return func(c *Checker) {
+		c.goPackage = true
+	}
+}
+
+// checkPackage returns a violation if the first line that is not blank
+// after the header that matches in full at start does not begin the
+// package clause.
+func (c *Checker) checkPackage(path string, h *header, start int) []Violation {
+	for i := start + len(c.lines); ; i++ {
+		line, ok := h.rawLine(i)
+		if !ok {
+			return nil
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "package ") {
+			return nil
+		}
+		return []Violation{{
+			Path:   path,
+			Line:   i + 1,
+			Kind:   Interposed,
+			Detail: line,
+		}}
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// matchHolder matches the copyright notice of a line, capturing what
+// follows its (optional) symbol and years: the holder, and anything after
+// it.
+var matchHolder = regexp.MustCompile(`(?i)copyright(?:\s*(?:\(c\)|©))?\s*` +
+	`(?:[0-9]{4}(?:(?:\s*[,\-\x{2013}]\s*|\s+)[0-9]{4})*)?,?\s*(.*)$`)
+
+// WithHolder requires the copyright lines of a header that otherwise
+// matches to name holder exactly, as they might not if aliases let a
+// former holder match, or a header was copied from another project.  The
+// copyright lines are those whose line in the boilerplate mentions a
+// copyright, and the holder is what follows the copyright symbol and
+// years, up to the end of a word.
+func WithHolder(holder string) Option {
+	return func(c *Checker) {
+		c.holder = holder
+	}
+}
+
+// checkHolder returns the copyright lines of the header starting at
+// start, whose raw lines are given, that do not name the holder.
+func (c *Checker) checkHolder(path string, start int, raw []string) []Violation {
+	var violations []Violation
+	for i, want := range c.lines {
+		if !strings.Contains(strings.ToLower(want), "copyright") {
+			continue
+		}
+		m := matchHolder.FindStringSubmatch(raw[i])
+		if m == nil {
+			continue
+		}
+		found := strings.TrimSpace(m[1])
+		if namesHolder(found, c.holder) {
+			continue
+		}
+		violations = append(violations, Violation{
+			Path:   path,
+			Line:   start + 1 + i,
+			Kind:   Misattributed,
+			Detail: fmt.Sprintf("found %q, expected %q", found, c.holder),
+		})
+	}
+	return violations
+}
+
+// namesHolder returns whether text starts with holder, followed by
+// anything but more of the same word, e.g. a period or the end of a
+// comment.
+func namesHolder(text, holder string) bool {
+	if !strings.HasPrefix(text, holder) {
+		return false
+	}
+	next, _ := utf8.DecodeRuneInString(text[len(holder):])
+	return next == utf8.RuneError || !(unicode.IsLetter(next) || unicode.IsDigit(next))
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"sort"
+	"strings"
+)
+
+// licenseHeaders holds the headers of common licenses, by their SPDX
+// identifiers, uncommented.  The line of each that is "Copyright" names
+// the copyright holder.
+var licenseHeaders = map[string]string{
+	"Apache-2.0": `Copyright YYYY
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.`,
+
+	"MIT": `Copyright (c) YYYY
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.`,
+
+	"BSD-3-Clause": `Copyright (c) YYYY
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its
+   contributors may be used to endorse or promote products derived from
+   this software without specific prior written permission.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.`,
+}
+
+// Licenses returns the sorted SPDX identifiers of the licenses whose
+// headers LicenseHeaders knows.
+func Licenses() []string {
+	ids := make([]string, 0, len(licenseHeaders))
+	for id := range licenseHeaders {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// LicenseHeaders returns the headers of the license identified by id, in
+// the comment style, each as the lines a boilerplate file of it would
+// hold, or false if the license is not one of Licenses.  A style with both
+// block and line comments has a header in each, the block comment first,
+// as the one to insert.  The copyright line names holder, or is a
+// WildcardLine if holder is empty, so that any holder matches.
+func LicenseHeaders(id, holder string, style CommentStyle) ([][]string, bool) {
+	text, ok := licenseHeaders[id]
+	if !ok {
+		return nil, false
+	}
+	var headers [][]string
+	if style.Start != "" {
+		headers = append(headers, licenseHeader(text, holder, style))
+	}
+	if style.Line != "" {
+		headers = append(headers, licenseHeader(text, holder, CommentStyle{Line: style.Line}))
+	}
+	return headers, true
+}
+
+// licenseHeader returns the lines of the license text in a block comment,
+// if the style has one, or else in line comments.
+func licenseHeader(text, holder string, style CommentStyle) []string {
+	var lines []string
+	if style.Start != "" {
+		lines = append(lines, style.Start)
+	}
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case strings.HasPrefix(line, "Copyright") && holder == "":
+			lines = append(lines, WildcardLine)
+			continue
+		case strings.HasPrefix(line, "Copyright"):
+			line += " " + holder
+		}
+		switch {
+		case style.Start != "":
+		case line == "":
+			line = style.Line
+		default:
+			line = style.Line + " " + line
+		}
+		lines = append(lines, line)
+	}
+	if style.End != "" {
+		lines = append(lines, style.End)
+	}
+	// Like a file of the boilerplate, which ends in a newline.
+	return append(lines, "")
+}
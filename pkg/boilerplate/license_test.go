@@ -0,0 +1,109 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLicenseHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		holder string
+		style  CommentStyle
+		// want holds the first lines of each header.
+		want [][]string
+	}{{
+		name:  "block and line comments",
+		id:    "Apache-2.0",
+		style: CommentStyle{Line: "//", Start: "/*", End: "*/"},
+		want: [][]string{{
+			"/*",
+			"{{*}}",
+			"",
+			`Licensed under the Apache License, Version 2.0 (the "License");`,
+		}, {
+			"{{*}}",
+			"//",
+			`// Licensed under the Apache License, Version 2.0 (the "License");`,
+		}},
+	}, {
+		name:   "line comments",
+		id:     "BSD-3-Clause",
+		holder: "Matt Moore",
+		style:  CommentStyle{Line: "#"},
+		want: [][]string{{
+			"# Copyright (c) YYYY Matt Moore",
+			"# All rights reserved.",
+			"#",
+			"# Redistribution and use in source and binary forms, with or without",
+		}},
+	}, {
+		name:  "block comments",
+		id:    "MIT",
+		style: CommentStyle{Start: "<!--", End: "-->"},
+		want: [][]string{{
+			"<!--",
+			"{{*}}",
+			"",
+			"Permission is hereby granted, free of charge, to any person obtaining a copy",
+		}},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			headers, ok := LicenseHeaders(test.id, test.holder, test.style)
+			if !ok {
+				t.Fatalf("LicenseHeaders(%q) = false, wanted true", test.id)
+			}
+			if len(headers) != len(test.want) {
+				t.Fatalf("LicenseHeaders() = %d headers, wanted %d", len(headers), len(test.want))
+			}
+			for i, got := range headers {
+				if diff := cmp.Diff(test.want[i], got[:len(test.want[i])]); diff != "" {
+					t.Errorf("LicenseHeaders()[%d] (-want +got): %s", i, diff)
+				}
+				// The header ends in a newline, like a boilerplate file.
+				if last := got[len(got)-1]; last != "" {
+					t.Errorf("LicenseHeaders()[%d] ends in %q, wanted a blank line", i, last)
+				}
+			}
+			if end := test.style.End; end != "" {
+				got := headers[0]
+				if got[len(got)-2] != end {
+					t.Errorf("LicenseHeaders()[0] ends its comment with %q, wanted %q", got[len(got)-2], end)
+				}
+			}
+		})
+	}
+
+	for _, id := range Licenses() {
+		headers, _ := LicenseHeaders(id, "", CommentStyle{Line: "#"})
+		for _, line := range headers[0] {
+			if line != "" && line != WildcardLine && !strings.HasPrefix(line, "#") {
+				t.Errorf("LicenseHeaders(%q) has uncommented line %q", id, line)
+			}
+		}
+	}
+	if _, ok := LicenseHeaders("GPL-3.0", "", CommentStyle{Line: "#"}); ok {
+		t.Error(`LicenseHeaders("GPL-3.0") = true, wanted false`)
+	}
+}
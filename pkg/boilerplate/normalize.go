@@ -0,0 +1,104 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// utf8BOM is the byte order mark that may prefix the first line of a file.
+const utf8BOM = "\uFEFF"
+
+// TODO(mattmoor): Fix this y10k bug.
+var matchYear = regexp.MustCompile("[0-9][0-9][0-9][0-9]")
+
+// Normalize strips year-like strings out in favor of YYYY,
+// so that we do not complain about older files with otherwise
+// fine headers.
+func Normalize(line string) string {
+	// Most lines have no digits, so skip the regexp for them.
+	if !strings.ContainsAny(line, "0123456789") {
+		return line
+	}
+	return matchYear.ReplaceAllString(line, "YYYY")
+}
+
+// asciiPunctuation maps the typographic punctuation that word processors
+// substitute for ASCII to the ASCII it stands for.  Each maps to a single
+// rune, so that columns are unchanged.
+var asciiPunctuation = map[rune]rune{
+	'\u2018': '\'', // left single quotation mark
+	'\u2019': '\'', // right single quotation mark
+	'\u201A': '\'', // single low-9 quotation mark
+	'\u201B': '\'', // single high-reversed-9 quotation mark
+	'\u201C': '"',  // left double quotation mark
+	'\u201D': '"',  // right double quotation mark
+	'\u201E': '"',  // double low-9 quotation mark
+	'\u201F': '"',  // double high-reversed-9 quotation mark
+	'\u2010': '-',  // hyphen
+	'\u2011': '-',  // non-breaking hyphen
+	'\u2012': '-',  // figure dash
+	'\u2013': '-',  // en dash
+	'\u2014': '-',  // em dash
+	'\u2212': '-',  // minus sign
+	'\u00A0': ' ',  // no-break space
+	'\u2007': ' ',  // figure space
+	'\u202F': ' ',  // narrow no-break space
+}
+
+// toASCII returns the ASCII that r stands for, if it is typographic
+// punctuation, or else r.
+func toASCII(r rune) rune {
+	if a, ok := asciiPunctuation[r]; ok {
+		return a
+	}
+	return r
+}
+
+// Denormalize replaces YYYY with the current year.
+func Denormalize(line string) string {
+	return strings.ReplaceAll(line, "YYYY", fmt.Sprint(time.Now().Year()))
+}
+
+// normalizeYears normalizes line, unless the Checker was made
+// WithLiteralYears.
+func (c *Checker) normalizeYears(line string) string {
+	if c.literalYears {
+		return line
+	}
+	return Normalize(line)
+}
+
+// denormalize denormalizes line, unless the Checker was made
+// WithLiteralYears, whose lines have no YYYY to replace but their own.
+func (c *Checker) denormalize(line string) string {
+	if c.literalYears {
+		return line
+	}
+	return Denormalize(line)
+}
+
+func (c *Checker) denormalizeAll(lines []string) []string {
+	ret := make([]string, 0, len(lines))
+	for _, line := range lines {
+		ret = append(ret, c.denormalize(line))
+	}
+	return ret
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{{
+		line: "Copyright 2020 Matt Moore",
+		want: "Copyright YYYY Matt Moore",
+	}, {
+		line: "Copyright 2019-2020 Matt Moore",
+		want: "Copyright YYYY-YYYY Matt Moore",
+	}, {
+		line: "No years here",
+		want: "No years here",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			if got := Normalize(test.line); got != test.want {
+				t.Errorf("Normalize() = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDenormalize(t *testing.T) {
+	got := Denormalize("Copyright YYYY Matt Moore")
+	want := fmt.Sprintf("Copyright %d Matt Moore", time.Now().Year())
+	if got != want {
+		t.Errorf("Denormalize() = %q, wanted %q", got, want)
+	}
+}
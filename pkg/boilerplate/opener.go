@@ -0,0 +1,41 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import "regexp"
+
+// WithOpeners lets the first line of a header be any line that one of
+// openers matches, instead of the first line of the boilerplate,
+// e.g. a comment opener followed by an editor modeline.  Lines are
+// normalized before they are matched, as they are before they are
+// compared, and the rest of the header is compared as usual.
+func WithOpeners(openers []*regexp.Regexp) Option {
+	return func(c *Checker) {
+		c.openers = openers
+	}
+}
+
+// opens returns whether line, which is normalized, may stand in for the
+// first line of the boilerplate.
+func (c *Checker) opens(line string) bool {
+	for _, opener := range c.openers {
+		if opener.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import "strings"
+
+// WithRemnants reports license text left after a header that matches in
+// full, such as the rest of a header that was pasted twice or half merged.
+// To be conservative, so that package documentation is not mistaken for
+// it, a line is license text only if, less any comment markers, it is a
+// line of the boilerplate of at least minRemnantWords words.  We look
+// until the first line that is not blank, a comment, or license text,
+// which is taken to start the code.  There is no fix, since which of the
+// lines belong is a judgment call.
+func WithRemnants() Option {
+	return func(c *Checker) {
+		c.forbidRemnants = true
+	}
+}
+
+// minRemnantWords is the fewest words a line of the boilerplate must have
+// for a copy of it to be reported as a remnant, so that short lines like
+// "All rights reserved." are not.
+const minRemnantWords = 4
+
+// commentMarkers holds the characters of the comment markers of common
+// languages, which are trimmed from lines before they are compared.
+const commentMarkers = "/*#;-!<>"
+
+// uncomment returns the line less any comment markers around it.
+func uncomment(line string) string {
+	return strings.TrimSpace(strings.Trim(strings.TrimSpace(line), commentMarkers))
+}
+
+// checkRemnants returns a violation for the first line of license text
+// among those after the header that matches in full at start, before the
+// code.
+func (c *Checker) checkRemnants(path string, h *header, start int) []Violation {
+	text := make(map[string]bool, len(c.lines))
+	for _, line := range c.lines {
+		if line = uncomment(line); len(strings.Fields(line)) >= minRemnantWords {
+			text[line] = true
+		}
+	}
+	// A remnant is at most as long as the boilerplate.
+	end := start + 2*len(c.lines)
+	for i := start + len(c.lines); i < end; i++ {
+		line, ok := h.line(i)
+		if !ok {
+			return nil
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case text[uncomment(line)]:
+			raw, _ := h.rawLine(i)
+			return []Violation{{
+				Path:   path,
+				Line:   i + 1,
+				Kind:   Remnant,
+				Detail: raw,
+			}}
+		case trimmed == "" || strings.ContainsAny(trimmed[:1], commentMarkers):
+			continue
+		default:
+			return nil
+		}
+	}
+	return nil
+}
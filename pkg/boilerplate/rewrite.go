@@ -0,0 +1,72 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import "strings"
+
+// WithRewrites gives Mismatch violations a fix that replaces the header
+// with the boilerplate, e.g. to correct a typo.  Unlike other fixes, this
+// can discard text that was not a mistake, such as the header of another
+// license, so it is off by default.
+func WithRewrites() Option {
+	return func(c *Checker) {
+		c.rewriteHeaders = true
+	}
+}
+
+// rewrite returns the edit that replaces the mismatched header at start
+// with the boilerplate, or nil if it includes a wildcard line.  The lines
+// are those of the file from start, normalized, which should run past
+// the end of the header if the file does.
+func (c *Checker) rewrite(start int, lines []string) *Edit {
+	fix := c.insert(start, c.canonical)
+	if fix != nil {
+		fix.End = start + c.extent(lines)
+	}
+	return fix
+}
+
+// extent returns the number of lines in the mismatched header that the
+// lines start with.  A header with a line too many or too few still ends
+// with the last line of the boilerplate that is not blank (e.g. "*/"), so
+// we take the line nearest where that should be that matches it, and any
+// blank lines after it that the boilerplate ends with.  If there is none,
+// the header is as long as the boilerplate.
+func (c *Checker) extent(lines []string) int {
+	last := len(c.lines) - 1
+	for last > 0 && strings.TrimSpace(c.lines[last]) == "" {
+		last--
+	}
+	for d := 0; d < len(c.lines); d++ {
+		for _, j := range []int{last - d, last + d} {
+			// The first line opens the header, so only closes it when
+			// the boilerplate is a single line.
+			if j < 0 || (j == 0 && last > 0) || j >= len(lines) || lines[j] != c.lines[last] {
+				continue
+			}
+			n := j + 1
+			for _, want := range c.lines[last+1:] {
+				if n == len(lines) || lines[n] != want {
+					break
+				}
+				n++
+			}
+			return n
+		}
+	}
+	return len(c.lines)
+}
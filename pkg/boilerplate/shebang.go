@@ -0,0 +1,73 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Interpreters returns the file extensions (without the leading ".") of
+// the languages of common script interpreters, by interpreter name.
+func Interpreters() map[string]string {
+	return map[string]string{
+		"python":  "py",
+		"python2": "py",
+		"python3": "py",
+		"sh":      "sh",
+		"bash":    "sh",
+		"dash":    "sh",
+		"ksh":     "sh",
+		"zsh":     "sh",
+		"ruby":    "rb",
+		"perl":    "pl",
+		"node":    "js",
+		"php":     "php",
+	}
+}
+
+// ShebangExtension returns the extension, per interpreters, of the
+// language of a script whose first line is line, e.g. "py" for
+// "#!/usr/bin/env python3", or "" if line is not a shebang or names an
+// interpreter that interpreters lacks.  An interpreter is looked up by
+// its name, and then by its name without a version, so that python3.8
+// is a python script.
+func ShebangExtension(line string, interpreters map[string]string) string {
+	if !strings.HasPrefix(line, "#!") {
+		return ""
+	}
+	fields := strings.Fields(line[2:])
+	if len(fields) == 0 {
+		return ""
+	}
+	name := filepath.Base(fields[0])
+	if name == "env" {
+		// Skip the options and variables of env, as in
+		// "#!/usr/bin/env -S FOO=bar python3 -u".
+		name = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+				name = filepath.Base(field)
+				break
+			}
+		}
+	}
+	if ext, ok := interpreters[name]; ok {
+		return ext
+	}
+	return interpreters[strings.TrimRight(name, "0123456789.")]
+}
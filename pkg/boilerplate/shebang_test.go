@@ -0,0 +1,64 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import "testing"
+
+func TestShebangExtension(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{{
+		line: "#!/usr/bin/env python",
+		want: "py",
+	}, {
+		line: "#!/usr/bin/python3",
+		want: "py",
+	}, {
+		line: "#! /bin/bash -e",
+		want: "sh",
+	}, {
+		line: "#!/usr/bin/env python3.8",
+		want: "py",
+	}, {
+		line: "#!/usr/bin/env -S FOO=bar ruby -w",
+		want: "rb",
+	}, {
+		line: "#!/usr/bin/env custom",
+		want: "",
+	}, {
+		line: "#!/usr/bin/env",
+		want: "",
+	}, {
+		line: "#!",
+		want: "",
+	}, {
+		line: "# just a comment",
+		want: "",
+	}, {
+		line: "package main",
+		want: "",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			if got := ShebangExtension(test.line, Interpreters()); got != test.want {
+				t.Errorf("ShebangExtension() = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const spdxPrefix = "SPDX-License-Identifier:"
+
+// NewSPDXChecker returns a Checker for files with one of the given
+// extensions (without the leading ".") whose paths match none of
+// excludes, which should identify their license with an
+// SPDX-License-Identifier line among their leading lines, instead of
+// starting with a boilerplate.
+func NewSPDXChecker(license string, extensions []string, excludes []*regexp.Regexp, opts ...Option) *Checker {
+	c := NewChecker(nil, extensions, excludes, opts...)
+	c.spdx = license
+	return c
+}
+
+// WithCopyright requires that a line matching the pattern accompany the
+// SPDX-License-Identifier line of a Checker from NewSPDXChecker.
+func WithCopyright(pattern *regexp.Regexp) Option {
+	return func(c *Checker) {
+		c.copyright = pattern
+	}
+}
+
+// checkSPDX returns the ways in which the leading lines of the file at
+// path fail to identify its license (and copyright).
+func (c *Checker) checkSPDX(path string, h *header) ([]Violation, error) {
+	var violations []Violation
+	found, copyright := false, c.copyright == nil
+	for i := 0; c.searches(h, i, 0); i++ {
+		h.discard(i)
+		line, ok := h.rawLine(i)
+		if !ok {
+			break
+		}
+		if !copyright && c.copyright.MatchString(line) {
+			copyright = true
+		}
+		if j := strings.Index(line, spdxPrefix); j >= 0 && !found {
+			found = true
+			if got := spdxID(line[j+len(spdxPrefix):]); !c.sameLicense(got) {
+				violations = append(violations, Violation{
+					Path:   path,
+					Line:   i + 1,
+					Kind:   Mismatch,
+					Detail: cmp.Diff([]string{c.spdx}, []string{got}),
+				})
+			}
+		}
+		if found && copyright {
+			break
+		}
+	}
+	if err := h.err(); err != nil {
+		return nil, err
+	}
+
+	if !found {
+		violations = append(violations, Violation{
+			Path:   path,
+			Line:   1,
+			Kind:   Missing,
+			Detail: spdxPrefix + " " + c.spdx + "\n",
+		})
+	}
+	if !copyright {
+		violations = append(violations, Violation{
+			Path:   path,
+			Line:   1,
+			Kind:   Missing,
+			Detail: "a copyright line matching " + c.copyright.String() + "\n",
+		})
+	}
+	return violations, nil
+}
+
+// sameLicense returns whether the identifier names our license.
+func (c *Checker) sameLicense(id string) bool {
+	if c.ignoreCase {
+		return strings.EqualFold(id, c.spdx)
+	}
+	return id == c.spdx
+}
+
+// spdxID returns the license identifier that follows the
+// SPDX-License-Identifier prefix, less any comment terminator.
+func spdxID(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(s, "*/")
+	s = strings.TrimSuffix(s, "-->")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckSPDX(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		content string
+		want    []Violation
+	}{{
+		name:    "matching identifier",
+		content: "// SPDX-License-Identifier: Apache-2.0\n\npackage foo\n",
+	}, {
+		name:    "matching identifier in a block comment",
+		content: "/* SPDX-License-Identifier: Apache-2.0 */\n\npackage foo\n",
+	}, {
+		name:    "matching identifier after a shebang",
+		content: "#!/bin/bash\n# SPDX-License-Identifier: Apache-2.0\n\necho hi\n",
+	}, {
+		name:    "missing identifier",
+		content: "package foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Missing,
+			Detail: "SPDX-License-Identifier: Apache-2.0\n",
+		}},
+	}, {
+		name:    "identifier past the window",
+		content: strings.Repeat("\n", 10) + "// SPDX-License-Identifier: Apache-2.0\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Missing,
+			Detail: "SPDX-License-Identifier: Apache-2.0\n",
+		}},
+	}, {
+		name:    "identifier within the bytes",
+		opts:    []Option{WithMaxHeaderBytes(1000)},
+		content: strings.Repeat("\n", 10) + "// SPDX-License-Identifier: Apache-2.0\n",
+	}, {
+		name:    "identifier past the bytes",
+		opts:    []Option{WithMaxHeaderBytes(10)},
+		content: strings.Repeat("\n", 10) + "// SPDX-License-Identifier: Apache-2.0\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Missing,
+			Detail: "SPDX-License-Identifier: Apache-2.0\n",
+		}},
+	}, {
+		name:    "mismatched identifier",
+		content: "// SPDX-License-Identifier: MIT\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Mismatch,
+			Detail: cmp.Diff([]string{"Apache-2.0"}, []string{"MIT"}),
+		}},
+	}, {
+		name:    "identifier with case ignored",
+		opts:    []Option{WithoutCaseSensitivity()},
+		content: "// SPDX-License-Identifier: apache-2.0\n\npackage foo\n",
+	}, {
+		name:    "matching copyright",
+		opts:    []Option{WithCopyright(regexp.MustCompile(`^// Copyright \d{4} Matt Moore$`))},
+		content: "// Copyright 2019 Matt Moore\n// SPDX-License-Identifier: Apache-2.0\n\npackage foo\n",
+	}, {
+		name:    "missing copyright",
+		opts:    []Option{WithCopyright(regexp.MustCompile(`^// Copyright \d{4} Matt Moore$`))},
+		content: "// SPDX-License-Identifier: Apache-2.0\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   1,
+			Kind:   Missing,
+			Detail: "a copyright line matching ^// Copyright \\d{4} Matt Moore$\n",
+		}},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewSPDXChecker("Apache-2.0", []string{"go"}, nil, test.opts...)
+			got, err := c.Check("foo.go", strings.NewReader(test.content))
+			if err != nil {
+				t.Fatalf("Check() = %v", err)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("Check() (-want, +got): %s", cmp.Diff(test.want, got))
+			}
+		})
+	}
+}
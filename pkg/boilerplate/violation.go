@@ -0,0 +1,304 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind is the kind of a Violation.
+type Kind int
+
+const (
+	// Missing means that the file has no header.
+	Missing Kind = iota
+	// Incomplete means that the file ends before the header does.
+	Incomplete
+	// Mismatch means that the header differs from the boilerplate.
+	Mismatch
+	// Unreadable means that the file could not be read.
+	Unreadable
+	// Misplaced means that content precedes the header.
+	Misplaced
+	// Outdated means that the copyright year of the header is not
+	// the current year.
+	Outdated
+	// Duplicate means that the header is followed by a second one.
+	Duplicate
+	// Forbidden means that the header matches a forbidden boilerplate.
+	Forbidden
+	// Uncommented means that a line of the header is not in a comment.
+	Uncommented
+	// Unseparated means that the header is not followed by a blank line.
+	Unseparated
+	// Misattributed means that the copyright line of the header names
+	// another holder than the one required.
+	Misattributed
+	// Interposed means that something other than blank lines comes
+	// between the header of a Go file and its package clause.
+	Interposed
+	// Unmarked means that a file that must be marked as generated is
+	// not.
+	Unmarked
+	// Remnant means that license text follows the header, before the
+	// code.
+	Remnant
+	// Overlong means that the comment of the header goes on past the
+	// boilerplate.
+	Overlong
+	// Misdated means that a copyright year of the header is outside
+	// the range of years allowed.
+	Misdated
+)
+
+var kindNames = []string{"missing", "incomplete", "mismatch", "unreadable", "misplaced", "outdated", "duplicate", "forbidden", "uncommented", "unseparated", "misattributed", "interposed", "unmarked", "remnant", "overlong", "misdated"}
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// KindNames returns the names of the kinds, in order.
+func KindNames() []string {
+	return append([]string(nil), kindNames...)
+}
+
+// ParseKind returns the kind with the given name, or false if there is
+// none.
+func ParseKind(name string) (Kind, bool) {
+	for i, n := range kindNames {
+		if n == name {
+			return Kind(i), true
+		}
+	}
+	return 0, false
+}
+
+// Severity is how serious a Violation is.
+type Severity int
+
+const (
+	// SeverityError means that the violation should fail the check.
+	SeverityError Severity = iota
+	// SeverityWarning means that the violation should be reported, but
+	// not fail the check.
+	SeverityWarning
+)
+
+var severityNames = []string{"error", "warning"}
+
+// String returns the name of the severity.
+func (s Severity) String() string {
+	if int(s) < len(severityNames) {
+		return severityNames[s]
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ParseSeverity returns the severity with the given name, or false if
+// there is none.
+func ParseSeverity(name string) (Severity, bool) {
+	for i, n := range severityNames {
+		if n == name {
+			return Severity(i), true
+		}
+	}
+	return 0, false
+}
+
+// Violation describes a way in which the header of a file does not
+// match the boilerplate.
+type Violation struct {
+	// Path is the path of the offending file.
+	Path string `json:"path"`
+	// Line is the line of the file at which the violation was found,
+	// or zero if it concerns the whole file.
+	Line int `json:"line"`
+	// Column is the column (counting runes from one) of the line at
+	// which the violation was found, or zero if it is not known.  Only
+	// Mismatch violations of a Checker made WithColumns have one.
+	Column int `json:"column,omitempty"`
+	// BoilerplateLine is the line of the boilerplate (counting from one)
+	// that the header first differs from, for Mismatch violations, or
+	// zero if it is not known, as for those of --anchor block-start and
+	// SPDX identifiers.
+	BoilerplateLine int `json:"boilerplateLine,omitempty"`
+	// Kind is the kind of violation.
+	Kind Kind `json:"kind"`
+	// Severity is how serious the violation is.  A Checker reports
+	// every violation as an error, and it is up to its caller to
+	// downgrade some, e.g. by their kind.
+	Severity Severity `json:"severity"`
+	// Detail is the expected boilerplate for Missing violations, the
+	// missing lines for Incomplete violations, and a diff of the
+	// expected and actual lines for Mismatch violations, the error for
+	// Unreadable violations, where the content and header are for
+	// Misplaced violations, the year found for Outdated violations,
+	// where the first header starts for Duplicate violations, the
+	// lines of the header for Forbidden violations, the line that is
+	// not in a comment for Uncommented violations, the line that
+	// follows the header for Unseparated violations, the holder found
+	// and expected for Misattributed violations, the line that
+	// precedes the package clause for Interposed violations, the
+	// marker that no line matches for Unmarked violations, the line
+	// of license text for Remnant violations, the first line past the
+	// boilerplate for Overlong violations, and the year found and the
+	// range expected for Misdated violations.
+	Detail string `json:"detail"`
+	// Found is the first lines of the file, numbered, that were searched
+	// for the header of Missing and Incomplete violations, if the Checker
+	// was made WithFoundLines.
+	Found string `json:"found,omitempty"`
+	// Fix is the edit that would correct the violation, or nil if it
+	// cannot be corrected automatically.
+	Fix *Edit `json:"-"`
+}
+
+// Message describes the violation, followed by the lines found instead
+// of the header, if any.
+func (v Violation) Message() string {
+	msg := v.message()
+	if v.Found == "" {
+		return msg
+	}
+	if !strings.HasSuffix(msg, "\n") {
+		msg += "\n"
+	}
+	return msg + "found instead:\n" + v.Found
+}
+
+func (v Violation) message() string {
+	switch v.Kind {
+	case Missing:
+		return "missing boilerplate:\n" + v.Detail
+	case Incomplete:
+		return "incomplete boilerplate, missing:\n" + v.Detail
+	case Mismatch:
+		return "found mismatched boilerplate lines:\n" + v.Detail
+	case Unreadable:
+		return "could not read: " + v.Detail
+	case Misplaced:
+		return "boilerplate is not at the top of the file: " + v.Detail
+	case Outdated:
+		return "copyright year is out of date: " + v.Detail
+	case Duplicate:
+		return "duplicate boilerplate: " + v.Detail
+	case Forbidden:
+		return "found forbidden boilerplate: " + v.Detail
+	case Uncommented:
+		return "boilerplate is not in a comment: " + v.Detail
+	case Misattributed:
+		return "wrong copyright holder: " + v.Detail
+	case Unseparated:
+		return "no blank line after the boilerplate, before: " + v.Detail
+	case Interposed:
+		return "found between the boilerplate and the package clause: " + v.Detail
+	case Unmarked:
+		return "missing generated code marker: " + v.Detail
+	case Remnant:
+		return "license text after the boilerplate: " + v.Detail
+	case Overlong:
+		return "header continues past the boilerplate: " + v.Detail
+	case Misdated:
+		return "copyright year is out of range: " + v.Detail
+	default:
+		return v.Detail
+	}
+}
+
+// Location formats where the violation was found as "path:line", or
+// "path:line:column" if the column is known, or "path" if it concerns
+// the whole file.
+func (v Violation) Location() string {
+	switch {
+	case v.Line == 0:
+		return v.Path
+	case v.Column == 0:
+		return fmt.Sprintf("%s:%d", v.Path, v.Line)
+	default:
+		return fmt.Sprintf("%s:%d:%d", v.Path, v.Line, v.Column)
+	}
+}
+
+// String formats the violation as "path:line: message", or as
+// "path:line: warning: message" if it is a warning.
+func (v Violation) String() string {
+	if v.Severity == SeverityWarning {
+		return v.Location() + ": warning: " + v.Message()
+	}
+	return v.Location() + ": " + v.Message()
+}
+
+// Edit describes a change to the lines of a file: the lines in
+// [Start, End), counting from zero, are replaced with Lines.
+type Edit struct {
+	Start int
+	End   int
+	Lines []string
+}
+
+// Apply returns content with the edit applied.  A byte order mark at
+// the beginning of content stays at the beginning.
+func (e *Edit) Apply(content string) string {
+	bom := strings.HasPrefix(content, utf8BOM)
+	content = strings.TrimPrefix(content, utf8BOM)
+
+	existing := strings.SplitAfter(content, "\n")
+	if existing[len(existing)-1] == "" {
+		existing = existing[:len(existing)-1]
+	}
+	start, end := e.Start, e.End
+	if start > len(existing) {
+		start = len(existing)
+	}
+	if end > len(existing) {
+		end = len(existing)
+	}
+	// Make sure the line we edit after is terminated.
+	if start > 0 && !strings.HasSuffix(existing[start-1], "\n") {
+		existing[start-1] += "\n"
+	}
+
+	var sb strings.Builder
+	if bom {
+		sb.WriteString(utf8BOM)
+	}
+	for _, l := range existing[:start] {
+		sb.WriteString(l)
+	}
+	for _, l := range e.Lines {
+		sb.WriteString(l + "\n")
+	}
+	for _, l := range existing[end:] {
+		sb.WriteString(l)
+	}
+	return sb.String()
+}
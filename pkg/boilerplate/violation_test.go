@@ -0,0 +1,161 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"testing"
+)
+
+func TestViolationString(t *testing.T) {
+	tests := []struct {
+		v    Violation
+		want string
+	}{{
+		v:    Violation{Path: "foo/bar.go", Line: 3, Kind: Missing, Detail: "/*\n*/\n"},
+		want: "foo/bar.go:3: missing boilerplate:\n/*\n*/\n",
+	}, {
+		v:    Violation{Path: "foo/bar.go", Line: 1, Kind: Missing, Detail: "/*\n*/", Found: "1 | package bar\n"},
+		want: "foo/bar.go:1: missing boilerplate:\n/*\n*/\nfound instead:\n1 | package bar\n",
+	}, {
+		v:    Violation{Path: "foo/bar.go", Line: 1, Kind: Incomplete, Detail: "*/\n"},
+		want: "foo/bar.go:1: incomplete boilerplate, missing:\n*/\n",
+	}, {
+		v:    Violation{Path: "foo/bar.go", Line: 2, Kind: Mismatch, Detail: "diff\n"},
+		want: "foo/bar.go:2: found mismatched boilerplate lines:\ndiff\n",
+	}, {
+		v:    Violation{Path: "foo/bar.go", Line: 2, Column: 5, Kind: Mismatch, Detail: "diff\n"},
+		want: "foo/bar.go:2:5: found mismatched boilerplate lines:\ndiff\n",
+	}, {
+		v:    Violation{Path: "foo/bar.go", Kind: Unreadable, Detail: "permission denied"},
+		want: "foo/bar.go: could not read: permission denied",
+	}, {
+		v:    Violation{Path: "foo/bar.go", Line: 1, Kind: Misplaced, Detail: "line 1 precedes the boilerplate at line 2"},
+		want: "foo/bar.go:1: boilerplate is not at the top of the file: line 1 precedes the boilerplate at line 2",
+	}, {
+		v:    Violation{Path: "foo/bar.go", Line: 5, Kind: Duplicate, Detail: "the boilerplate already starts at line 1"},
+		want: "foo/bar.go:5: duplicate boilerplate: the boilerplate already starts at line 1",
+	}, {
+		v:    Violation{Path: "foo/bar.go", Line: 1, Kind: Forbidden, Detail: "lines 1 through 4"},
+		want: "foo/bar.go:1: found forbidden boilerplate: lines 1 through 4",
+	}, {
+		v:    Violation{Path: "foo/bar.go", Line: 2, Kind: Uncommented, Detail: "Copyright 2020 Matt Moore"},
+		want: "foo/bar.go:2: boilerplate is not in a comment: Copyright 2020 Matt Moore",
+	}, {
+		v:    Violation{Path: "foo/bar.go", Line: 4, Kind: Unseparated, Detail: "package bar"},
+		want: "foo/bar.go:4: no blank line after the boilerplate, before: package bar",
+	}, {
+		v:    Violation{Path: "foo/bar.go", Line: 2, Kind: Misattributed, Detail: `found "Matt Moore", expected "Acme Inc"`},
+		want: `foo/bar.go:2: wrong copyright holder: found "Matt Moore", expected "Acme Inc"`,
+	}, {
+		v:    Violation{Path: "foo/bar.go", Line: 5, Kind: Interposed, Detail: "// +build e2e"},
+		want: "foo/bar.go:5: found between the boilerplate and the package clause: // +build e2e",
+	}, {
+		v:    Violation{Path: "foo/bar.go", Line: 1, Kind: Unmarked, Detail: "no line matches ^// Code generated"},
+		want: "foo/bar.go:1: missing generated code marker: no line matches ^// Code generated",
+	}, {
+		v:    Violation{Path: "foo/bar.go", Line: 5, Kind: Remnant, Detail: "limitations under the License."},
+		want: "foo/bar.go:5: license text after the boilerplate: limitations under the License.",
+	}, {
+		v:    Violation{Path: "foo/bar.go", Line: 2, Kind: Overlong, Detail: "// This file builds widgets."},
+		want: "foo/bar.go:2: header continues past the boilerplate: // This file builds widgets.",
+	}, {
+		v:    Violation{Path: "foo/bar.go", Line: 2, Kind: Misdated, Detail: "found 0202, expected 2015-2025"},
+		want: "foo/bar.go:2: copyright year is out of range: found 0202, expected 2015-2025",
+	}, {
+		v:    Violation{Path: "foo/bar.go", Line: 2, Kind: Outdated, Severity: SeverityWarning, Detail: "2019"},
+		want: "foo/bar.go:2: warning: copyright year is out of date: 2019",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.v.Kind.String(), func(t *testing.T) {
+			if got := test.v.String(); got != test.want {
+				t.Errorf("String() = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseKind(t *testing.T) {
+	for _, name := range KindNames() {
+		k, ok := ParseKind(name)
+		if !ok || k.String() != name {
+			t.Errorf("ParseKind(%q) = %v, %v, wanted %s", name, k, ok, name)
+		}
+	}
+	if k, ok := ParseKind("year"); ok {
+		t.Errorf("ParseKind(year) = %v, wanted not found", k)
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for _, s := range []Severity{SeverityError, SeverityWarning} {
+		got, ok := ParseSeverity(s.String())
+		if !ok || got != s {
+			t.Errorf("ParseSeverity(%q) = %v, %v, wanted %v", s.String(), got, ok, s)
+		}
+	}
+	if s, ok := ParseSeverity("fatal"); ok {
+		t.Errorf("ParseSeverity(fatal) = %v, wanted not found", s)
+	}
+}
+
+func TestEditApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    Edit
+		content string
+		want    string
+	}{{
+		name:    "insert at the top",
+		edit:    Edit{Start: 0, End: 0, Lines: []string{"// header", ""}},
+		content: "package foo\n",
+		want:    "// header\n\npackage foo\n",
+	}, {
+		name:    "insert after a prologue",
+		edit:    Edit{Start: 1, End: 1, Lines: []string{"// header"}},
+		content: "#!/bin/bash\necho hi\n",
+		want:    "#!/bin/bash\n// header\necho hi\n",
+	}, {
+		name:    "append to an unterminated file",
+		edit:    Edit{Start: 2, End: 2, Lines: []string{"c"}},
+		content: "a\nb",
+		want:    "a\nb\nc\n",
+	}, {
+		name:    "insert into an empty file",
+		edit:    Edit{Start: 0, End: 0, Lines: []string{"a"}},
+		content: "",
+		want:    "a\n",
+	}, {
+		name:    "replace lines",
+		edit:    Edit{Start: 1, End: 3, Lines: []string{"x"}},
+		content: "a\nb\nc\nd\n",
+		want:    "a\nx\nd\n",
+	}, {
+		name:    "keep the byte order mark first",
+		edit:    Edit{Start: 0, End: 0, Lines: []string{"// header"}},
+		content: utf8BOM + "package foo\n",
+		want:    utf8BOM + "// header\npackage foo\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.edit.Apply(test.content); got != test.want {
+				t.Errorf("Apply() = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
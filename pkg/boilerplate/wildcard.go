@@ -0,0 +1,59 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import "strings"
+
+// WildcardLine is a line of a boilerplate that matches any single line
+// of a header, such as one naming the file a header was generated from,
+// so that the lines around it are still checked.  Whitespace around it
+// is ignored.
+const WildcardLine = "{{*}}"
+
+func isWildcard(line string) bool {
+	return strings.TrimSpace(line) == WildcardLine
+}
+
+// matches returns whether the ith line of the boilerplate matches line,
+// which is normalized.
+func (c *Checker) matches(i int, line string) bool {
+	return c.wildcards[i] || line == c.lines[i] || (i == 0 && c.opens(line))
+}
+
+// mask replaces the lines of a header that wildcard lines of the
+// boilerplate (or its openers) match with the lines of the boilerplate,
+// so that they compare equal, and returns lines.
+func (c *Checker) mask(lines []string) []string {
+	for i := range lines {
+		if i < len(c.wildcards) && c.matches(i, lines[i]) {
+			lines[i] = c.lines[i]
+		}
+	}
+	return lines
+}
+
+// insert returns the edit that inserts the given lines of the boilerplate
+// before line i, or nil if they include a wildcard line, since there is
+// no knowing what should replace it.
+func (c *Checker) insert(i int, lines []string) *Edit {
+	for _, line := range lines {
+		if isWildcard(line) {
+			return nil
+		}
+	}
+	return &Edit{Start: i, End: i, Lines: c.denormalizeAll(lines)}
+}
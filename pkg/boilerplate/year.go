@@ -0,0 +1,167 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// matchYears matches a year, or a range of years like 2019-2020.
+	matchYears = regexp.MustCompile("[0-9][0-9][0-9][0-9](-[0-9][0-9][0-9][0-9])?")
+	// matchYearList matches a year, or a list of them like 2019, 2020
+	// (including ranges, whose years are separated by dashes).
+	matchYearList = regexp.MustCompile(`[0-9]{4}(?:(?:\s*[,-]\s*|\s+)[0-9]{4})*`)
+	// matchNormalizedYearList matches the normalized form of a list of
+	// years at the start of a line.
+	matchNormalizedYearList = regexp.MustCompile(`^YYYY(?:(?:\s*[,-]\s*|\s+)YYYY)*`)
+)
+
+// WithCurrentYear requires the copyright year of a header that otherwise
+// matches to be the current year, or a range of years ending in it.
+func WithCurrentYear() Option {
+	return func(c *Checker) {
+		c.requireCurrentYear = true
+	}
+}
+
+// WithYearRange requires each copyright year of a header that otherwise
+// matches, including both ends of a range of years, to be from min to
+// max inclusive.  A max of zero is the current year, as of each check.
+func WithYearRange(min, max int) Option {
+	return func(c *Checker) {
+		c.minYear, c.maxYear = min, max
+	}
+}
+
+// WithYearLists lets a list of years, like 2019, 2020, 2021, or a mix
+// of years and ranges, match a single year of the boilerplate.  Years
+// separated by commas, dashes or spaces are taken to form a list.
+func WithYearLists() Option {
+	return func(c *Checker) {
+		c.yearLists = true
+	}
+}
+
+// collapseYears replaces each list of normalized years in line by a
+// single YYYY.
+func collapseYears(line string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(line, "YYYY")
+		if i < 0 {
+			b.WriteString(line)
+			return b.String()
+		}
+		n := len(matchNormalizedYearList.FindString(line[i:]))
+		b.WriteString(line[:i])
+		b.WriteString("YYYY")
+		line = line[i+n:]
+	}
+}
+
+// checkYears returns a violation for each line of the header starting at
+// start (given as raw) with years, where the boilerplate has them, that
+// are not the current year.  Lines whose years are all in the past are
+// fixed by ending them in the current year, e.g. 2019 becomes 2019-2020,
+// and 2018-2019 becomes 2018-2020.
+func (c *Checker) checkYears(path string, start int, raw []string) []Violation {
+	now := fmt.Sprint(time.Now().Year())
+
+	var violations []Violation
+	// Lines are lowercased to compare them if we ignore case, but not
+	// those we look for years in.
+	for i, want := range c.cased {
+		if !strings.Contains(want, "YYYY") {
+			continue
+		}
+		line := raw[i]
+		var found string
+		future := false
+		pattern := matchYears
+		if c.yearLists {
+			pattern = matchYearList
+		}
+		updated := pattern.ReplaceAllStringFunc(line, func(years string) string {
+			// For a range or list, only the year it ends in matters.
+			end := years[len(years)-4:]
+			if end == now {
+				return years
+			}
+			if found == "" {
+				found = years
+			}
+			if end > now {
+				future = true
+				return years
+			}
+			return years[:4] + "-" + now
+		})
+		if found == "" {
+			continue
+		}
+		v := Violation{
+			Path:   path,
+			Line:   start + 1 + i,
+			Kind:   Outdated,
+			Detail: fmt.Sprintf("found %s, expected %s", found, now),
+		}
+		if !future {
+			v.Fix = &Edit{Start: start + i, End: start + i + 1, Lines: []string{updated}}
+		}
+		violations = append(violations, v)
+	}
+	return violations
+}
+
+// checkYearRange returns the lines of the header starting at start, whose
+// raw lines are given, with a year, where the boilerplate has them,
+// outside of the range of years allowed.  Only the first such year of
+// each line is reported.
+func (c *Checker) checkYearRange(path string, start int, raw []string) []Violation {
+	max := c.maxYear
+	if max == 0 {
+		max = time.Now().Year()
+	}
+
+	var violations []Violation
+	// As in checkYears, the year lines are found in their own case.
+	for i, want := range c.cased {
+		if !strings.Contains(want, "YYYY") {
+			continue
+		}
+		for _, year := range matchYear.FindAllString(raw[i], -1) {
+			// Years are four digits, so this cannot fail.
+			n, _ := strconv.Atoi(year)
+			if n >= c.minYear && n <= max {
+				continue
+			}
+			violations = append(violations, Violation{
+				Path:   path,
+				Line:   start + 1 + i,
+				Kind:   Misdated,
+				Detail: fmt.Sprintf("found %s, expected %d-%d", year, c.minYear, max),
+			})
+			break
+		}
+	}
+	return violations
+}
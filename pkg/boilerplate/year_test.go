@@ -0,0 +1,281 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package boilerplate
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheckYears(t *testing.T) {
+	now := time.Now().Year()
+	tests := []struct {
+		name    string
+		opts    []Option
+		content string
+		want    []Violation
+	}{{
+		name:    "current year",
+		content: fmt.Sprintf("/*\nCopyright %d Matt Moore\n*/\n\npackage foo\n", now),
+	}, {
+		name:    "range ending in the current year",
+		content: fmt.Sprintf("/*\nCopyright 2018-%d Matt Moore\n*/\n\npackage foo\n", now),
+	}, {
+		name:    "old year",
+		content: "/*\nCopyright 2018 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Kind:   Outdated,
+			Detail: fmt.Sprintf("found 2018, expected %d", now),
+			Fix: &Edit{
+				Start: 1,
+				End:   2,
+				Lines: []string{fmt.Sprintf("Copyright 2018-%d Matt Moore", now)},
+			},
+		}},
+	}, {
+		name:    "old range",
+		content: "/*\nCopyright 2016-2018 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Kind:   Outdated,
+			Detail: fmt.Sprintf("found 2016-2018, expected %d", now),
+			Fix: &Edit{
+				Start: 1,
+				End:   2,
+				Lines: []string{fmt.Sprintf("Copyright 2016-%d Matt Moore", now)},
+			},
+		}},
+	}, {
+		name:    "list of years ending in the current year",
+		opts:    []Option{WithYearLists()},
+		content: fmt.Sprintf("/*\nCopyright 2018, 2019, %d Matt Moore\n*/\n\npackage foo\n", now),
+	}, {
+		name:    "old list of years",
+		opts:    []Option{WithYearLists()},
+		content: "/*\nCopyright 2016-2017, 2019 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Kind:   Outdated,
+			Detail: fmt.Sprintf("found 2016-2017, 2019, expected %d", now),
+			Fix: &Edit{
+				Start: 1,
+				End:   2,
+				Lines: []string{fmt.Sprintf("Copyright 2016-%d Matt Moore", now)},
+			},
+		}},
+	}, {
+		name:    "old year with case ignored",
+		opts:    []Option{WithoutCaseSensitivity()},
+		content: "/*\nCOPYRIGHT 2018 MATT MOORE\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Kind:   Outdated,
+			Detail: fmt.Sprintf("found 2018, expected %d", now),
+			Fix: &Edit{
+				Start: 1,
+				End:   2,
+				Lines: []string{fmt.Sprintf("COPYRIGHT 2018-%d MATT MOORE", now)},
+			},
+		}},
+	}, {
+		name:    "future year",
+		content: "/*\nCopyright 9999 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Kind:   Outdated,
+			Detail: fmt.Sprintf("found 9999, expected %d", now),
+		}},
+	}, {
+		name:    "old year in a mismatched header",
+		content: "/*\nCopyright 2018 Matt More\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path: "foo.go",
+			Line: 2,
+			Kind: Mismatch,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"Copyright YYYY Matt More", "*/", ""})),
+		}},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewChecker(testBoilerplate, []string{"go"}, nil, append(test.opts, WithCurrentYear())...)
+			got, err := c.Check("foo.go", strings.NewReader(test.content))
+			if err != nil {
+				t.Fatalf("Check() = %v", err)
+			}
+			got = withoutBoilerplateLines(got)
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("Check() (-want, +got): %s", cmp.Diff(test.want, got))
+			}
+		})
+	}
+}
+
+func TestCheckYearRange(t *testing.T) {
+	now := time.Now().Year()
+	tests := []struct {
+		name     string
+		min, max int
+		opts     []Option
+		content  string
+		want     []Violation
+	}{{
+		name:    "year in range",
+		min:     2015,
+		max:     2025,
+		content: "/*\nCopyright 2019 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:    "range in range",
+		min:     2015,
+		max:     2025,
+		content: "/*\nCopyright 2015-2025 Matt Moore\n*/\n\npackage foo\n",
+	}, {
+		name:    "typo",
+		min:     2015,
+		max:     2025,
+		content: "/*\nCopyright 0202 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Kind:   Misdated,
+			Detail: "found 0202, expected 2015-2025",
+		}},
+	}, {
+		name:    "typo with case ignored",
+		min:     2015,
+		max:     2025,
+		opts:    []Option{WithoutCaseSensitivity()},
+		content: "/*\nCOPYRIGHT 0202 MATT MOORE\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Kind:   Misdated,
+			Detail: "found 0202, expected 2015-2025",
+		}},
+	}, {
+		name:    "end of range out of range",
+		min:     2015,
+		max:     2025,
+		content: "/*\nCopyright 2019-2026 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Kind:   Misdated,
+			Detail: "found 2026, expected 2015-2025",
+		}},
+	}, {
+		name:    "start of range out of range",
+		min:     2015,
+		max:     2025,
+		content: "/*\nCopyright 2014-2016 Matt Moore\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Kind:   Misdated,
+			Detail: "found 2014, expected 2015-2025",
+		}},
+	}, {
+		name:    "current year",
+		min:     2015,
+		content: fmt.Sprintf("/*\nCopyright %d Matt Moore\n*/\n\npackage foo\n", now),
+	}, {
+		name:    "future year",
+		min:     2015,
+		content: fmt.Sprintf("/*\nCopyright %d Matt Moore\n*/\n\npackage foo\n", now+1),
+		want: []Violation{{
+			Path:   "foo.go",
+			Line:   2,
+			Kind:   Misdated,
+			Detail: fmt.Sprintf("found %d, expected 2015-%d", now+1, now),
+		}},
+	}, {
+		name:    "year in a mismatched header",
+		min:     2015,
+		max:     2025,
+		content: "/*\nCopyright 0202 Matt More\n*/\n\npackage foo\n",
+		want: []Violation{{
+			Path:            "foo.go",
+			Line:            2,
+			Kind:            Mismatch,
+			BoilerplateLine: 2,
+			Detail: Denormalize(cmp.Diff(
+				[]string{"Copyright YYYY Matt Moore", "*/", ""},
+				[]string{"Copyright YYYY Matt More", "*/", ""})),
+		}},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := NewChecker(testBoilerplate, []string{"go"}, nil, append(test.opts, WithYearRange(test.min, test.max))...)
+			got, err := c.Check("foo.go", strings.NewReader(test.content))
+			if err != nil {
+				t.Fatalf("Check() = %v", err)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("Check() (-want, +got): %s", cmp.Diff(test.want, got))
+			}
+		})
+	}
+}
+
+func TestCollapseYears(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{{
+		line: "Copyright YYYY Matt Moore",
+		want: "Copyright YYYY Matt Moore",
+	}, {
+		line: "Copyright YYYY, YYYY, YYYY Matt Moore",
+		want: "Copyright YYYY Matt Moore",
+	}, {
+		line: "Copyright YYYY,YYYY Matt Moore",
+		want: "Copyright YYYY Matt Moore",
+	}, {
+		line: "Copyright YYYY-YYYY, YYYY Matt Moore",
+		want: "Copyright YYYY Matt Moore",
+	}, {
+		line: "Copyright YYYY - YYYY YYYY Matt Moore",
+		want: "Copyright YYYY Matt Moore",
+	}, {
+		line: "Copyright YYYY Matt Moore, YYYY Someone Else",
+		want: "Copyright YYYY Matt Moore, YYYY Someone Else",
+	}, {
+		line: "Copyright YYYY, Matt Moore",
+		want: "Copyright YYYY, Matt Moore",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			if got := collapseYears(test.line); got != test.want {
+				t.Errorf("collapseYears() = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseAlias parses an --alias of the form OLD=NEW, returning the old and
+// new text.  Since the new text would itself be rewritten, it may not
+// contain the old.
+func parseAlias(value string) (string, string, error) {
+	i := strings.Index(value, "=")
+	if i <= 0 {
+		return "", "", fmt.Errorf("--alias %q must be of the form OLD=NEW", value)
+	}
+	old, new := value[:i], value[i+1:]
+	if strings.Contains(new, old) {
+		return "", "", fmt.Errorf("--alias %q may not contain OLD in NEW", value)
+	}
+	return old, new, nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import "testing"
+
+func TestParseAlias(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantOld string
+		wantNew string
+		wantErr string
+	}{{
+		value:   "Matt Moore=Acme Inc",
+		wantOld: "Matt Moore",
+		wantNew: "Acme Inc",
+	}, {
+		value:   "Copyright YYYY Matt Moore. All rights reserved.=Copyright YYYY Acme Inc",
+		wantOld: "Copyright YYYY Matt Moore. All rights reserved.",
+		wantNew: "Copyright YYYY Acme Inc",
+	}, {
+		value:   "a=b=c",
+		wantOld: "a",
+		wantNew: "b=c",
+	}, {
+		value:   "Inc.=",
+		wantOld: "Inc.",
+	}, {
+		value:   "Matt Moore",
+		wantErr: `--alias "Matt Moore" must be of the form OLD=NEW`,
+	}, {
+		value:   "=Acme Inc",
+		wantErr: `--alias "=Acme Inc" must be of the form OLD=NEW`,
+	}, {
+		value:   "Acme=Acme Inc",
+		wantErr: `--alias "Acme=Acme Inc" may not contain OLD in NEW`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			old, new, err := parseAlias(test.value)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Errorf("parseAlias() = %v, wanted %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseAlias() = %v", err)
+			}
+			if old != test.wantOld || new != test.wantNew {
+				t.Errorf("parseAlias() = %q, %q, wanted %q, %q", old, new, test.wantOld, test.wantNew)
+			}
+		})
+	}
+}
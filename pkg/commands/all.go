@@ -23,4 +23,13 @@ import (
 func AddAll(cmd *cobra.Command) {
 	cmd.AddCommand(NewVersionCommand())
 	cmd.AddCommand(NewCheckCommand())
+	cmd.AddCommand(NewCheckArchiveCommand())
+	cmd.AddCommand(NewCheckFileCommand())
+	cmd.AddCommand(NewCheckAllCommand())
+	cmd.AddCommand(NewManifestCommand())
+	cmd.AddCommand(NewExtractCommand())
+	cmd.AddCommand(NewExplainCommand())
+	cmd.AddCommand(NewSurveyCommand())
+	cmd.AddCommand(NewHookCommand())
+	cmd.AddCommand(NewCompletionCommand())
 }
@@ -0,0 +1,207 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+	"github.com/spf13/cobra"
+)
+
+// archiveIncompatible are the check flags that concern files on disk,
+// which check-archive does not support.
+var archiveIncompatible = []string{"root", "files-from", "files-from0", "follow-symlinks", "max-depth", "check-symlink-targets", "fix", "decompress", "watch", "watch-interval", "cache", "find-root", "root-marker", "since", "concurrency", "sniff-shebang", "interpreter"}
+
+// NewCheckArchiveCommand implements the `check-archive` sub-command
+func NewCheckArchiveCommand() *cobra.Command {
+	co := &checkOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "check-archive ARCHIVE",
+		Short: "Checks that the headers of the files in a tar or zip archive match boilerplate files.",
+		Example: `  boilerplate-check check-archive release.tar.gz \
+    --boilerplate ./hack/boilerplate/boilerplate.go.txt --file-extension go`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: co.archivePreRunE,
+		RunE:    co.archiveRunE,
+	}
+	co.AddFlags(cmd)
+	cmd.SetOut(os.Stdout)
+
+	return cmd
+}
+
+func (co *checkOptions) archivePreRunE(cmd *cobra.Command, args []string) error {
+	if err := rejectFlags(cmd, archiveIncompatible); err != nil {
+		return err
+	}
+	if _, err := archiveFormat(args[0]); err != nil {
+		return err
+	}
+	return co.PreRunE(cmd, args)
+}
+
+// rejectFlags returns an error if any of the named check flags, which
+// cmd does not support, were passed.
+func rejectFlags(cmd *cobra.Command, names []string) error {
+	for _, name := range names {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s may not be used with %s", name, cmd.Name())
+		}
+	}
+	return nil
+}
+
+func (co *checkOptions) archiveRunE(cmd *cobra.Command, args []string) error {
+	return withIssuesExitCode(co.run(cmd, func() error {
+		return co.checkArchive(cmd, args[0])
+	}), co.IssuesExitCode)
+}
+
+// archiveFormat returns the format of the archive named name, which is
+// determined by its extension.
+func archiveFormat(name string) (string, error) {
+	switch {
+	case strings.HasSuffix(name, ".tar"):
+		return "tar", nil
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return "tgz", nil
+	case strings.HasSuffix(name, ".zip"):
+		return "zip", nil
+	default:
+		return "", fmt.Errorf("archive %q must end in one of: .tar, .tar.gz, .tgz, .zip", name)
+	}
+}
+
+// checkArchive checks the entries of the archive at name, which are
+// reported by their paths within it.
+func (co *checkOptions) checkArchive(cmd *cobra.Command, name string) error {
+	format, err := archiveFormat(name)
+	if err != nil {
+		return err
+	}
+	if format == "zip" {
+		return co.checkZip(cmd, name)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("error reading archive %q: %v", name, err)
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if format == "tgz" {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("error reading archive %q: %v", name, err)
+		}
+		r = zr
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading archive %q: %v", name, err)
+		}
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA {
+			co.log.logf(debugLevel, hdr.Name, "skipped: not a regular file")
+			continue
+		}
+		if err := co.checkEntry(cmd, hdr.Name, hdr.Size, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// checkZip checks the entries of the zip archive at name.
+func (co *checkOptions) checkZip(cmd *cobra.Command, name string) error {
+	zr, err := zip.OpenReader(name)
+	if err != nil {
+		return fmt.Errorf("error reading archive %q: %v", name, err)
+	}
+	defer zr.Close()
+	for _, zf := range zr.File {
+		if !zf.Mode().IsRegular() {
+			co.log.logf(debugLevel, zf.Name, "skipped: not a regular file")
+			continue
+		}
+		r, err := zf.Open()
+		if err != nil {
+			if err := co.record(zf.Name, co.failure(boilerplate.Unreadable), co.unreadable(zf.Name, err)); err != nil {
+				return err
+			}
+			continue
+		}
+		err = co.checkEntry(cmd, zf.Name, int64(zf.UncompressedSize64), r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkEntry checks the archive entry reported by path, of size bytes,
+// whose content r reads, if it matches our filters.
+func (co *checkOptions) checkEntry(cmd *cobra.Command, path string, size int64, r io.Reader) error {
+	if co.ctx.Err() != nil {
+		return errTimedOut
+	}
+	if pattern := co.inExcludedDir(path); pattern != "" {
+		co.log.logf(debugLevel, path, "skipped: exclude-dir %s", pattern)
+		return nil
+	}
+	if reason := co.filter.SkipReason(path); reason != "" {
+		co.log.logf(debugLevel, path, "skipped: %s", reason)
+		return nil
+	}
+	co.matched++
+	if co.MaxFileSize > 0 && size > co.MaxFileSize {
+		co.log.logf(warnLevel, path, "skipped: %d bytes is larger than --max-file-size %d",
+			size, co.MaxFileSize)
+		return nil
+	}
+	// Entries are read only once, so hold onto the content for each
+	// of the checkers to read it.
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return co.record(path, co.failure(boilerplate.Unreadable), co.unreadable(path, err))
+	}
+	co.log.logf(debugLevel, path, "checked")
+
+	open := func(string) (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(content)), nil
+	}
+	checkers, err := co.forFile(path, co.checkers)
+	if err != nil {
+		return err
+	}
+	result, err := co.check(cmd, checkers, open, path, path, nil)
+	return co.record(path, result, err)
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// archiveEntries are the entries of the test archives, by name.
+var archiveEntries = []string{
+	"src/old.good.mm",
+	"src/typo.bad.mm",
+	"src/notes.txt",
+}
+
+// writeArchive writes archiveEntries, with the content of the testdata
+// files of the same base name, to an archive of the given format.
+func writeArchive(t *testing.T, name string) {
+	t.Helper()
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	defer f.Close()
+
+	var (
+		add    func(name string, content []byte) error
+		finish func() error
+	)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		zw := zip.NewWriter(f)
+		add = func(name string, content []byte) error {
+			w, err := zw.Create(name)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(content)
+			return err
+		}
+		finish = zw.Close
+	default:
+		var w io.Writer = f
+		var gw *gzip.Writer
+		if strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz") {
+			gw = gzip.NewWriter(f)
+			w = gw
+		}
+		tw := tar.NewWriter(w)
+		add = func(name string, content []byte) error {
+			if err := tw.WriteHeader(&tar.Header{
+				Name:     name,
+				Mode:     0644,
+				Size:     int64(len(content)),
+				Typeflag: tar.TypeReg,
+			}); err != nil {
+				return err
+			}
+			_, err := tw.Write(content)
+			return err
+		}
+		finish = func() error {
+			if err := tw.Close(); err != nil {
+				return err
+			}
+			if gw != nil {
+				return gw.Close()
+			}
+			return nil
+		}
+	}
+
+	for _, entry := range archiveEntries {
+		content, err := ioutil.ReadFile(filepath.Join("testdata", filepath.Base(entry)))
+		if os.IsNotExist(err) {
+			content = []byte("not checked\n")
+		} else if err != nil {
+			t.Fatalf("ReadFile() = %v", err)
+		}
+		if err := add(entry, content); err != nil {
+			t.Fatalf("add(%q) = %v", entry, err)
+		}
+	}
+	if err := finish(); err != nil {
+		t.Fatalf("finish() = %v", err)
+	}
+}
+
+func TestCheckArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+		code int
+	}{{
+		name: "release.tar",
+		want: "src/typo.bad.mm\n",
+		code: ExitViolations,
+	}, {
+		name: "release.tar.gz",
+		want: "src/typo.bad.mm\n",
+		code: ExitViolations,
+	}, {
+		name: "release.zip",
+		want: "src/typo.bad.mm\n",
+		code: ExitViolations,
+	}, {
+		name: "release.zip",
+		args: []string{"--exclude", "typo"},
+		want: "",
+	}}
+
+	for _, test := range tests {
+		t.Run(strings.Join(append([]string{test.name}, test.args...), " "), func(t *testing.T) {
+			name := filepath.Join(dir, test.name)
+			writeArchive(t, name)
+
+			cmd := NewCheckArchiveCommand()
+			stdout := new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs(append([]string{
+				name,
+				"--boilerplate", "testdata/boilerplate.mm.txt",
+				"--file-extension", "mm",
+				"--print-files", "failing",
+			}, test.args...))
+
+			if err := cmd.Execute(); err != nil {
+				t.Errorf("Execute() = %v", err)
+			}
+			if got := stdout.String(); got != test.want {
+				t.Errorf("stdout = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckArchiveViolations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "release.tgz")
+	writeArchive(t, name)
+
+	cmd := NewCheckArchiveCommand()
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		name,
+		"--boilerplate", "testdata/boilerplate.mm.txt",
+		"--file-extension", "mm",
+	})
+
+	if err := cmd.Execute(); ExitCode(err) != ExitViolations {
+		t.Errorf("Execute() = %v, wanted exit code %d", err, ExitViolations)
+	}
+	// Violations are reported by the paths within the archive.
+	if got, want := stdout.String(), "src/typo.bad.mm:"; !strings.HasPrefix(got, want) {
+		t.Errorf("stdout = %q, wanted prefix %q", got, want)
+	}
+}
+
+func TestCheckArchivePreRunE(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{{
+		name:    "unknown format",
+		args:    []string{"release.rar"},
+		wantErr: `archive "release.rar" must end in one of: .tar, .tar.gz, .tgz, .zip`,
+	}, {
+		name:    "with --fix",
+		args:    []string{"release.tar", "--fix"},
+		wantErr: "--fix may not be used with check-archive",
+	}, {
+		name:    "with --root",
+		args:    []string{"release.tar", "--root", "testdata"},
+		wantErr: "--root may not be used with check-archive",
+	}, {
+		name:    "with --decompress",
+		args:    []string{"release.tar", "--decompress", "gzip"},
+		wantErr: "--decompress may not be used with check-archive",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewCheckArchiveCommand()
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs(append(test.args,
+				"--boilerplate", "testdata/boilerplate.mm.txt",
+				"--file-extension", "mm"))
+
+			err := cmd.Execute()
+			if err == nil || err.Error() != test.wantErr {
+				t.Errorf("Execute() = %v, wanted %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckArchiveMissing(t *testing.T) {
+	cmd := NewCheckArchiveCommand()
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"testdata/missing.tar",
+		"--boilerplate", "testdata/boilerplate.mm.txt",
+		"--file-extension", "mm",
+	})
+
+	if err := cmd.Execute(); err == nil || !strings.HasPrefix(err.Error(), `error reading archive "testdata/missing.tar"`) {
+		t.Errorf("Execute() = %v, wanted an error reading the archive", err)
+	}
+}
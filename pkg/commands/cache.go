@@ -0,0 +1,130 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+)
+
+// cache records the files that passed, for --cache, so that they are
+// not read again while they are unchanged.
+type cache struct {
+	// Files holds the state of each file that passed when it did, by
+	// the path that it was read from.
+	Files map[string]cacheEntry `json:"files"`
+}
+
+// cacheEntry is the state of a file that passed.
+type cacheEntry struct {
+	// Key identifies the configuration that the file passed, since it
+	// may not pass another.
+	Key     string `json:"key"`
+	ModTime int64  `json:"modTime"`
+	Size    int64  `json:"size"`
+}
+
+// loadCache reads the cache at name, or returns an empty one if there is
+// none yet.  Since the cache is only an optimization, a cache that cannot
+// be parsed is discarded with a warning.
+func loadCache(log *logger, name string) (*cache, error) {
+	c := &cache{Files: make(map[string]cacheEntry)}
+	bts, err := ioutil.ReadFile(name)
+	switch {
+	case os.IsNotExist(err):
+		return c, nil
+	case err != nil:
+		return nil, fmt.Errorf("error reading --cache %q: %v", name, err)
+	}
+	if err := json.Unmarshal(bts, c); err != nil || c.Files == nil {
+		log.logf(warnLevel, "", "discarding --cache %q, which could not be parsed: %v", name, err)
+		c.Files = make(map[string]cacheEntry)
+	}
+	return c, nil
+}
+
+// save writes the cache to name.
+func (c *cache) save(name string) error {
+	bts, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(name, bts, 0644); err != nil {
+		return fmt.Errorf("error writing --cache %q: %v", name, err)
+	}
+	return nil
+}
+
+// passed returns whether file, whose state is info, passed with the
+// configuration identified by key, and is unchanged since.
+func (c *cache) passed(file string, info os.FileInfo, key string) bool {
+	if c == nil {
+		return false
+	}
+	entry, ok := c.Files[file]
+	return ok && entry == cacheEntry{Key: key, ModTime: info.ModTime().UnixNano(), Size: info.Size()}
+}
+
+// update records whether file, whose state is info, passed with the
+// configuration identified by key.
+func (c *cache) update(file string, info os.FileInfo, key string, passed bool) {
+	if c == nil {
+		return
+	}
+	if !passed {
+		delete(c.Files, file)
+		return
+	}
+	c.Files[file] = cacheEntry{Key: key, ModTime: info.ModTime().UnixNano(), Size: info.Size()}
+}
+
+// configKey identifies the configuration of co, for --cache: its flags,
+// except those that only choose which files are checked, the lines of
+// the boilerplates required (including those of --boilerplate-dir) and
+// forbidden, the files allowed to lack boilerplate, and the current
+// year, which some flags compare headers with.
+func (co *checkOptions) configKey(variants, forbids [][]string, byExtension map[string][][]string) (string, error) {
+	flags := *co
+	flags.Cache, flags.Roots, flags.FilesFrom, flags.FilesFrom0 = "", nil, "", ""
+	flags.Watch, flags.WatchInterval = false, 0
+	bts, err := json.Marshal(flags)
+	if err != nil {
+		return "", err
+	}
+	allowed := make([]string, 0, len(co.allowedMissing))
+	for path := range co.allowedMissing {
+		allowed = append(allowed, path)
+	}
+	sort.Strings(allowed)
+
+	h := sha256.New()
+	h.Write(bts)
+	fmt.Fprintf(h, "\nboilerplate %q\nforbidden %q\nallowed %q\nyear %d\n",
+		variants, forbids, allowed, time.Now().Year())
+	if len(byExtension) > 0 {
+		// Maps are formatted in the order of their keys.
+		fmt.Fprintf(h, "by extension %q\n", byExtension)
+	}
+	// Half of the hash is plenty to tell configurations apart.
+	return hex.EncodeToString(h.Sum(nil)[:sha256.Size/2]), nil
+}
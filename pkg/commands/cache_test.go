@@ -0,0 +1,112 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	good, err := ioutil.ReadFile("testdata/old.good.mm")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	bad, err := ioutil.ReadFile("testdata/typo.bad.mm")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	writeFiles(t, dir, map[string]string{
+		"src/a.mm": string(good),
+		"src/b.mm": string(good),
+		"src/c.mm": string(bad),
+	})
+	root, name := filepath.Join(dir, "src"), filepath.Join(dir, "cache.json")
+
+	steps := []struct {
+		name   string
+		before func()
+		args   []string
+		cached []string
+	}{{
+		name: "first run",
+	}, {
+		name:   "unchanged",
+		cached: []string{"a.mm", "b.mm"},
+	}, {
+		name: "changed",
+		before: func() {
+			writeFiles(t, dir, map[string]string{"src/b.mm": string(good) + "more\n"})
+		},
+		cached: []string{"a.mm"},
+	}, {
+		name: "different flags",
+		args: []string{"--ignore-case"},
+	}, {
+		name: "corrupt",
+		before: func() {
+			writeFiles(t, dir, map[string]string{"cache.json": "{"})
+		},
+	}, {
+		name:   "after corrupt",
+		cached: []string{"a.mm", "b.mm"},
+	}}
+
+	for _, step := range steps {
+		if step.before != nil {
+			step.before()
+		}
+		cmd := NewCheckCommand()
+		stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+		cmd.SetOut(stdout)
+		cmd.SetErr(stderr)
+		cmd.SetArgs(append([]string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--root", root,
+			"--cache", name,
+			"--log-level", "debug",
+		}, step.args...))
+
+		if got := ExitCode(cmd.Execute()); got != ExitViolations {
+			t.Errorf("%s: ExitCode() = %d, wanted %d", step.name, got, ExitViolations)
+		}
+		// The failing file is reported, whatever the cache holds.
+		if want := "c.mm:2: found mismatched boilerplate lines:"; !strings.Contains(stdout.String(), want) {
+			t.Errorf("%s: stdout = %s\nwanted it to contain %q", step.name, stdout, want)
+		}
+		for _, path := range []string{"a.mm", "b.mm", "c.mm"} {
+			want := false
+			for _, cached := range step.cached {
+				want = want || cached == path
+			}
+			line := path + ": passed, and unchanged since, per --cache\n"
+			if got := strings.Contains(stderr.String(), line); got != want {
+				t.Errorf("%s: %s cached = %v, wanted %v", step.name, path, got, want)
+			}
+		}
+	}
+}
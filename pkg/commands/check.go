@@ -18,24 +18,114 @@ package commands
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
-	"github.com/google/go-cmp/cmp"
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
 	"github.com/spf13/cobra"
 )
 
 var (
-	ErrBoilerplateRequired   = errors.New("--boilerplate is a required flag.")
-	ErrFileExtensionRequired = errors.New("--file-extension is a required flag.")
+	ErrBoilerplateRequired     = errors.New("--boilerplate (or --boilerplate-dir, --license, --spdx or --forbid) is a required flag.")
+	ErrSPDXWithBoilerplate     = errors.New("--spdx may not be used with --boilerplate.")
+	ErrBoilerplateDirWithSPDX  = errors.New("--boilerplate-dir may not be used with --spdx.")
+	ErrLicenseWithBoilerplate  = errors.New("--license may not be used with --boilerplate, --boilerplate-dir or --spdx.")
+	ErrTemplateWithLicense     = errors.New("--boilerplate-template may not be used with --license.")
+	ErrBoilerplateConflict     = errors.New("--boilerplate and --boilerplate-literal may not be used together.")
+	ErrCopyrightRequiresSPDX   = errors.New("--copyright-pattern may only be used with --spdx.")
+	ErrFileExtensionRequired   = errors.New("--file-extension (or --file-pattern) is a required flag.")
+	ErrDryRunRequiresFix       = errors.New("--dry-run may only be used with --fix.")
+	ErrUpdateYearRequiresFix   = errors.New("--update-year may only be used with --fix.")
+	ErrForceFixRequiresFix     = errors.New("--force-fix may only be used with --fix.")
+	ErrFilesFromConflict       = errors.New("--files-from and --files-from0 may not be used together.")
+	ErrFilesFromWithRoot       = errors.New("--root may not be used with --files-from or --files-from0.")
+	ErrMaxDepthWithFilesFrom   = errors.New("--max-depth may not be used with --files-from or --files-from0.")
+	ErrMatchAnywhereWindow     = errors.New("--max-header-lines and --max-header-bytes may not be used with --match-anywhere.")
+	ErrHeaderWindowConflict    = errors.New("--max-header-lines and --max-header-bytes may not be used together.")
+	ErrDuplicateWithSPDX       = errors.New("--forbid-duplicate-header may not be used with --spdx.")
+	ErrRemnantsWithSPDX        = errors.New("--forbid-license-remnants may not be used with --spdx.")
+	ErrRequireCommentWithSPDX  = errors.New("--require-comment may not be used with --spdx.")
+	ErrTrailingBlankWithSPDX   = errors.New("--require-trailing-blank may not be used with --spdx.")
+	ErrExactLengthWithSPDX     = errors.New("--require-exact-length may not be used with --spdx.")
+	ErrHolderWithSPDX          = errors.New("--require-holder may not be used with --spdx.")
+	ErrYearRangeWithSPDX       = errors.New("--year-range may not be used with --spdx.")
+	ErrGoPackageWithSPDX       = errors.New("--go-package-follows may not be used with --spdx.")
+	ErrOpeningLineWithSPDX     = errors.New("--opening-line may not be used with --spdx.")
+	ErrGeneratedWithSPDX       = errors.New("--require-generated-marker may not be used with --spdx.")
+	ErrMarkerRequiresGenerated = errors.New("--generated-marker may only be used with --require-generated-marker.")
+	ErrNoNormalizeYearConflict = errors.New("--no-normalize may not be used with --collapse-year-lists, --require-current-year, --update-year or --year-range.")
+	ErrStyleRequiresComment    = errors.New("--comment-style may only be used with --require-comment or --license.")
+	ErrInterpreterWithoutSniff = errors.New("--interpreter may only be used with --sniff-shebang.")
+	ErrCountWithFormat         = errors.New("--count and --count-files may not be used with --format.")
+	ErrCountWithFix            = errors.New("--count and --count-files may not be used with --fix.")
+	ErrPrintFilesWithFormat    = errors.New("--print-files may not be used with --format.")
+	ErrPrintFilesWithFix       = errors.New("--print-files may not be used with --fix.")
+	ErrPrintFilesWithCount     = errors.New("--print-files may not be used with --count or --count-files.")
+	ErrStatsWithFormat         = errors.New("--stats may not be used with --format.")
+	ErrStatsWithFix            = errors.New("--stats may not be used with --fix.")
+	ErrStatsWithCount          = errors.New("--stats may not be used with --count, --count-files or --print-files.")
+	ErrStatsWithDiffContext    = errors.New("--stats may not be used with --show-diff-context or --anchor block-start.")
+	ErrByExtensionWithCount    = errors.New("--count-by-extension may not be used with --count, --count-files, --print-files or --stats.")
+	ErrGroupWithFormat         = errors.New("--group-by-file may only be used with --format text.")
+	ErrTemplateWithSPDX        = errors.New("--boilerplate-template may not be used with --spdx.")
+	ErrProjectRequiresTemplate = errors.New("--project may only be used with --boilerplate-template.")
+	ErrDecompressWithFix       = errors.New("--decompress may not be used with --fix.")
+	ErrWatchWithFilesFrom      = errors.New("--watch may not be used with --files-from or --files-from0.")
+	ErrWatchWithFix            = errors.New("--watch may not be used with --fix.")
+	ErrWatchWithTimeout        = errors.New("--watch may not be used with --timeout.")
+	ErrMarkerRequiresFindRoot  = errors.New("--root-marker may only be used with --find-root.")
+	ErrBlockAnchorConflict     = errors.New("--anchor block-start may not be used with --report-all-mismatches or --columns.")
+	ErrNoMatches               = errors.New("no files matched --file-extension or --file-pattern.")
 )
 
+// errFailFast stops the walk at the first file with violations, for
+// --fail-fast.  It is not reported.
+var errFailFast = errors.New("stopped at the first file with violations")
+
+// errTimedOut stops the run once --timeout has passed.  It is reported
+// along with the files checked until then.
+var errTimedOut = errors.New("timed out")
+
+// defaultMaxFileSize is the size of the largest file checked by default,
+// which keeps us from scanning huge binaries that happen to share an
+// extension with the files we check.
+const defaultMaxFileSize = 10 << 20
+
+// defaultMaxLineLength is the length of the longest line we read by
+// default, well beyond the 64KB that bufio.Scanner allows, so that the
+// long lines of minified files don't keep us from checking them.
+const defaultMaxLineLength = 1 << 20
+
+// verboseFoundLines is how many of the lines searched for a missing or
+// incomplete header --verbose shows, enough to see where it went wrong.
+const verboseFoundLines = 5
+
+// anchorModes are the --anchor values: the first line of a mismatched
+// header that differs, which survives reviewdog's filtering of lines
+// outside a change, or the line the header starts at.
+var anchorModes = []string{"first-diff", "block-start"}
+
+// leadingBlankPolicies are the --leading-blank-policy values: whether
+// blank lines at the top of a file may precede the header, or are
+// reported, and removed by --fix.
+var leadingBlankPolicies = []string{"keep", "remove"}
+
+// defaultWatchInterval is how long --watch waits by default for a file to
+// stop changing before it re-checks it, short enough to feel immediate.
+const defaultWatchInterval = 500 * time.Millisecond
+
 // NewCheckCommand implements the `check` sub-command
 func NewCheckCommand() *cobra.Command {
 	co := &checkOptions{}
@@ -53,151 +143,1609 @@ func NewCheckCommand() *cobra.Command {
 }
 
 type checkOptions struct {
-	BoilerplateFile string
-	FileExtension   string
-	ExcludePattern  string
+	BoilerplateFiles    []string
+	BoilerplateLiteral  string
+	BoilerplateDir      string
+	License             string
+	Template            bool
+	Project             string
+	SPDX                string
+	Forbid              []string
+	CopyrightRegexp     string
+	FileExtensions      []string
+	FilePatterns        []string
+	SniffShebang        bool
+	Interpreters        []string
+	ExcludePattern      string
+	ExcludeDirs         []string
+	AllowMissing        string
+	AllowMissingFrom    string
+	Roots               []string
+	FilesFrom           string
+	FilesFrom0          string
+	FollowSymlinks      bool
+	CheckSymlinkTargets bool
+	MaxDepth            int
+	FindRoot            bool
+	RootMarker          string
+	MaxFileSize         int64
+	Since               string
+	Decompress          string
+	Watch               bool
+	WatchInterval       time.Duration
+	Cache               string
+
+	MaxHeaderLines           int
+	MaxHeaderBytes           int
+	MaxLineLength            int
+	MatchAnywhere            bool
+	AllowLeadingLines        bool
+	SkipLeadingBlank         bool
+	LeadingBlankPolicy       string
+	RequireAtTop             bool
+	IgnoreTrailingWhitespace bool
+	IgnoreLeadingWhitespace  bool
+	IgnoreCase               bool
+	NormalizeUnicode         bool
+	Aliases                  []string
+	TabWidth                 int
+	CollapseBlankLines       bool
+	OpeningLines             []string
+	CollapseYearLists        bool
+	NoNormalize              bool
+	RequireCurrentYear       bool
+	YearRange                string
+	ReportAllMismatches      bool
+	ForbidDuplicateHeader    bool
+	ForbidRemnants           bool
+	RequireComment           bool
+	RequireTrailingBlank     bool
+	RequireExactLength       bool
+	GoPackageFollows         bool
+	RequireGeneratedMarker   string
+	GeneratedMarker          string
+	RequireHolder            string
+	CommentStyles            []string
+	Columns                  bool
+	Anchor                   string
+	DiffContext              int
+	FailOnError              bool
+	FailFast                 bool
+	Severities               []string
+	WarnOnly                 bool
+	IssuesExitCode           int
+	Timeout                  time.Duration
+	FailOnNoMatches          bool
+	Fix                      bool
+	DryRun                   bool
+	UpdateYear               bool
+	ForceFix                 bool
+	Format                   string
+	Color                    string
+	Quiet                    bool
+	NoSummary                bool
+	CountByExtension         bool
+	Progress                 bool
+	GroupByFile              bool
+	Verbose                  bool
+	LogFormat                string
+	LogLevel                 string
+	Count                    bool
+	CountFiles               bool
+	PrintFiles               string
+	Stats                    bool
+	Concurrency              int
+
+	log            *logger
+	checkers       []*boilerplate.Checker
+	forbidden      []*boilerplate.Checker
+	filter         *boilerplate.Checker
+	interpreters   map[string]string
+	byExtension    map[string][]*boilerplate.Checker
+	severities     map[boilerplate.Kind]boilerplate.Severity
+	newChecker     func(lines []string) *boilerplate.Checker
+	overrides      map[string][]*boilerplate.Checker
+	tops           map[string]bool
+	allowMissing   *regexp.Regexp
+	excludeDirs    []*regexp.Regexp
+	since          time.Time
+	allowedMissing map[string]bool
+	cache          *cache
+	cacheKey       string
+	formatter      formatter
+	progress       *progress
+	summary        summary
+	// queue holds the files being checked by --concurrency workers,
+	// in the order they are to be reported.
+	queue []*pending
+	// ctx is done once --timeout has passed, if it is set.
+	ctx context.Context
+	// matched counts the files that our filters matched, whether or
+	// not they could be checked.
+	matched int
+
+	// base, if any, is the repository root found by --find-root, which
+	// files are reported relative to.
+	base string
+
+	// prefix, if any, is prepended to the paths that files are
+	// reported by, for check-all.
+	prefix string
+}
 
-	boilerplateLines []string
-	exclude          *regexp.Regexp
+// summary tallies the results of a check run.
+type summary struct {
+	Checked    int `json:"checked"`
+	Passed     int `json:"passed"`
+	Failed     int `json:"failed"`
+	Violations int `json:"violations"`
+	Warnings   int `json:"warnings,omitempty"`
+	Fixed      int `json:"fixed,omitempty"`
+	// ByExtension tallies the violations by the extension (or, if it
+	// has none, the base name) of their files, for --count-by-extension.
+	ByExtension map[string]int `json:"byExtension,omitempty"`
+}
+
+// extensions returns the keys of ByExtension, those with the most
+// violations first.
+func (s summary) extensions() []string {
+	exts := make([]string, 0, len(s.ByExtension))
+	for ext := range s.ByExtension {
+		exts = append(exts, ext)
+	}
+	sort.Slice(exts, func(i, j int) bool {
+		a, b := exts[i], exts[j]
+		if s.ByExtension[a] != s.ByExtension[b] {
+			return s.ByExtension[a] > s.ByExtension[b]
+		}
+		return a < b
+	})
+	return exts
 }
 
 func (co *checkOptions) AddFlags(cmd *cobra.Command) {
-	cmd.Flags().StringVarP(&co.BoilerplateFile, "boilerplate", "", "",
-		"The path to the required boilerplate file.")
-	cmd.Flags().StringVarP(&co.FileExtension, "file-extension", "", "",
-		"The extension of files that should match this boilerplate.")
+	cmd.Flags().StringArrayVarP(&co.BoilerplateFiles, "boilerplate", "", nil,
+		"The path (or http(s) URL) of the required boilerplate file, may be repeated to accept any of several.")
+	cmd.Flags().StringVarP(&co.BoilerplateLiteral, "boilerplate-literal", "", "",
+		"The text of the required boilerplate, instead of --boilerplate.")
+	cmd.Flags().StringVarP(&co.BoilerplateDir, "boilerplate-dir", "", "",
+		"A directory of boilerplate.EXT.txt files, each the boilerplate of the files with that extension (or base name).")
+	cmd.Flags().StringVarP(&co.License, "license", "", "",
+		"The SPDX identifier of a license whose header is built in, one of: "+strings.Join(boilerplate.Licenses(), ", ")+", instead of a boilerplate file.")
+	cmd.Flags().BoolVarP(&co.Template, "boilerplate-template", "", false,
+		"Expand the boilerplate as a Go text/template, which may refer to {{.Year}}, {{.Project}}, and {{.Env.NAME}}.")
+	cmd.Flags().StringVarP(&co.Project, "project", "", "",
+		"The project name that a --boilerplate-template refers to as {{.Project}}, or the copyright holder of a --license.")
+	cmd.Flags().StringVarP(&co.SPDX, "spdx", "", "",
+		"A license that files must identify with an SPDX-License-Identifier line, instead of a boilerplate.")
+	cmd.Flags().StringArrayVarP(&co.Forbid, "forbid", "", nil,
+		"A file (or URL) of boilerplate that headers must not match, may be repeated.")
+	cmd.Flags().StringVarP(&co.CopyrightRegexp, "copyright-pattern", "", "",
+		"With --spdx, a pattern that a copyright line near the identifier must match.")
+	cmd.Flags().StringSliceVarP(&co.FileExtensions, "file-extension", "", nil,
+		"The extensions of files that should match this boilerplate, may be repeated.")
+	cmd.Flags().StringSliceVarP(&co.FilePatterns, "file-pattern", "", nil,
+		"A glob matching the base names of other files to check, e.g. Dockerfile, may be repeated.")
+	cmd.Flags().BoolVarP(&co.SniffShebang, "sniff-shebang", "", false,
+		"Check files without an extension whose shebang names the interpreter of a language with one of the extensions.")
+	cmd.Flags().StringArrayVarP(&co.Interpreters, "interpreter", "", nil,
+		"With --sniff-shebang, the extension of the language of an interpreter, as NAME=EXT, may be repeated.")
 	cmd.Flags().StringVarP(&co.ExcludePattern, "exclude", "", "",
 		"A pattern of files to exclude from consideration.")
+	cmd.Flags().StringArrayVarP(&co.ExcludeDirs, "exclude-dir", "", nil,
+		"A pattern matching the whole name or path of directories not to descend into, may be repeated.")
+	cmd.Flags().StringVarP(&co.AllowMissing, "allow-missing", "", "",
+		"A pattern of files that may lack boilerplate, but whose headers are checked if present.")
+	cmd.Flags().StringVarP(&co.AllowMissingFrom, "allow-missing-from", "", "",
+		"A file listing the paths of files that may lack boilerplate, one per line.")
+	cmd.Flags().StringArrayVarP(&co.Roots, "root", "", []string{"."},
+		"A directory to check the files under, may be repeated.")
+	cmd.Flags().BoolVarP(&co.FindRoot, "find-root", "", false,
+		"Interpret paths, and report files, relative to the repository root above the working directory, found by --root-marker.")
+	cmd.Flags().StringVarP(&co.RootMarker, "root-marker", "", defaultRootMarker,
+		"With --find-root, the file or directory that marks the repository root.")
+	cmd.Flags().StringVarP(&co.FilesFrom, "files-from", "", "",
+		"A file (or - for stdin) listing the paths to check, one per line.")
+	cmd.Flags().StringVarP(&co.FilesFrom0, "files-from0", "", "",
+		"A file (or - for stdin) listing the paths to check, separated by NUL.")
+	cmd.Flags().BoolVarP(&co.FollowSymlinks, "follow-symlinks", "", false,
+		"Descend into symlinks to directories, walking each directory at most once.")
+	cmd.Flags().BoolVarP(&co.CheckSymlinkTargets, "check-symlink-targets", "", false,
+		"Check symlinked files as if they were where the link is, warning of broken links instead of reporting them.")
+	cmd.Flags().IntVarP(&co.MaxDepth, "max-depth", "", 0,
+		"Descend at most this many directory levels below each --root, where 1 checks only the files directly within it (0 for no limit).")
+	cmd.Flags().Int64VarP(&co.MaxFileSize, "max-file-size", "", defaultMaxFileSize,
+		"The size in bytes of the largest file to check, larger ones are skipped (0 for no limit).")
+	cmd.Flags().StringVarP(&co.Since, "since", "", "",
+		"Only check files modified since this date, as YYYY-MM-DD (or an RFC 3339 time).")
+	cmd.Flags().IntVarP(&co.Concurrency, "concurrency", "", 1,
+		"How many files to check at once; files are still reported in the order they are walked (or listed, with --files-from).")
+	cmd.Flags().StringVarP(&co.Decompress, "decompress", "", "none",
+		"How to decompress files before checking them, one of: "+strings.Join(decompressModes, ", ")+".")
+	cmd.Flags().BoolVarP(&co.Watch, "watch", "", false,
+		"After checking, keep re-checking the files under --root as they change, until interrupted.")
+	cmd.Flags().DurationVarP(&co.WatchInterval, "watch-interval", "", defaultWatchInterval,
+		"How long a file must be unchanged before --watch re-checks it, so that a burst of saves is checked once.")
+	cmd.Flags().StringVarP(&co.Cache, "cache", "", "",
+		"A file recording the files that passed, so that they are not read again until they change or the flags do.")
+	cmd.Flags().IntVarP(&co.MaxHeaderLines, "max-header-lines", "", 10,
+		"The number of lines, after any leading lines, to search for the start of the boilerplate.")
+	cmd.Flags().IntVarP(&co.MaxHeaderBytes, "max-header-bytes", "", 0,
+		"The number of bytes to search for the start of the boilerplate, instead of --max-header-lines.")
+	cmd.Flags().IntVarP(&co.MaxLineLength, "max-line-length", "", defaultMaxLineLength,
+		"The length in bytes of the longest line that may be read, longer ones make a file unreadable.")
+	cmd.Flags().BoolVarP(&co.MatchAnywhere, "match-anywhere", "", false,
+		"Search the whole file for the boilerplate, stopping at the first complete match.")
+	cmd.Flags().BoolVarP(&co.AllowLeadingLines, "allow-leading-lines", "", false,
+		"Permit shebang, build tag, and blank lines to precede the boilerplate.")
+	cmd.Flags().BoolVarP(&co.SkipLeadingBlank, "skip-leading-blank", "", false,
+		"Skip blank lines at the top of files without counting them against --max-header-lines.")
+	cmd.Flags().StringVarP(&co.LeadingBlankPolicy, "leading-blank-policy", "", "keep",
+		"What to do with blank lines at the top of files that precede the boilerplate, one of: "+strings.Join(leadingBlankPolicies, ", ")+", which reports them and lets --fix remove them.")
+	cmd.Flags().BoolVarP(&co.RequireAtTop, "require-at-top", "", false,
+		"Fail files where anything but blank (or allowed leading) lines precede the boilerplate.")
+	cmd.Flags().BoolVarP(&co.IgnoreTrailingWhitespace, "ignore-trailing-whitespace", "", false,
+		"Ignore spaces and tabs at the end of lines when comparing them with the boilerplate.")
+	cmd.Flags().BoolVarP(&co.IgnoreLeadingWhitespace, "ignore-leading-whitespace", "", false,
+		"Ignore spaces and tabs at the beginning of lines when comparing them with the boilerplate.")
+	cmd.Flags().BoolVarP(&co.IgnoreCase, "ignore-case", "", false,
+		"Ignore differences in case when comparing lines with the boilerplate.")
+	cmd.Flags().BoolVarP(&co.NormalizeUnicode, "normalize-unicode", "", false,
+		"Let curly quotes, en and em dashes, and non-breaking spaces match their ASCII equivalents.")
+	cmd.Flags().StringArrayVarP(&co.Aliases, "alias", "", nil,
+		"Substitute NEW for OLD in headers before comparing them with the boilerplate, as OLD=NEW, may be repeated and applies in order.")
+	cmd.Flags().IntVarP(&co.TabWidth, "tab-width", "", 0,
+		"Treat each tab in the indentation of a line as this many spaces (0 to match tabs only with tabs).")
+	cmd.Flags().StringArrayVarP(&co.OpeningLines, "opening-line", "", nil,
+		"A regular expression of a whole line that may open a header instead of the first line of the boilerplate, e.g. one with an editor modeline, may be repeated.")
+	cmd.Flags().BoolVarP(&co.CollapseBlankLines, "collapse-blank-lines", "", false,
+		"Let the blank lines ending the boilerplate match any number of blank lines.")
+	cmd.Flags().BoolVarP(&co.CollapseYearLists, "collapse-year-lists", "", false,
+		"Let a list of years, like 2019, 2020, 2021, match a single year of the boilerplate.")
+	cmd.Flags().BoolVarP(&co.NoNormalize, "no-normalize", "", false,
+		"Compare the years of headers with the boilerplate as they are, instead of letting any year match any other.")
+	cmd.Flags().BoolVarP(&co.RequireCurrentYear, "require-current-year", "", false,
+		"Fail headers whose copyright year is not the current year (or a range ending in it).")
+	cmd.Flags().StringVarP(&co.YearRange, "year-range", "", "",
+		"Fail headers with a copyright year outside of MIN-MAX inclusive, or MIN- to end in the current year, e.g. 2015-2025.")
+	cmd.Flags().BoolVarP(&co.ReportAllMismatches, "report-all-mismatches", "", false,
+		"Report each line of a header that differs from the boilerplate, instead of only the first.")
+	cmd.Flags().BoolVarP(&co.ForbidDuplicateHeader, "forbid-duplicate-header", "", false,
+		"Report a second boilerplate starting shortly after the first, as a bad merge might leave.")
+	cmd.Flags().BoolVarP(&co.ForbidRemnants, "forbid-license-remnants", "", false,
+		"Report lines of the boilerplate's license text repeated after the header, before the code.")
+	cmd.Flags().BoolVarP(&co.RequireComment, "require-comment", "", false,
+		"Fail headers that are not within comments, for the languages with a known comment style.")
+	cmd.Flags().BoolVarP(&co.RequireTrailingBlank, "require-trailing-blank", "", false,
+		"Fail headers followed directly by anything but a blank line, e.g. code.")
+	cmd.Flags().BoolVarP(&co.RequireExactLength, "require-exact-length", "", false,
+		"Fail headers whose comment goes on past the boilerplate, instead of treating the boilerplate as a prefix.")
+	cmd.Flags().BoolVarP(&co.GoPackageFollows, "go-package-follows", "", false,
+		"Fail Go files with anything but blank lines between the header and the package clause.")
+	cmd.Flags().StringVarP(&co.RequireGeneratedMarker, "require-generated-marker", "", "",
+		"A regular expression of the paths of generated files, whose headers must also have a line that --generated-marker matches.")
+	cmd.Flags().StringVarP(&co.GeneratedMarker, "generated-marker", "", boilerplate.DefaultGeneratedMarker.String(),
+		"With --require-generated-marker, a regular expression of the line that marks a file as generated.")
+	cmd.Flags().StringVarP(&co.RequireHolder, "require-holder", "", "",
+		"Fail headers whose copyright line names another holder than this one, e.g. one matched by an --alias.")
+	cmd.Flags().StringArrayVarP(&co.CommentStyles, "comment-style", "", nil,
+		"With --require-comment or --license, the comment style of files with an extension (or name), as EXT=LINE[,START,END], may be repeated.")
+	cmd.Flags().BoolVarP(&co.Columns, "columns", "", false,
+		"Report the column at which mismatched lines first differ, as path:line:column.")
+	cmd.Flags().StringVarP(&co.Anchor, "anchor", "", "first-diff",
+		"The line that mismatched headers are reported at, one of: "+strings.Join(anchorModes, ", ")+".")
+	cmd.Flags().IntVarP(&co.DiffContext, "show-diff-context", "", -1,
+		"Show mismatched lines as a unified diff with this many lines of context (-1 for the rest of the header).")
+	cmd.Flags().BoolVarP(&co.FailOnError, "fail-on-error", "", false,
+		"Abort on the first file that cannot be read instead of reporting it.")
+	cmd.Flags().BoolVarP(&co.FailFast, "fail-fast", "", false,
+		"Stop at the first file with violations, instead of checking every file.")
+	cmd.Flags().StringSliceVarP(&co.Severities, "severity", "", nil,
+		"The severity of a kind of violation, as KIND=LEVEL where LEVEL is error or warning, may be repeated. Warnings do not fail the check.")
+	cmd.Flags().BoolVarP(&co.WarnOnly, "warn-only", "", false,
+		"Report every violation as a warning, which does not fail the check, e.g. for a grace period.")
+	cmd.Flags().IntVarP(&co.IssuesExitCode, "issues-exit-code", "", ExitViolations,
+		"The exit status when violations are found, like golangci-lint's.")
+	cmd.Flags().DurationVarP(&co.Timeout, "timeout", "", 0,
+		"Stop checking after this long, reporting the files checked until then, and exit with status 3 (0 for no limit).")
+	cmd.Flags().BoolVarP(&co.FailOnNoMatches, "fail-on-no-matches", "", false,
+		"Fail if no files match --file-extension or --file-pattern, which is likely a mistake.")
+	cmd.Flags().BoolVarP(&co.Fix, "fix", "", false,
+		"Insert missing boilerplate into files instead of only reporting it.")
+	cmd.Flags().BoolVarP(&co.DryRun, "dry-run", "", false,
+		"With --fix, print the changes as a unified diff instead of writing them.")
+	cmd.Flags().BoolVarP(&co.UpdateYear, "update-year", "", false,
+		"With --fix, end the copyright years of headers that otherwise match in the current year.")
+	cmd.Flags().BoolVarP(&co.ForceFix, "force-fix", "", false,
+		"With --fix, replace headers that do not match the boilerplate with it, discarding what they say.")
+	cmd.Flags().StringVarP(&co.Format, "format", "", "text",
+		"The output format, one of: "+strings.Join(formatNames(), ", ")+", which defaults to github in GitHub Actions.")
+	cmd.Flags().StringVarP(&co.Color, "color", "", "auto",
+		"Whether to colorize text output, one of: "+strings.Join(colorModes, ", ")+".")
+	cmd.Flags().BoolVarP(&co.Quiet, "quiet", "", false,
+		"Do not print the details of each violation.")
+	cmd.Flags().BoolVarP(&co.NoSummary, "no-summary", "", false,
+		"Do not print a summary of the results.")
+	cmd.Flags().BoolVarP(&co.CountByExtension, "count-by-extension", "", false,
+		"Break the violations of the summary down by the extension of their files.")
+	cmd.Flags().BoolVarP(&co.Progress, "progress", "", false,
+		"Show the files checked and violations found so far on stderr, when run in a terminal.")
+	cmd.Flags().BoolVarP(&co.GroupByFile, "group-by-file", "", false,
+		"Print the violations once the run is complete, under the path of each file, instead of as they are found.")
+	cmd.Flags().BoolVarP(&co.Verbose, "verbose", "", false,
+		"Log each file considered, and why it was skipped, to stderr, like --log-level debug, and show the lines found instead of missing boilerplate.")
+	cmd.Flags().StringVarP(&co.LogFormat, "log-format", "", "text",
+		"The format of messages logged to stderr, one of: "+strings.Join(logFormats, ", ")+".")
+	cmd.Flags().StringVarP(&co.LogLevel, "log-level", "", "warn",
+		"The least important messages to log to stderr, one of: "+strings.Join(logLevels, ", ")+".")
+	cmd.Flags().BoolVarP(&co.Count, "count", "", false,
+		"Print only the number of violations, and exit zero regardless.")
+	cmd.Flags().BoolVarP(&co.CountFiles, "count-files", "", false,
+		"Print only the number of files with violations, and exit zero regardless.")
+	cmd.Flags().StringVarP(&co.PrintFiles, "print-files", "", "",
+		"Print only the paths of the files that are "+strings.Join(printFilesModes, " or ")+", and exit zero regardless.")
+	cmd.Flags().BoolVarP(&co.Stats, "stats", "", false,
+		"Print only which lines of the boilerplate differ in the most files, ranked, and exit zero regardless.")
+
+	completeValues(cmd, "format", formatNames())
+	completeValues(cmd, "color", colorModes)
+	completeValues(cmd, "decompress", decompressModes)
+	completeValues(cmd, "anchor", anchorModes)
+	completeValues(cmd, "license", boilerplate.Licenses())
+	completeValues(cmd, "leading-blank-policy", leadingBlankPolicies)
+	completeValues(cmd, "print-files", printFilesModes)
+	completeValues(cmd, "log-format", logFormats)
+	completeValues(cmd, "log-level", logLevels)
 }
 
 func (co *checkOptions) PreRunE(cmd *cobra.Command, args []string) error {
-	if co.BoilerplateFile == "" {
+	// Set up logging first, to log any problems with the other flags.
+	co.log = &logger{out: cmd.ErrOrStderr()}
+	switch co.LogFormat {
+	case "text":
+	case "json":
+		co.log.json = true
+	default:
+		return fmt.Errorf("--log-format %q must be one of: %s", co.LogFormat, strings.Join(logFormats, ", "))
+	}
+	level := -1
+	for i, name := range logLevels {
+		if co.LogLevel == name {
+			level = i
+		}
+	}
+	if level < 0 {
+		return fmt.Errorf("--log-level %q must be one of: %s", co.LogLevel, strings.Join(logLevels, ", "))
+	}
+	co.log.level = logLevel(level)
+	if co.Verbose {
+		co.log.level = debugLevel
+	}
+
+	if err := co.applyEnv(cmd); err != nil {
+		return err
+	}
+
+	if cmd.Flags().Changed("root-marker") && !co.FindRoot {
+		return ErrMarkerRequiresFindRoot
+	}
+	co.base = ""
+	if co.FindRoot {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		if err := co.findRoot(wd); err != nil {
+			return err
+		}
+	}
+
+	var variants [][]string
+	var byExtension map[string][][]string
+	hasBoilerplate := len(co.BoilerplateFiles) > 0 || co.BoilerplateLiteral != ""
+	switch {
+	case co.BoilerplateDir != "" && co.SPDX != "":
+		return ErrBoilerplateDirWithSPDX
+	case co.License != "" && (hasBoilerplate || co.BoilerplateDir != "" || co.SPDX != ""):
+		return ErrLicenseWithBoilerplate
+	case co.License != "" && co.Template:
+		return ErrTemplateWithLicense
+	case co.License != "":
+		var err error
+		byExtension, err = co.licenseBoilerplates()
+		if err != nil {
+			return err
+		}
+	case len(co.BoilerplateFiles) > 0 && co.BoilerplateLiteral != "":
+		return ErrBoilerplateConflict
+	case co.SPDX != "" && hasBoilerplate:
+		return ErrSPDXWithBoilerplate
+	case co.SPDX != "" && co.Template:
+		return ErrTemplateWithSPDX
+	case co.SPDX != "":
+	case !hasBoilerplate && co.BoilerplateDir == "" && len(co.Forbid) == 0:
 		return ErrBoilerplateRequired
+	default:
+		if co.Project != "" && !co.Template {
+			return ErrProjectRequiresTemplate
+		}
+		var err error
+		variants, err = co.readBoilerplates()
+		if err != nil {
+			return err
+		}
+		if co.BoilerplateDir != "" {
+			byExtension, err = co.readBoilerplateDir()
+			if err != nil {
+				return err
+			}
+		}
 	}
-	bts, err := ioutil.ReadFile(co.BoilerplateFile)
+	forbids, err := co.readForbidden()
 	if err != nil {
-		return fmt.Errorf("error reading --boilerplate file %q: %v", co.BoilerplateFile, err)
-	}
-	if string(bts) == "" {
-		return fmt.Errorf("--boilerplate file %q is empty", co.BoilerplateFile)
+		return err
 	}
-	raw := strings.Split(string(bts), "\n")
-	co.boilerplateLines = make([]string, 0, len(raw))
-	for _, rl := range raw {
-		co.boilerplateLines = append(co.boilerplateLines, normalize(rl))
+
+	var copyright *regexp.Regexp
+	if co.CopyrightRegexp != "" {
+		if co.SPDX == "" {
+			return ErrCopyrightRequiresSPDX
+		}
+		var err error
+		copyright, err = regexp.Compile(co.CopyrightRegexp)
+		if err != nil {
+			return fmt.Errorf("error compiling --copyright-pattern %q: %v", co.CopyrightRegexp, err)
+		}
 	}
 
-	if co.FileExtension == "" {
+	names := make([]string, 0, len(byExtension))
+	for name := range byExtension {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(co.FileExtensions) == 0 && len(co.FilePatterns) == 0 {
+		// Check the files that --boilerplate-dir has a boilerplate for.
+		co.FileExtensions = names
+	}
+	if len(co.FileExtensions) == 0 && len(co.FilePatterns) == 0 {
 		return ErrFileExtensionRequired
 	}
-	if strings.Contains(co.FileExtension, ".") {
-		return fmt.Errorf("--file-extension %q may not contain '.'", co.FileExtension)
+	if byExtension != nil && len(variants) == 0 {
+		for _, ext := range co.FileExtensions {
+			if _, ok := byExtension[ext]; ok {
+				continue
+			}
+			if co.License != "" {
+				return fmt.Errorf("--file-extension %q has no comment style for --license, which --comment-style may give", ext)
+			}
+			return fmt.Errorf("--file-extension %q has no boilerplate in --boilerplate-dir %q, and there is no --boilerplate", ext, co.BoilerplateDir)
+		}
+	}
+	for _, ext := range co.FileExtensions {
+		if strings.Contains(ext, ".") {
+			return fmt.Errorf("--file-extension %q may not contain '.'", ext)
+		}
+	}
+	for _, pattern := range co.FilePatterns {
+		if strings.Contains(pattern, "/") {
+			return fmt.Errorf("--file-pattern %q may not contain '/', since it matches base names", pattern)
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("error parsing --file-pattern %q: %v", pattern, err)
+		}
 	}
-	// filepath.Ext returns the leading "."
-	co.FileExtension = "." + co.FileExtension
 
+	var excludes []*regexp.Regexp
 	if co.ExcludePattern != "" {
-		co.exclude, err = regexp.Compile(co.ExcludePattern)
+		exclude, err := regexp.Compile(co.ExcludePattern)
 		if err != nil {
 			return fmt.Errorf("error compiling --exclude pattern %q: %v", co.ExcludePattern, err)
 		}
+		excludes = append(excludes, exclude)
+	}
+	if co.excludeDirs, err = compileExcludeDirs(co.ExcludeDirs); err != nil {
+		return err
+	}
+
+	co.allowMissing, co.allowedMissing = nil, nil
+	if co.AllowMissing != "" {
+		var err error
+		co.allowMissing, err = regexp.Compile(co.AllowMissing)
+		if err != nil {
+			return fmt.Errorf("error compiling --allow-missing pattern %q: %v", co.AllowMissing, err)
+		}
+	}
+	if co.AllowMissingFrom != "" {
+		bts, err := ioutil.ReadFile(co.AllowMissingFrom)
+		if err != nil {
+			return fmt.Errorf("error reading --allow-missing-from file %q: %v", co.AllowMissingFrom, err)
+		}
+		co.allowedMissing = make(map[string]bool)
+		for _, line := range strings.Split(string(bts), "\n") {
+			// Skip blank lines and comments.
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			co.allowedMissing[filepath.Clean(line)] = true
+		}
+	}
+
+	for _, root := range co.Roots {
+		info, err := os.Stat(root)
+		if err != nil {
+			return fmt.Errorf("error reading --root %q: %v", root, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("--root %q is not a directory", root)
+		}
+	}
+
+	if co.FilesFrom != "" && co.FilesFrom0 != "" {
+		return ErrFilesFromConflict
+	}
+	if (co.FilesFrom != "" || co.FilesFrom0 != "") && cmd.Flags().Changed("root") {
+		return ErrFilesFromWithRoot
+	}
+	if co.MaxDepth < 0 {
+		return fmt.Errorf("--max-depth %d may not be negative", co.MaxDepth)
+	}
+	if (co.FilesFrom != "" || co.FilesFrom0 != "") && co.MaxDepth > 0 {
+		return ErrMaxDepthWithFilesFrom
+	}
+
+	if co.Watch {
+		switch {
+		case co.FilesFrom != "" || co.FilesFrom0 != "":
+			return ErrWatchWithFilesFrom
+		case co.Fix:
+			return ErrWatchWithFix
+		case co.WatchInterval <= 0:
+			return fmt.Errorf("--watch-interval %v must be positive", co.WatchInterval)
+		case co.Timeout > 0:
+			return ErrWatchWithTimeout
+		}
+	}
+	if err := validateIssuesExitCode(co.IssuesExitCode); err != nil {
+		return err
+	}
+	if co.Timeout < 0 {
+		return fmt.Errorf("--timeout %v may not be negative", co.Timeout)
+	}
+
+	co.since = time.Time{}
+	if co.Since != "" {
+		var err error
+		co.since, err = parseSince(co.Since)
+		if err != nil {
+			return err
+		}
+	}
+	if co.MaxFileSize < 0 {
+		return fmt.Errorf("--max-file-size %d may not be negative", co.MaxFileSize)
+	}
+	switch co.Decompress {
+	case "none":
+	case "gzip":
+		if co.Fix {
+			return ErrDecompressWithFix
+		}
+	default:
+		return fmt.Errorf("--decompress %q must be one of: %s", co.Decompress, strings.Join(decompressModes, ", "))
+	}
+	if co.MaxHeaderLines < 1 {
+		return fmt.Errorf("--max-header-lines %d must be positive", co.MaxHeaderLines)
+	}
+	switch co.Anchor {
+	case "first-diff":
+	case "block-start":
+		if co.ReportAllMismatches || co.Columns {
+			return ErrBlockAnchorConflict
+		}
+	default:
+		return fmt.Errorf("--anchor %q must be one of: %s", co.Anchor, strings.Join(anchorModes, ", "))
+	}
+	switch co.LeadingBlankPolicy {
+	case "keep", "remove":
+	default:
+		return fmt.Errorf("--leading-blank-policy %q must be one of: %s", co.LeadingBlankPolicy, strings.Join(leadingBlankPolicies, ", "))
+	}
+	if co.DiffContext < -1 {
+		return fmt.Errorf("--show-diff-context %d may not be less than -1", co.DiffContext)
+	}
+	if co.TabWidth < 0 {
+		return fmt.Errorf("--tab-width %d may not be negative", co.TabWidth)
+	}
+	if co.Concurrency < 1 {
+		return fmt.Errorf("--concurrency %d must be positive", co.Concurrency)
+	}
+	if co.MaxLineLength < 1 {
+		return fmt.Errorf("--max-line-length %d must be positive", co.MaxLineLength)
+	}
+	if co.MaxHeaderBytes < 0 {
+		return fmt.Errorf("--max-header-bytes %d may not be negative", co.MaxHeaderBytes)
+	}
+	if co.MatchAnywhere && (cmd.Flags().Changed("max-header-lines") || co.MaxHeaderBytes > 0) {
+		return ErrMatchAnywhereWindow
+	}
+	if co.MaxHeaderBytes > 0 && cmd.Flags().Changed("max-header-lines") {
+		return ErrHeaderWindowConflict
+	}
+	if co.ForbidDuplicateHeader && co.SPDX != "" {
+		return ErrDuplicateWithSPDX
+	}
+	if co.ForbidRemnants && co.SPDX != "" {
+		return ErrRemnantsWithSPDX
+	}
+	if co.RequireComment && co.SPDX != "" {
+		return ErrRequireCommentWithSPDX
+	}
+	if co.RequireTrailingBlank && co.SPDX != "" {
+		return ErrTrailingBlankWithSPDX
+	}
+	if co.RequireExactLength && co.SPDX != "" {
+		return ErrExactLengthWithSPDX
+	}
+	if co.RequireHolder != "" && co.SPDX != "" {
+		return ErrHolderWithSPDX
+	}
+	if co.YearRange != "" && co.SPDX != "" {
+		return ErrYearRangeWithSPDX
+	}
+	if co.GoPackageFollows && co.SPDX != "" {
+		return ErrGoPackageWithSPDX
+	}
+	if len(co.OpeningLines) > 0 && co.SPDX != "" {
+		return ErrOpeningLineWithSPDX
+	}
+	openers := make([]*regexp.Regexp, 0, len(co.OpeningLines))
+	for _, pattern := range co.OpeningLines {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("error compiling --opening-line pattern %q: %v", pattern, err)
+		}
+		// Match whole lines, as headers are compared.
+		openers = append(openers, regexp.MustCompile("^(?:"+pattern+")$"))
+	}
+	if co.RequireGeneratedMarker != "" && co.SPDX != "" {
+		return ErrGeneratedWithSPDX
+	}
+	if cmd.Flags().Changed("generated-marker") && co.RequireGeneratedMarker == "" {
+		return ErrMarkerRequiresGenerated
+	}
+	var generatedFiles, generatedMarker *regexp.Regexp
+	if co.RequireGeneratedMarker != "" {
+		var err error
+		generatedFiles, err = regexp.Compile(co.RequireGeneratedMarker)
+		if err != nil {
+			return fmt.Errorf("error compiling --require-generated-marker pattern %q: %v", co.RequireGeneratedMarker, err)
+		}
+		generatedMarker, err = regexp.Compile(co.GeneratedMarker)
+		if err != nil {
+			return fmt.Errorf("error compiling --generated-marker pattern %q: %v", co.GeneratedMarker, err)
+		}
+	}
+	if co.NoNormalize && (co.CollapseYearLists || co.RequireCurrentYear || co.UpdateYear || co.YearRange != "") {
+		return ErrNoNormalizeYearConflict
+	}
+	var minYear, maxYear int
+	if co.YearRange != "" {
+		var err error
+		if minYear, maxYear, err = parseYearRange(co.YearRange); err != nil {
+			return err
+		}
+	}
+	if len(co.CommentStyles) > 0 && !co.RequireComment && co.License == "" {
+		return ErrStyleRequiresComment
+	}
+	var styles map[string]boilerplate.CommentStyle
+	if co.RequireComment {
+		var err error
+		if styles, err = co.commentStyles(); err != nil {
+			return err
+		}
+	}
+	if len(co.Interpreters) > 0 && !co.SniffShebang {
+		return ErrInterpreterWithoutSniff
+	}
+	co.interpreters = nil
+	if co.SniffShebang {
+		co.interpreters = boilerplate.Interpreters()
+		for _, value := range co.Interpreters {
+			name, ext, err := parseInterpreter(value)
+			if err != nil {
+				return err
+			}
+			co.interpreters[name] = ext
+		}
+	}
+	aliases := make([][2]string, 0, len(co.Aliases))
+	for _, value := range co.Aliases {
+		old, new, err := parseAlias(value)
+		if err != nil {
+			return err
+		}
+		aliases = append(aliases, [2]string{old, new})
+	}
+
+	co.severities = make(map[boilerplate.Kind]boilerplate.Severity, len(co.Severities))
+	for _, value := range co.Severities {
+		kind, severity, err := parseSeverity(value)
+		if err != nil {
+			return err
+		}
+		co.severities[kind] = severity
+	}
+
+	if co.DryRun && !co.Fix {
+		return ErrDryRunRequiresFix
+	}
+	if co.UpdateYear && !co.Fix {
+		return ErrUpdateYearRequiresFix
+	}
+	if co.ForceFix && !co.Fix {
+		return ErrForceFixRequiresFix
+	}
+	if co.Count || co.CountFiles {
+		if cmd.Flags().Changed("format") {
+			return ErrCountWithFormat
+		}
+		if co.Fix {
+			return ErrCountWithFix
+		}
+	}
+	if co.PrintFiles != "" {
+		switch {
+		case co.PrintFiles != "passing" && co.PrintFiles != "failing":
+			return fmt.Errorf("--print-files %q must be one of: %s", co.PrintFiles, strings.Join(printFilesModes, ", "))
+		case cmd.Flags().Changed("format"):
+			return ErrPrintFilesWithFormat
+		case co.Fix:
+			return ErrPrintFilesWithFix
+		case co.Count || co.CountFiles:
+			return ErrPrintFilesWithCount
+		}
+	}
+	if co.Stats {
+		switch {
+		case cmd.Flags().Changed("format"):
+			return ErrStatsWithFormat
+		case co.Fix:
+			return ErrStatsWithFix
+		case co.Count || co.CountFiles || co.PrintFiles != "":
+			return ErrStatsWithCount
+		case co.DiffContext >= 0 || co.Anchor == "block-start":
+			return ErrStatsWithDiffContext
+		}
+	}
+
+	if co.CountByExtension && (co.Count || co.CountFiles || co.PrintFiles != "" || co.Stats) {
+		return ErrByExtensionWithCount
+	}
+
+	if !cmd.Flags().Changed("format") && !co.GroupByFile && os.Getenv("GITHUB_ACTIONS") == "true" {
+		// Annotate pull requests without any further setup.
+		co.Format = "github"
+	}
+	if !hasFormat(co.Format) {
+		return fmt.Errorf("--format %q must be one of: %s", co.Format, strings.Join(formatNames(), ", "))
+	}
+	if co.GroupByFile && co.Format != "text" {
+		return ErrGroupWithFormat
+	}
+	switch co.Color {
+	case "auto", "always", "never":
+	default:
+		return fmt.Errorf("--color %q must be one of: %s", co.Color, strings.Join(colorModes, ", "))
+	}
+
+	opts := []boilerplate.Option{
+		boilerplate.WithMaxHeaderLines(co.MaxHeaderLines),
+		boilerplate.WithMaxLineLength(co.MaxLineLength),
+	}
+	if len(co.FilePatterns) > 0 {
+		opts = append(opts, boilerplate.WithFilePatterns(co.FilePatterns...))
+	}
+	if co.MaxHeaderBytes > 0 {
+		opts = append(opts, boilerplate.WithMaxHeaderBytes(co.MaxHeaderBytes))
+	}
+	if co.MatchAnywhere {
+		opts = append(opts, boilerplate.WithMatchAnywhere())
+	}
+	if co.AllowLeadingLines {
+		opts = append(opts, boilerplate.WithLeadingLines())
+	}
+	if co.SkipLeadingBlank {
+		opts = append(opts, boilerplate.WithLeadingBlankLines())
+	}
+	if co.LeadingBlankPolicy == "remove" {
+		opts = append(opts, boilerplate.WithoutLeadingBlankLines())
+	}
+	if co.RequireAtTop {
+		opts = append(opts, boilerplate.WithHeaderAtTop())
+	}
+	if co.IgnoreTrailingWhitespace {
+		opts = append(opts, boilerplate.WithoutTrailingWhitespace())
+	}
+	if co.IgnoreLeadingWhitespace {
+		opts = append(opts, boilerplate.WithoutLeadingWhitespace())
+	}
+	if co.IgnoreCase {
+		opts = append(opts, boilerplate.WithoutCaseSensitivity())
+	}
+	if co.NormalizeUnicode {
+		opts = append(opts, boilerplate.WithNormalizedUnicode())
+	}
+	for _, alias := range aliases {
+		opts = append(opts, boilerplate.WithAlias(alias[0], alias[1]))
+	}
+	if co.TabWidth > 0 {
+		opts = append(opts, boilerplate.WithTabWidth(co.TabWidth))
+	}
+	if co.CollapseBlankLines {
+		opts = append(opts, boilerplate.WithCollapsedBlankLines())
+	}
+	if len(openers) > 0 {
+		opts = append(opts, boilerplate.WithOpeners(openers))
+	}
+	if co.CollapseYearLists {
+		opts = append(opts, boilerplate.WithYearLists())
+	}
+	if co.NoNormalize {
+		opts = append(opts, boilerplate.WithLiteralYears())
+	}
+	if co.ReportAllMismatches || co.Stats {
+		// --stats tallies each line that differs, not only the first.
+		opts = append(opts, boilerplate.WithAllMismatches())
+	}
+	if co.ForbidDuplicateHeader {
+		opts = append(opts, boilerplate.WithoutDuplicates())
+	}
+	if co.ForbidRemnants {
+		opts = append(opts, boilerplate.WithRemnants())
+	}
+	if co.RequireTrailingBlank {
+		opts = append(opts, boilerplate.WithTrailingBlankLine())
+	}
+	if co.RequireExactLength {
+		opts = append(opts, boilerplate.WithExactLength())
+	}
+	if co.GoPackageFollows {
+		opts = append(opts, boilerplate.WithGoPackageClause())
+	}
+	if generatedFiles != nil {
+		opts = append(opts, boilerplate.WithGeneratedMarker(generatedFiles, generatedMarker))
+	}
+	if co.RequireHolder != "" {
+		opts = append(opts, boilerplate.WithHolder(co.RequireHolder))
+	}
+	if styles != nil {
+		opts = append(opts, boilerplate.WithComments(styles))
+	}
+	if co.Columns {
+		opts = append(opts, boilerplate.WithColumns())
+	}
+	if co.Anchor == "block-start" {
+		opts = append(opts, boilerplate.WithBlockAnchor())
+	}
+	if co.DiffContext >= 0 {
+		opts = append(opts, boilerplate.WithDiffContext(co.DiffContext))
+	}
+	if co.RequireCurrentYear || co.UpdateYear {
+		opts = append(opts, boilerplate.WithCurrentYear())
+	}
+	if minYear > 0 {
+		opts = append(opts, boilerplate.WithYearRange(minYear, maxYear))
+	}
+	if co.ForceFix {
+		opts = append(opts, boilerplate.WithRewrites())
+	}
+	if co.Verbose {
+		opts = append(opts, boilerplate.WithFoundLines(verboseFoundLines))
+	}
+	co.newChecker = func(lines []string) *boilerplate.Checker {
+		return boilerplate.NewChecker(lines, co.FileExtensions, excludes, opts...)
+	}
+	co.forbidden = make([]*boilerplate.Checker, 0, len(forbids))
+	for _, lines := range forbids {
+		co.forbidden = append(co.forbidden,
+			boilerplate.NewForbiddenChecker(lines, co.FileExtensions, excludes, opts...))
+	}
+	if co.SPDX != "" {
+		if copyright != nil {
+			opts = append(opts, boilerplate.WithCopyright(copyright))
+		}
+		co.checkers = []*boilerplate.Checker{
+			boilerplate.NewSPDXChecker(co.SPDX, co.FileExtensions, excludes, opts...),
+		}
+	} else {
+		co.checkers = make([]*boilerplate.Checker, 0, len(variants))
+		for _, lines := range variants {
+			co.checkers = append(co.checkers, co.newChecker(lines))
+		}
+	}
+	co.byExtension = make(map[string][]*boilerplate.Checker, len(byExtension))
+	for name, variants := range byExtension {
+		for _, lines := range variants {
+			co.byExtension[name] = append(co.byExtension[name], co.newChecker(lines))
+		}
+	}
+	if co.Cache != "" {
+		var err error
+		co.cacheKey, err = co.configKey(variants, forbids, byExtension)
+		if err != nil {
+			return err
+		}
+	}
+	// Every checker selects the same files, but there may be
+	// only forbidden ones, or only those of --boilerplate-dir.
+	switch {
+	case len(co.checkers) > 0:
+		co.filter = co.checkers[0]
+	case len(co.forbidden) > 0:
+		co.filter = co.forbidden[0]
+	default:
+		co.filter = co.byExtension[names[0]][0]
 	}
 	return nil
 }
 
-func (co *checkOptions) match(path string) bool {
-	// Check whether the file extension matches.
-	if ext := filepath.Ext(path); ext != co.FileExtension {
-		return false
+// parseBoilerplate splits content into the lines of a boilerplate,
+// which is referred to by source in messages.
+func (co *checkOptions) parseBoilerplate(content, source string) ([]string, error) {
+	if co.Template {
+		var err error
+		content, err = expandTemplate(content, source, co.Project)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if content == "" {
+		return nil, fmt.Errorf("%s is empty", source)
+	}
+	lines := strings.Split(content, "\n")
+	if err := validateBoilerplate(co.log, source, lines, co.IgnoreTrailingWhitespace); err != nil {
+		return nil, err
 	}
+	if co.NoNormalize && strings.Contains(content, "YYYY") {
+		co.log.logf(warnLevel, "", "%s contains YYYY, which --no-normalize matches only as is, "+
+			"unless --boilerplate-template fills in {{.Year}} instead", source)
+	}
+	return lines, nil
+}
 
-	// Check whether the file is excluded by a pattern.
-	if co.exclude != nil {
-		if co.exclude.MatchString(path) {
-			return false
+// validateBoilerplate rejects boilerplate that no header could usefully
+// match, and warns about boilerplate that few headers will.  The
+// boilerplate is referred to by source in messages.
+func validateBoilerplate(log *logger, source string, lines []string, ignoreTrailingWhitespace bool) error {
+	blank := true
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			blank = false
+			break
+		}
+	}
+	if blank {
+		return fmt.Errorf("%s has only blank lines", source)
+	}
+	wild := true
+	for _, line := range lines {
+		if trimmed := strings.TrimSpace(line); trimmed != "" && trimmed != boilerplate.WildcardLine {
+			wild = false
+			break
 		}
 	}
-	return true
+	if wild {
+		return fmt.Errorf("%s has only blank and %s lines, which any file would match", source, boilerplate.WildcardLine)
+	}
+
+	if ignoreTrailingWhitespace {
+		return nil
+	}
+	for i, line := range lines {
+		if strings.TrimRight(line, " \t") != line {
+			log.logf(warnLevel, "", "%s has trailing whitespace on line %d, "+
+				"which headers must match unless --ignore-trailing-whitespace is passed", source, i+1)
+			break
+		}
+	}
+	return nil
+}
+
+// parseSince parses a --since date, as YYYY-MM-DD in local time, or an
+// RFC 3339 time.
+func parseSince(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("--since %q must be a date, as YYYY-MM-DD, or an RFC 3339 time", value)
 }
 
 func (co *checkOptions) RunE(cmd *cobra.Command, args []string) error {
-	return filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+	if co.Watch {
+		// Watch before the first check, so that no change is missed.
+		dw, err := co.newDirWatcher()
 		if err != nil {
+			return fmt.Errorf("error watching for changes: %v", err)
+		}
+		defer dw.Close()
+		ticker := time.NewTicker(co.WatchInterval)
+		defer ticker.Stop()
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		defer signal.Stop(interrupt)
+		return co.watch(cmd, dw.events, ticker.C, interrupt)
+	}
+	return withIssuesExitCode(co.run(cmd, func() error {
+		return co.checkAll(cmd)
+	}), co.IssuesExitCode)
+}
+
+// checkAll checks the files listed by --files-from or --files-from0, or
+// else those under each --root.
+func (co *checkOptions) checkAll(cmd *cobra.Command) error {
+	if co.FilesFrom != "" || co.FilesFrom0 != "" {
+		return co.checkFiles(cmd)
+	}
+	for _, root := range co.Roots {
+		if err := co.walk(cmd, root); err != nil {
 			return err
 		}
-		if !info.Mode().IsRegular() {
-			return nil
+	}
+	return nil
+}
+
+// run checks the files that visit visits, and reports the results.
+func (co *checkOptions) run(cmd *cobra.Command, visit func() error) error {
+	// Errors past flag validation don't warrant usage, and are
+	// reported by our caller.
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	start := time.Now()
+	co.summary = summary{}
+	if co.CountByExtension {
+		co.summary.ByExtension = make(map[string]int)
+	}
+	co.matched = 0
+	co.overrides = make(map[string][]*boilerplate.Checker)
+	co.tops = make(map[string]bool)
+	co.progress = newProgress(co.Progress, cmd.OutOrStdout(), cmd.ErrOrStderr())
+	defer co.progress.clear()
+	out, errOut := co.progress.clearing(cmd.OutOrStdout()), co.progress.clearing(cmd.ErrOrStderr())
+	logOut := co.log.out
+	co.log.out = co.progress.clearing(logOut)
+	defer func() { co.log.out = logOut }()
+	switch {
+	case co.Count || co.CountFiles:
+		co.formatter = &countFormatter{out: out, files: co.CountFiles}
+	case co.PrintFiles != "":
+		co.formatter = &filesFormatter{out: out, failing: co.PrintFiles == "failing"}
+	case co.Stats:
+		co.formatter = newStatsFormatter(out)
+	case co.GroupByFile:
+		co.formatter = newGroupFormatter(newTextFormatter(co, out, errOut))
+	default:
+		co.formatter = newFormatter(co, out, errOut)
+	}
+	if co.prefix != "" {
+		co.formatter = &prefixFormatter{formatter: co.formatter, prefix: co.prefix}
+	}
+	co.cache = nil
+	if co.Cache != "" {
+		var err error
+		co.cache, err = loadCache(co.log, co.Cache)
+		if err != nil {
+			return err
 		}
-		if !co.match(path) {
-			return nil
+	}
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if co.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, co.Timeout)
+	}
+	defer cancel()
+	co.ctx = ctx
+	co.queue = nil
+	err := visit()
+	if err == nil {
+		err = co.flush(true)
+	}
+	if err == errTimedOut {
+		// Report the files checked so far, abandoning those that a
+		// slow read still holds up.
+		err = co.drain()
+		if err == nil {
+			err = errTimedOut
+		}
+	}
+	co.queue = nil
+	switch err {
+	case nil:
+	case errFailFast:
+		co.log.logf(infoLevel, "", "%v, per --fail-fast", err)
+	case errTimedOut:
+		co.log.logf(warnLevel, "", "%v after %v, per --timeout, so files may be left unchecked", err, co.Timeout)
+	default:
+		return err
+	}
+	if co.cache != nil {
+		if err := co.cache.save(co.Cache); err != nil {
+			return err
 		}
+	}
+	co.log.logf(infoLevel, "", "checked %d of %d matching files in %v", co.summary.Checked, co.matched, time.Since(start))
+	if err := co.formatter.Summary(co.summary); err != nil {
+		return err
+	}
+	if err == errTimedOut {
+		return &exitError{ExitTimeout, fmt.Sprintf("timed out after %v, per --timeout", co.Timeout)}
+	}
+	if co.FailOnNoMatches && co.matched == 0 {
+		return ErrNoMatches
+	}
+	if co.Count || co.CountFiles || co.PrintFiles != "" || co.Stats {
+		// The output is meant to be captured, so it isn't an error.
+		return nil
+	}
+	switch {
+	case co.DryRun && co.summary.Fixed > 0:
+		return &exitError{ExitViolations, fmt.Sprintf("--fix would change %d file(s)", co.summary.Fixed)}
+	case co.Fix && co.summary.Failed > 0:
+		return &exitError{ExitViolations, fmt.Sprintf("--fix could not correct %d file(s)", co.summary.Failed)}
+	case co.summary.Failed > 0:
+		return &exitError{ExitViolations, fmt.Sprintf("found %d violations in %d file(s)", co.summary.Violations, co.summary.Failed)}
+	}
+	return nil
+}
 
-		// Open the file to copy it into the tarball.
-		file, err := os.Open(path)
+// walk checks the files under root, which are reported (and matched
+// against --exclude) by their path relative to root, or with --find-root,
+// to the repository root.
+func (co *checkOptions) walk(cmd *cobra.Command, root string) error {
+	walk := filepath.Walk
+	if co.FollowSymlinks {
+		walk = walkFollowing
+	}
+	// Overrides above the root don't apply to it.
+	co.tops[filepath.Clean(root)] = true
+	return walk(root, func(file string, info os.FileInfo, walkErr error) error {
+		path, err := co.rel(root, file)
 		if err != nil {
 			return err
 		}
-		defer file.Close()
+		if walkErr != nil {
+			return co.later(func() error {
+				return co.record(path, co.failure(boilerplate.Unreadable), co.unreadable(path, walkErr))
+			})
+		}
+		if info.IsDir() && path != "." {
+			// Skip excluded directories without reading them.
+			if pattern := co.excludedDir(path); pattern != "" {
+				co.log.logf(debugLevel, path, "skipped: exclude-dir %s", pattern)
+				return filepath.SkipDir
+			}
+			if co.MaxDepth > 0 && depth(root, file) >= co.MaxDepth {
+				co.log.logf(debugLevel, path, "skipped: its files are deeper than --max-depth %d", co.MaxDepth)
+				return filepath.SkipDir
+			}
+		}
+		return co.visit(cmd, file, path, info)
+	})
+}
 
-		scanner := bufio.NewScanner(file)
+// depth returns how many directory levels below root file is, where the
+// files directly within root are at depth 1, and root itself at 0.
+func depth(root, file string) int {
+	rel, err := filepath.Rel(root, file)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
 
-		// Find the first matching line of the file.
-		idx, found := 1, false
-		// TODO(mattmoor): Consider making the number of lines to scan a flag.
-		for ; idx <= 10; idx++ {
-			if !scanner.Scan() {
-				break
-			}
-			line := normalize(scanner.Text())
-			if line == co.boilerplateLines[0] {
-				found = true
-				break
+// compileExcludeDirs compiles the --exclude-dir patterns, anchored to
+// match whole names, so that vendor doesn't match vendored.
+func compileExcludeDirs(patterns []string) ([]*regexp.Regexp, error) {
+	excludes := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("error compiling --exclude-dir pattern %q: %v", pattern, err)
+		}
+		excludes = append(excludes, regexp.MustCompile("^(?:"+pattern+")$"))
+	}
+	return excludes, nil
+}
+
+// excludedDir returns the one of patterns, compiled as excludes, that
+// matches the name or path of dir, or "" if there is none.
+func excludedDir(excludes []*regexp.Regexp, patterns []string, dir string) string {
+	for i, exclude := range excludes {
+		if exclude.MatchString(dir) || exclude.MatchString(filepath.Base(dir)) {
+			return patterns[i]
+		}
+	}
+	return ""
+}
+
+// excludedDir returns the --exclude-dir pattern that matches the name or
+// path of dir, or "" if there is none.
+func (co *checkOptions) excludedDir(dir string) string {
+	return excludedDir(co.excludeDirs, co.ExcludeDirs, dir)
+}
+
+// inExcludedDir returns the --exclude-dir pattern that matches one of the
+// directories that path is under, or "" if there is none.
+func (co *checkOptions) inExcludedDir(path string) string {
+	for dir := filepath.Dir(path); dir != "." && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if pattern := co.excludedDir(dir); pattern != "" {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// checkFiles checks the files listed by --files-from or --files-from0.
+func (co *checkOptions) checkFiles(cmd *cobra.Command) error {
+	flag, name, split := "--files-from", co.FilesFrom, bufio.ScanLines
+	if co.FilesFrom0 != "" {
+		flag, name, split = "--files-from0", co.FilesFrom0, scanNul
+	}
+
+	r := cmd.InOrStdin()
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return fmt.Errorf("error reading %s file %q: %v", flag, name, err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Split(split)
+	for scanner.Scan() {
+		path := scanner.Text()
+		if path == "" {
+			continue
+		}
+		if pattern := co.inExcludedDir(path); pattern != "" {
+			co.log.logf(debugLevel, path, "skipped: exclude-dir %s", pattern)
+			continue
+		}
+		file := co.fromBase(path)
+		info, err := os.Lstat(file)
+		if err != nil {
+			if err := co.later(func() error {
+				return co.record(path, co.failure(boilerplate.Unreadable), co.unreadable(path, err))
+			}); err != nil {
+				return err
 			}
+			continue
 		}
-		if !found {
-			cmd.Printf("%s:%d: missing boilerplate:\n%s",
-				path, 1, denormalize(strings.Join(co.boilerplateLines, "\n")))
-			return nil
+		if err := co.visit(cmd, file, path, info); err != nil {
+			return err
 		}
+	}
+	return scanner.Err()
+}
 
-		lines := make([]string, 0, len(co.boilerplateLines))
-		lines = append(lines, co.boilerplateLines[0])
+// scanNul is a bufio.SplitFunc for NUL-delimited input, as produced
+// by `git diff -z`.
+func scanNul(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
 
-		for range co.boilerplateLines[1:] {
-			if !scanner.Scan() {
-				cmd.Printf("%s:%d: incomplete boilerplate, missing:\n%s", path, idx,
-					denormalize(strings.Join(co.boilerplateLines[len(lines):], "\n")))
+// visit checks file, reported by path, if it matches our filters.
+func (co *checkOptions) visit(cmd *cobra.Command, file, path string, info os.FileInfo) error {
+	if co.ctx.Err() != nil {
+		return errTimedOut
+	}
+	if info.IsDir() {
+		co.log.logf(debugLevel, path, "directory")
+		return nil
+	}
+	reason := co.filter.SkipReason(path)
+	if reason != "" && !co.sniffs(path) {
+		co.log.logf(debugLevel, path, "skipped: %s", reason)
+		return nil
+	}
+	// The boilerplate overrides that apply are those of the file's
+	// directory, which with --check-symlink-targets is the link's.
+	dir := filepath.Dir(file)
+	if info.Mode()&os.ModeSymlink != 0 {
+		// Check (and fix) symlinked files through their target,
+		// but report them by the path of the link.
+		target, err := filepath.EvalSymlinks(file)
+		if err == nil {
+			info, err = os.Stat(target)
+		}
+		if err != nil {
+			if co.CheckSymlinkTargets {
+				co.log.logf(warnLevel, path, "skipped: broken symlink: %v", err)
 				return nil
 			}
+			return co.later(func() error {
+				return co.record(path, co.failure(boilerplate.Unreadable), co.unreadable(path, err))
+			})
+		}
+		file = target
+		if !co.CheckSymlinkTargets {
+			dir = filepath.Dir(file)
+		}
+	}
+	if !info.Mode().IsRegular() {
+		co.log.logf(debugLevel, path, "skipped: not a regular file")
+		return nil
+	}
+	if reason != "" {
+		ext, err := co.sniff(file, path)
+		if err != nil {
+			co.log.logf(infoLevel, path, "skipped: could not read its shebang: %v", err)
+			return nil
+		}
+		if ext == "" {
+			co.log.logf(debugLevel, path, "skipped: %s, and no shebang of one", reason)
+			return nil
+		}
+		co.log.logf(debugLevel, path, "checked as a .%s file, per its shebang", ext)
+	}
+	co.matched++
+	if co.MaxFileSize > 0 && info.Size() > co.MaxFileSize {
+		// Unlike other skipped files, these may well lack a header, so
+		// warn that they were not checked.
+		co.log.logf(warnLevel, path, "skipped: %d bytes is larger than --max-file-size %d",
+			info.Size(), co.MaxFileSize)
+		return nil
+	}
+	if !co.since.IsZero() && info.ModTime().Before(co.since) {
+		co.log.logf(debugLevel, path, "skipped: modified before --since %s", co.Since)
+		return nil
+	}
+	checkers, err := co.checkersFor(cmd, dir)
+	if err != nil {
+		return err
+	}
+	// Overrides aren't part of the cache's key, so files under them
+	// aren't cached.
+	cached := sameCheckers(checkers, co.checkers)
+	if checkers, err = co.forFile(path, checkers); err != nil {
+		return err
+	}
+	if cached && co.cache.passed(file, info, co.cacheKey) {
+		co.log.logf(debugLevel, path, "passed, and unchanged since, per --cache")
+		return co.later(func() error {
+			return co.record(path, conforming, nil)
+		})
+	}
+	co.log.logf(debugLevel, path, "checked")
+	return co.checkLater(cmd, checkers, file, path, info, cached)
+}
 
-			lines = append(lines, normalize(scanner.Text()))
+// record tallies the outcome of checking the file reported by path,
+// unless checking it failed with err.
+func (co *checkOptions) record(path string, result outcome, err error) error {
+	if err != nil {
+		return err
+	}
+	co.summary.Checked++
+	switch result {
+	case conforming:
+		co.summary.Passed++
+	case fixed:
+		co.summary.Fixed++
+	case warned:
+		// Warnings do not fail the check.
+		co.summary.Passed++
+	case violation:
+		co.summary.Failed++
+	}
+	co.progress.update(co.summary)
+	if ff, ok := co.formatter.(fileFormatter); ok {
+		if err := ff.File(path, result); err != nil {
+			return err
 		}
+	}
+	if co.FailFast && result == violation {
+		return errFailFast
+	}
+	return nil
+}
 
-		// We comment on the first bad line instead of the first line of the comment
-		// because if the error is a change, and the first line of the comment block
-		// isn't part of the diff, then reviewdog will filter the error.
-		for i := range lines {
-			if co.boilerplateLines[i] != lines[i] {
-				cmd.Printf("%s:%d: found mismatched boilerplate lines:\n%s",
-					path, idx+i, denormalize(cmp.Diff(co.boilerplateLines[i:], lines[i:])))
-				break
-			}
+// outcome is the result of checking a single file.
+type outcome int
+
+const (
+	conforming outcome = iota
+	fixed
+	warned
+	violation
+)
+
+// unreadable reports that the file at path could not be read, or
+// returns err with --fail-on-error.
+func (co *checkOptions) unreadable(path string, err error) error {
+	if co.FailOnError {
+		return err
+	}
+	co.log.logf(infoLevel, path, "could not read, continuing: %v", err)
+	return co.emit(boilerplate.Violation{
+		Path:   path,
+		Kind:   boilerplate.Unreadable,
+		Detail: err.Error(),
+	})
+}
+
+// dropAllowed removes the missing boilerplate violations of files that
+// --allow-missing or --allow-missing-from permit to lack it.
+func (co *checkOptions) dropAllowed(cmd *cobra.Command, path string, violations []boilerplate.Violation) []boilerplate.Violation {
+	allowed := co.allowedMissing[filepath.Clean(path)] ||
+		(co.allowMissing != nil && co.allowMissing.MatchString(path))
+	if !allowed {
+		return violations
+	}
+	kept := violations[:0]
+	for _, v := range violations {
+		if v.Kind == boilerplate.Missing {
+			co.log.logf(debugLevel, path, "allowed to lack boilerplate")
+			continue
 		}
-		return nil
+		kept = append(kept, v)
+	}
+	return kept
+}
+
+// check checks the boilerplate of a single file, read through open,
+// against those of checkers, reporting or fixing any problems it finds.
+// The file is reported by path.
+func (co *checkOptions) check(cmd *cobra.Command, checkers []*boilerplate.Checker, open func(string) (io.ReadCloser, error), file, path string, info os.FileInfo) (outcome, error) {
+	violations, err := find(checkers, co.forbidden, open, file, path)
+	return co.report(cmd, file, path, info, violations, err)
+}
+
+// find returns the violations of file, read through open, against the
+// closest of the checkers' boilerplates and any of the forbidden ones.
+// Unlike the rest of checking a file, it may run concurrently.
+func find(checkers, forbids []*boilerplate.Checker, open func(string) (io.ReadCloser, error), file, path string) ([]boilerplate.Violation, error) {
+	violations, err := closest(checkers, open, file, path)
+	if err != nil {
+		return nil, err
+	}
+	found, err := forbidden(forbids, open, file, path)
+	if err != nil {
+		return nil, err
+	}
+	return append(violations, found...), nil
+}
+
+// report reports or fixes the violations that find found in a single
+// file, or that it could not read the file, with err.
+func (co *checkOptions) report(cmd *cobra.Command, file, path string, info os.FileInfo, violations []boilerplate.Violation, err error) (outcome, error) {
+	if err != nil {
+		return co.failure(boilerplate.Unreadable), co.unreadable(path, err)
+	}
+	violations = co.dropAllowed(cmd, path, violations)
+	if len(violations) == 0 {
+		return conforming, nil
+	}
+	// Report violations by line, rather than by the checker that
+	// found them.
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Line < violations[j].Line
 	})
+
+	result := fixed
+	var edits []*boilerplate.Edit
+	for _, v := range violations {
+		if co.Fix && v.Fix != nil {
+			edits = append(edits, v.Fix)
+			continue
+		}
+		if err := co.emit(v); err != nil {
+			return violation, err
+		}
+		if f := co.failure(v.Kind); f > result {
+			result = f
+		}
+	}
+	if len(edits) == 0 {
+		return result, nil
+	}
+	// A file we fix counts as fixed, despite any warnings.
+	if result == warned {
+		result = fixed
+	}
+	return result, co.apply(cmd, file, path, info, edits)
+}
+
+// closest checks file, read through open, against each of the checkers'
+// boilerplates in turn, and returns no violations if its header matches
+// any of them, or else the violations against the one it is closest to.
+func closest(checkers []*boilerplate.Checker, open func(string) (io.ReadCloser, error), file, path string) ([]boilerplate.Violation, error) {
+	var closest []boilerplate.Violation
+	for i, checker := range checkers {
+		f, err := open(file)
+		if err != nil {
+			return nil, err
+		}
+		violations, err := checker.Check(path, f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(violations) == 0 {
+			return nil, nil
+		}
+		if i == 0 || diffSize(violations) < diffSize(closest) {
+			closest = violations
+		}
+	}
+	return closest, nil
 }
 
-// TODO(mattmoor): Fix this y10k bug.
-var matchYear = regexp.MustCompile("[0-9][0-9][0-9][0-9]")
+// forbidden checks file, read through open, against each of the
+// checkers' forbidden boilerplates, and returns the violations of any
+// it matches.
+func forbidden(checkers []*boilerplate.Checker, open func(string) (io.ReadCloser, error), file, path string) ([]boilerplate.Violation, error) {
+	var found []boilerplate.Violation
+	for _, checker := range checkers {
+		f, err := open(file)
+		if err != nil {
+			return nil, err
+		}
+		violations, err := checker.Check(path, f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, violations...)
+	}
+	return found, nil
+}
 
-// normalize strips year-like strings out in favor of YYYY,
-// so that we do not complain about older files with otherwise
-// fine headers.
-func normalize(line string) string {
-	return matchYear.ReplaceAllString(line, "YYYY")
+// diffSize measures how far a header is from matching a boilerplate by
+// the number of lines in the details of its violations.  A missing header
+// is furthest of all, so that --fix inserts the first boilerplate.
+func diffSize(violations []boilerplate.Violation) int {
+	size := 0
+	for _, v := range violations {
+		if v.Kind == boilerplate.Missing {
+			return math.MaxInt32
+		}
+		size += strings.Count(v.Detail, "\n") + 1
+	}
+	return size
 }
 
-// denormalize replaces YYYY with the current year.
-func denormalize(line string) string {
-	return strings.ReplaceAll(line, "YYYY", fmt.Sprint(time.Now().Year()))
+// apply rewrites file with the edits applied, or with --dry-run prints
+// the diff of doing so.
+func (co *checkOptions) apply(cmd *cobra.Command, file, path string, info os.FileInfo, edits []*boilerplate.Edit) error {
+	bts, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	// Apply the edits from the bottom up, so that each one's lines
+	// are not shifted by the others.
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].Start > edits[j].Start
+	})
+	updated := string(bts)
+	for _, edit := range edits {
+		updated = edit.Apply(updated)
+	}
+	if co.DryRun {
+		cmd.Print(unifiedDiff(path, string(bts), updated))
+		return nil
+	}
+	return writeFile(file, info, []byte(updated))
 }
@@ -18,11 +18,32 @@ package commands
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+	"github.com/spf13/cobra"
 )
 
+func TestMain(m *testing.M) {
+	// Run the same way inside and outside of GitHub Actions, and
+	// whatever defaults the environment sets.
+	os.Unsetenv("GITHUB_ACTIONS")
+	for _, ef := range envFlags {
+		os.Unsetenv(ef.env)
+	}
+	os.Exit(m.Run())
+}
+
 func TestCheckPreRunE(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -43,6 +64,18 @@ func TestCheckPreRunE(t *testing.T) {
 			"--boilerplate", "testdata/empty.txt",
 		},
 		wantErr: errors.New(`--boilerplate file "testdata/empty.txt" is empty`),
+	}, {
+		name: "forbidden boilerplate not found",
+		args: []string{
+			"--forbid", "testdata/not-found.mm.txt",
+		},
+		wantErr: errors.New(`error reading --forbid file "testdata/not-found.mm.txt": open testdata/not-found.mm.txt: no such file or directory`),
+	}, {
+		name: "blank forbidden boilerplate",
+		args: []string{
+			"--forbid", "testdata/blank.txt",
+		},
+		wantErr: errors.New(`--forbid file "testdata/blank.txt" has only blank lines`),
 	}, {
 		name: "just boilerplate",
 		args: []string{
@@ -56,6 +89,20 @@ func TestCheckPreRunE(t *testing.T) {
 			"--file-extension", ".mm",
 		},
 		wantErr: errors.New(`--file-extension ".mm" may not contain '.'`),
+	}, {
+		name: "with a slash in a pattern",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-pattern", "build/Dockerfile",
+		},
+		wantErr: errors.New(`--file-pattern "build/Dockerfile" may not contain '/', since it matches base names`),
+	}, {
+		name: "bad pattern",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-pattern", "Dockerfile[",
+		},
+		wantErr: errors.New(`error parsing --file-pattern "Dockerfile[": syntax error in pattern`),
 	}, {
 		name: "bad regexp",
 		args: []string{
@@ -72,152 +119,2800 @@ func TestCheckPreRunE(t *testing.T) {
 			"--exclude", ".*.bad.mm",
 		},
 		wantErr: nil,
-	}}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			cmd := NewCheckCommand()
-			output := new(bytes.Buffer)
-			cmd.SetOut(output)
-
-			cmd.SetArgs(test.args)
-
-			gotErr := cmd.Execute()
-			if (test.wantErr != nil) != (gotErr != nil) {
-				t.Errorf("Execute() = %v, wanted %v", gotErr, test.wantErr)
-			} else if (test.wantErr != nil) && (gotErr != nil) {
-				got, want := gotErr.Error(), test.wantErr.Error()
-				if got != want {
-					t.Errorf("Execute() = %s, wanted %s", got, want)
-				}
-			}
-		})
-	}
-}
-
-func TestCheckRunE(t *testing.T) {
-	tests := []struct {
-		name string
-		args []string
-		want string
-	}{{
-		name: "with typo mismatch error",
+	}, {
+		name: "dry run without fix",
 		args: []string{
 			"--boilerplate", "testdata/boilerplate.mm.txt",
 			"--file-extension", "mm",
-			"--exclude", "[^o].bad.mm",
+			"--dry-run",
 		},
-		want: denormalize(`testdata/typo.bad.mm:2: found mismatched boilerplate lines:
-{[]string}[0]:
-	-: "Copyright YYYY Matt Moore"
-	+: "Copyright YYYY Matt More"
-`),
+		wantErr: ErrDryRunRequiresFix,
 	}, {
-		name: "with whitespace mismatch error",
+		name: "bad decompress",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "gz",
+			"--decompress", "bzip2",
+		},
+		wantErr: errors.New(`--decompress "bzip2" must be one of: none, gzip`),
+	}, {
+		name: "decompress with fix",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "gz",
+			"--decompress", "gzip",
+			"--fix",
+		},
+		wantErr: ErrDecompressWithFix,
+	}, {
+		name: "comment style without require comment",
 		args: []string{
 			"--boilerplate", "testdata/boilerplate.mm.txt",
 			"--file-extension", "mm",
-			"--exclude", "[^d].bad.mm",
+			"--comment-style", "mm=//,/*,*/",
 		},
-		want: `testdata/trimmed.bad.mm:3: found mismatched boilerplate lines:
-{[]string}[0->?]:
-	-: ""
-	+: <non-existent>
-{[]string}[4->?]:
-	-: ""
-	+: <non-existent>
-{[]string}[6->?]:
-	-: ""
-	+: <non-existent>
-{[]string}[?->10]:
-	-: <non-existent>
-	+: ""
-{[]string}[?->11]:
-	-: <non-existent>
-	+: "// Package foo builds widgets"
-{[]string}[?->12]:
-	-: <non-existent>
-	+: "package foo"
-`,
+		wantErr: ErrStyleRequiresComment,
 	}, {
-		name: "with http[s] mismatch error",
+		name: "bad comment style",
 		args: []string{
 			"--boilerplate", "testdata/boilerplate.mm.txt",
 			"--file-extension", "mm",
-			"--exclude", "[^s].bad.mm",
+			"--require-comment",
+			"--comment-style", "mm=/*,*/",
 		},
-		want: `testdata/https.bad.mm:8: found mismatched boilerplate lines:
-{[]string}[0]:
-	-: "    http://www.apache.org/licenses/LICENSE-2.0"
-	+: "    https://www.apache.org/licenses/LICENSE-2.0"
-`,
+		wantErr: errors.New(`--comment-style "mm=/*,*/" must be of the form EXT=LINE[,START,END]`),
 	}, {
-		name: "with tab/space mismatch error",
+		name: "license with boilerplate",
 		args: []string{
 			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "go",
+			"--license", "MIT",
+		},
+		wantErr: ErrLicenseWithBoilerplate,
+	}, {
+		name: "unknown license",
+		args: []string{
+			"--file-extension", "go",
+			"--license", "GPL-3.0",
+		},
+		wantErr: errors.New(`--license "GPL-3.0" must be one of: ` + strings.Join(boilerplate.Licenses(), ", ")),
+	}, {
+		name: "license without comment style",
+		args: []string{
 			"--file-extension", "mm",
-			"--exclude", "[^b].bad.mm",
+			"--license", "MIT",
 		},
-		want: `testdata/tab.bad.mm:8: found mismatched boilerplate lines:
-{[]string}[0]:
-	-: "    http://www.apache.org/licenses/LICENSE-2.0"
-	+: "\thttp://www.apache.org/licenses/LICENSE-2.0"
-`,
+		wantErr: errors.New(`--file-extension "mm" has no comment style for --license, which --comment-style may give`),
 	}, {
-		name: "with too short error",
+		name: "bad alias",
 		args: []string{
 			"--boilerplate", "testdata/boilerplate.mm.txt",
 			"--file-extension", "mm",
-			"--exclude", "[^t].bad.mm",
+			"--alias", "Matt Moore",
 		},
-		want: `testdata/short.bad.mm:1: incomplete boilerplate, missing:
-WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
-See the License for the specific language governing permissions and
-limitations under the License.
-*/
-`,
+		wantErr: errors.New(`--alias "Matt Moore" must be of the form OLD=NEW`),
 	}, {
-		name: "with no header error",
+		name: "require comment with spdx",
+		args: []string{
+			"--spdx", "Apache-2.0",
+			"--file-extension", "mm",
+			"--require-comment",
+		},
+		wantErr: ErrRequireCommentWithSPDX,
+	}, {
+		name: "bad exclude-dir",
 		args: []string{
 			"--boilerplate", "testdata/boilerplate.mm.txt",
 			"--file-extension", "mm",
-			"--exclude", "[^g].bad.mm",
+			"--exclude-dir", "vendor(",
 		},
-		want: denormalize(`testdata/missing.bad.mm:1: missing boilerplate:
-/*
-Copyright YYYY Matt Moore
-
-Licensed under the Apache License, Version 2.0 (the "License");
-you may not use this file except in compliance with the License.
-You may obtain a copy of the License at
-
-    http://www.apache.org/licenses/LICENSE-2.0
-
-Unless required by applicable law or agreed to in writing, software
-distributed under the License is distributed on an "AS IS" BASIS,
-WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
-See the License for the specific language governing permissions and
-limitations under the License.
-*/
-`),
-	}}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			cmd := NewCheckCommand()
-			output := new(bytes.Buffer)
-			cmd.SetOut(output)
-
-			cmd.SetArgs(test.args)
-
-			err := cmd.Execute()
-			if err != nil {
-				t.Errorf("Execute() = %v", err)
-			}
-
-			got := output.String()
-			if test.want != got {
-				t.Errorf("Execute() = %s, wanted %s", got, test.want)
+		wantErr: errors.New("error compiling --exclude-dir pattern \"vendor(\": error parsing regexp: missing closing ): `vendor(`"),
+	}, {
+		name: "watch with files-from",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--files-from", "testdata/allow-missing.txt",
+			"--watch",
+		},
+		wantErr: ErrWatchWithFilesFrom,
+	}, {
+		name: "watch with fix",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--watch",
+			"--fix",
+		},
+		wantErr: ErrWatchWithFix,
+	}, {
+		name: "watch interval not positive",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--watch",
+			"--watch-interval", "0s",
+		},
+		wantErr: errors.New("--watch-interval 0s must be positive"),
+	}, {
+		name: "both files-from flags",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--files-from", "-",
+			"--files-from0", "-",
+		},
+		wantErr: ErrFilesFromConflict,
+	}, {
+		name: "files-from with root",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--files-from", "-",
+			"--root", "testdata",
+		},
+		wantErr: ErrFilesFromWithRoot,
+	}, {
+		name: "root not found",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--root", "testdata/not-found",
+		},
+		wantErr: errors.New(`error reading --root "testdata/not-found": stat testdata/not-found: no such file or directory`),
+	}, {
+		name: "root not a directory",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--root", "testdata/empty.txt",
+		},
+		wantErr: errors.New(`--root "testdata/empty.txt" is not a directory`),
+	}, {
+		name: "bad format",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--format", "yaml",
+		},
+		wantErr: errors.New(`--format "yaml" must be one of: checkstyle, github, json, rdjsonl, sarif, text`),
+	}, {
+		name: "bad color",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--color", "sometimes",
+		},
+		wantErr: errors.New(`--color "sometimes" must be one of: auto, always, never`),
+	}, {
+		name: "spdx with boilerplate",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--spdx", "Apache-2.0",
+			"--file-extension", "mm",
+		},
+		wantErr: ErrSPDXWithBoilerplate,
+	}, {
+		name: "copyright pattern without spdx",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--copyright-pattern", "Copyright",
+			"--file-extension", "mm",
+		},
+		wantErr: ErrCopyrightRequiresSPDX,
+	}, {
+		name: "bad copyright pattern",
+		args: []string{
+			"--spdx", "Apache-2.0",
+			"--copyright-pattern", "(Copyright",
+			"--file-extension", "mm",
+		},
+		wantErr: errors.New("error compiling --copyright-pattern \"(Copyright\": error parsing regexp: missing closing ): `(Copyright`"),
+	}, {
+		name: "update year without fix",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--update-year",
+		},
+		wantErr: ErrUpdateYearRequiresFix,
+	}, {
+		name: "force fix without fix",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--force-fix",
+		},
+		wantErr: ErrForceFixRequiresFix,
+	}, {
+		name: "bad severity",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--severity", "outdated=info",
+		},
+		wantErr: errors.New(`--severity "outdated=info" must give a LEVEL of error or warning`),
+	}, {
+		name: "bad allow missing pattern",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--allow-missing", "(",
+		},
+		wantErr: errors.New("error compiling --allow-missing pattern \"(\": error parsing regexp: missing closing ): `(`"),
+	}, {
+		name: "allow missing list not found",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--allow-missing-from", "testdata/not-found",
+		},
+		wantErr: errors.New(`error reading --allow-missing-from file "testdata/not-found": open testdata/not-found: no such file or directory`),
+	}, {
+		name: "boilerplate file and literal",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--boilerplate-literal", "// Copyright 2020 Matt Moore",
+			"--file-extension", "mm",
+		},
+		wantErr: ErrBoilerplateConflict,
+	}, {
+		name: "blank boilerplate",
+		args: []string{
+			"--boilerplate", "testdata/blank.txt",
+			"--file-extension", "mm",
+		},
+		wantErr: errors.New(`--boilerplate file "testdata/blank.txt" has only blank lines`),
+	}, {
+		name: "wildcard boilerplate",
+		args: []string{
+			"--boilerplate-literal", "{{*}}\n\n{{*}}",
+			"--file-extension", "mm",
+		},
+		wantErr: errors.New(`--boilerplate-literal has only blank and {{*}} lines, which any file would match`),
+	}, {
+		name: "negative max file size",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--max-file-size", "-1",
+		},
+		wantErr: errors.New(`--max-file-size -1 may not be negative`),
+	}, {
+		name: "group by file with format",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--group-by-file",
+			"--format", "json",
+		},
+		wantErr: ErrGroupWithFormat,
+	}, {
+		name: "count by extension with count",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--count-by-extension",
+			"--count",
+		},
+		wantErr: ErrByExtensionWithCount,
+	}, {
+		name: "count with format",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--count",
+			"--format", "json",
+		},
+		wantErr: ErrCountWithFormat,
+	}, {
+		name: "bad print files",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--print-files", "all",
+		},
+		wantErr: errors.New(`--print-files "all" must be one of: passing, failing`),
+	}, {
+		name: "print files with format",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--print-files", "failing",
+			"--format", "json",
+		},
+		wantErr: ErrPrintFilesWithFormat,
+	}, {
+		name: "print files with fix",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--print-files", "passing",
+			"--fix",
+		},
+		wantErr: ErrPrintFilesWithFix,
+	}, {
+		name: "print files with count",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--print-files", "passing",
+			"--count-files",
+		},
+		wantErr: ErrPrintFilesWithCount,
+	}, {
+		name: "count files with fix",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--count-files",
+			"--fix",
+		},
+		wantErr: ErrCountWithFix,
+	}, {
+		name: "template with spdx",
+		args: []string{
+			"--spdx", "Apache-2.0",
+			"--file-extension", "mm",
+			"--boilerplate-template",
+		},
+		wantErr: ErrTemplateWithSPDX,
+	}, {
+		name: "project without template",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--project", "Matt Moore",
+		},
+		wantErr: ErrProjectRequiresTemplate,
+	}, {
+		name: "bad max header lines",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--max-header-lines", "0",
+		},
+		wantErr: errors.New(`--max-header-lines 0 must be positive`),
+	}, {
+		name: "bad log format",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--log-format", "xml",
+		},
+		wantErr: errors.New(`--log-format "xml" must be one of: text, json`),
+	}, {
+		name: "bad log level",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--log-level", "trace",
+		},
+		wantErr: errors.New(`--log-level "trace" must be one of: debug, info, warn`),
+	}, {
+		name: "negative tab width",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--tab-width", "-4",
+		},
+		wantErr: errors.New(`--tab-width -4 may not be negative`),
+	}, {
+		name: "bad diff context",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--show-diff-context", "-2",
+		},
+		wantErr: errors.New(`--show-diff-context -2 may not be less than -1`),
+	}, {
+		name: "bad anchor",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--anchor", "middle",
+		},
+		wantErr: errors.New(`--anchor "middle" must be one of: first-diff, block-start`),
+	}, {
+		name: "issues exit code out of range",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--issues-exit-code", "256",
+		},
+		wantErr: errors.New(`--issues-exit-code 256 must be between 0 and 255`),
+	}, {
+		name: "issues exit code of errors",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--issues-exit-code", "2",
+		},
+		wantErr: errors.New(`--issues-exit-code 2 may not be 2 or 3, which report errors and timeouts`),
+	}, {
+		name: "bad leading blank policy",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--leading-blank-policy", "collapse",
+		},
+		wantErr: errors.New(`--leading-blank-policy "collapse" must be one of: keep, remove`),
+	}, {
+		name: "block anchor with columns",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--anchor", "block-start",
+			"--columns",
+		},
+		wantErr: ErrBlockAnchorConflict,
+	}, {
+		name: "trailing blank with spdx",
+		args: []string{
+			"--spdx", "Apache-2.0",
+			"--file-extension", "mm",
+			"--require-trailing-blank",
+		},
+		wantErr: ErrTrailingBlankWithSPDX,
+	}, {
+		name: "exact length with spdx",
+		args: []string{
+			"--spdx", "Apache-2.0",
+			"--file-extension", "mm",
+			"--require-exact-length",
+		},
+		wantErr: ErrExactLengthWithSPDX,
+	}, {
+		name: "holder with spdx",
+		args: []string{
+			"--spdx", "Apache-2.0",
+			"--file-extension", "mm",
+			"--require-holder", "Matt Moore",
+		},
+		wantErr: ErrHolderWithSPDX,
+	}, {
+		name: "year range with spdx",
+		args: []string{
+			"--spdx", "Apache-2.0",
+			"--file-extension", "mm",
+			"--year-range", "2015-2025",
+		},
+		wantErr: ErrYearRangeWithSPDX,
+	}, {
+		name: "bad year range",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--year-range", "2025-2015",
+		},
+		wantErr: errors.New(`--year-range "2025-2015" may not end before it starts`),
+	}, {
+		name: "year range without normalizing",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--no-normalize",
+			"--year-range", "2015-2025",
+		},
+		wantErr: ErrNoNormalizeYearConflict,
+	}, {
+		name: "negative max depth",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--max-depth", "-1",
+		},
+		wantErr: errors.New("--max-depth -1 may not be negative"),
+	}, {
+		name: "negative timeout",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--timeout", "-1s",
+		},
+		wantErr: errors.New("--timeout -1s may not be negative"),
+	}, {
+		name: "watch with timeout",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--watch",
+			"--timeout", "1m",
+		},
+		wantErr: ErrWatchWithTimeout,
+	}, {
+		name: "max depth with files from",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--files-from", "-",
+			"--max-depth", "1",
+		},
+		wantErr: ErrMaxDepthWithFilesFrom,
+	}, {
+		name: "go package follows with spdx",
+		args: []string{
+			"--spdx", "Apache-2.0",
+			"--file-extension", "go",
+			"--go-package-follows",
+		},
+		wantErr: ErrGoPackageWithSPDX,
+	}, {
+		name: "opening line with spdx",
+		args: []string{
+			"--spdx", "Apache-2.0",
+			"--file-extension", "go",
+			"--opening-line", `/\* -\*- .* -\*-`,
+		},
+		wantErr: ErrOpeningLineWithSPDX,
+	}, {
+		name: "bad opening line",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--opening-line", "(",
+		},
+		wantErr: errors.New("error compiling --opening-line pattern \"(\": error parsing regexp: missing closing ): `(`"),
+	}, {
+		name: "generated marker with spdx",
+		args: []string{
+			"--spdx", "Apache-2.0",
+			"--file-extension", "go",
+			"--require-generated-marker", "^zz_generated",
+		},
+		wantErr: ErrGeneratedWithSPDX,
+	}, {
+		name: "generated marker without requiring it",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--generated-marker", "GENERATED",
+		},
+		wantErr: ErrMarkerRequiresGenerated,
+	}, {
+		name: "bad generated marker",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--require-generated-marker", "^zz_generated",
+			"--generated-marker", "(",
+		},
+		wantErr: errors.New("error compiling --generated-marker pattern \"(\": error parsing regexp: missing closing ): `(`"),
+	}, {
+		name: "no normalize with collapse year lists",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--no-normalize",
+			"--collapse-year-lists",
+		},
+		wantErr: ErrNoNormalizeYearConflict,
+	}, {
+		name: "bad since",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--since", "01/02/2024",
+		},
+		wantErr: errors.New(`--since "01/02/2024" must be a date, as YYYY-MM-DD, or an RFC 3339 time`),
+	}, {
+		name: "bad max line length",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--max-line-length", "0",
+		},
+		wantErr: errors.New(`--max-line-length 0 must be positive`),
+	}, {
+		name: "bad concurrency",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--concurrency", "0",
+		},
+		wantErr: errors.New(`--concurrency 0 must be positive`),
+	}, {
+		name: "negative max header bytes",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--max-header-bytes", "-1",
+		},
+		wantErr: errors.New(`--max-header-bytes -1 may not be negative`),
+	}, {
+		name: "max header bytes with max header lines",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--max-header-bytes", "4096",
+			"--max-header-lines", "20",
+		},
+		wantErr: ErrHeaderWindowConflict,
+	}, {
+		name: "max header bytes with match anywhere",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--max-header-bytes", "4096",
+			"--match-anywhere",
+		},
+		wantErr: ErrMatchAnywhereWindow,
+	}, {
+		name: "max header lines with match anywhere",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--max-header-lines", "20",
+			"--match-anywhere",
+		},
+		wantErr: ErrMatchAnywhereWindow,
+	}, {
+		name: "duplicate header with spdx",
+		args: []string{
+			"--spdx", "Apache-2.0",
+			"--file-extension", "mm",
+			"--forbid-duplicate-header",
+		},
+		wantErr: ErrDuplicateWithSPDX,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			output := new(bytes.Buffer)
+			cmd.SetOut(output)
+			cmd.SetErr(new(bytes.Buffer))
+
+			cmd.SetArgs(test.args)
+
+			gotErr := cmd.Execute()
+			if (test.wantErr != nil) != (gotErr != nil) {
+				t.Errorf("Execute() = %v, wanted %v", gotErr, test.wantErr)
+			} else if (test.wantErr != nil) && (gotErr != nil) {
+				got, want := gotErr.Error(), test.wantErr.Error()
+				if got != want {
+					t.Errorf("Execute() = %s, wanted %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckBoilerplateWarning(t *testing.T) {
+	for _, ignore := range []bool{false, true} {
+		t.Run(fmt.Sprintf("ignore-trailing-whitespace=%v", ignore), func(t *testing.T) {
+			cmd := NewCheckCommand()
+			stderr := new(bytes.Buffer)
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(stderr)
+			cmd.SetArgs([]string{
+				"--boilerplate", "testdata/trailing.txt",
+				"--file-extension", "mm",
+				"--ignore-trailing-whitespace=" + fmt.Sprint(ignore),
+			})
+			cmd.RunE = nil
+			cmd.Run = func(*cobra.Command, []string) {}
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() = %v", err)
+			}
+			want := ""
+			if !ignore {
+				want = `warning: --boilerplate file "testdata/trailing.txt" has trailing whitespace on line 1, ` +
+					"which headers must match unless --ignore-trailing-whitespace is passed\n"
+			}
+			if got := stderr.String(); got != want {
+				t.Errorf("stderr = %q, wanted %q", got, want)
+			}
+		})
+	}
+}
+
+func TestCheckRunE(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{{
+		name: "with typo mismatch error",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^o].bad.mm",
+		},
+		want: boilerplate.Denormalize(`testdata/typo.bad.mm:2: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "Copyright YYYY Matt Moore"
+	+: "Copyright YYYY Matt More"
+`),
+	}, {
+		name: "with whitespace mismatch error",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^d].bad.mm",
+		},
+		want: `testdata/trimmed.bad.mm:3: found mismatched boilerplate lines:
+{[]string}[0->?]:
+	-: ""
+	+: <non-existent>
+{[]string}[4->?]:
+	-: ""
+	+: <non-existent>
+{[]string}[6->?]:
+	-: ""
+	+: <non-existent>
+{[]string}[?->10]:
+	-: <non-existent>
+	+: ""
+{[]string}[?->11]:
+	-: <non-existent>
+	+: "// Package foo builds widgets"
+{[]string}[?->12]:
+	-: <non-existent>
+	+: "package foo"
+`,
+	}, {
+		name: "with http[s] mismatch error",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^s].bad.mm",
+		},
+		want: `testdata/https.bad.mm:8: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "    http://www.apache.org/licenses/LICENSE-2.0"
+	+: "    https://www.apache.org/licenses/LICENSE-2.0"
+`,
+	}, {
+		name: "with a header matching another boilerplate",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--boilerplate", "testdata/generated.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^n].bad.mm",
+		},
+	}, {
+		name: "with a generated code marker required",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--boilerplate", "testdata/generated.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^n].bad.mm",
+			"--require-generated-marker", `gen\.bad\.mm$`,
+		},
+	}, {
+		name: "with another generated code marker required",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--boilerplate", "testdata/generated.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^n].bad.mm",
+			"--require-generated-marker", `gen\.bad\.mm$`,
+			"--generated-marker", "DO NOT EDIT BY HAND",
+		},
+		want: `testdata/gen.bad.mm:1: missing generated code marker: no line matches DO NOT EDIT BY HAND
+`,
+	}, {
+		name: "with the closest of several boilerplates",
+		args: []string{
+			"--boilerplate", "testdata/generated.mm.txt",
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^o].bad.mm",
+		},
+		want: boilerplate.Denormalize(`testdata/typo.bad.mm:2: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "Copyright YYYY Matt Moore"
+	+: "Copyright YYYY Matt More"
+`),
+	}, {
+		name: "with stacked headers",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^p].bad.mm",
+		},
+	}, {
+		name: "with stacked headers forbidden",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^p].bad.mm",
+			"--forbid-duplicate-header",
+		},
+		want: "testdata/dup.bad.mm:17: duplicate boilerplate: the boilerplate already starts at line 1\n",
+	}, {
+		name: "with a file pattern",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-pattern", "Mm*",
+		},
+		want: boilerplate.Denormalize(`testdata/Mmfile:2: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "Copyright YYYY Matt Moore"
+	+: "Copyright YYYY Matt More"
+`),
+	}, {
+		name: "with columns",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^s].bad.mm",
+			"--columns",
+		},
+		want: `testdata/https.bad.mm:8:9: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "    http://www.apache.org/licenses/LICENSE-2.0"
+	+: "    https://www.apache.org/licenses/LICENSE-2.0"
+`,
+	}, {
+		name: "with diff context",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^s].bad.mm",
+			"--show-diff-context", "1",
+		},
+		want: `testdata/https.bad.mm:8: found mismatched boilerplate lines:
+@@ -7,3 +7,3 @@
+ 
+-    http://www.apache.org/licenses/LICENSE-2.0
++    https://www.apache.org/licenses/LICENSE-2.0
+ 
+`,
+	}, {
+		name: "with diff context anchored at the header's start",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^s].bad.mm",
+			"--show-diff-context", "1",
+			"--anchor", "block-start",
+		},
+		want: `testdata/https.bad.mm:1: found mismatched boilerplate lines:
+@@ -7,3 +7,3 @@
+ 
+-    http://www.apache.org/licenses/LICENSE-2.0
++    https://www.apache.org/licenses/LICENSE-2.0
+ 
+`,
+	}, {
+		name: "with gzip compressed files",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "gz",
+			"--decompress", "gzip",
+		},
+		want: boilerplate.Denormalize(`testdata/typo.bad.mm.gz:2: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "Copyright YYYY Matt Moore"
+	+: "Copyright YYYY Matt More"
+`),
+	}, {
+		name: "with uncompressed files to decompress",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^o].bad.mm|good.mm",
+			"--decompress", "gzip",
+		},
+		want: "testdata/typo.bad.mm: could not read: gzip: invalid header\n",
+	}, {
+		name: "with a forbidden boilerplate",
+		args: []string{
+			"--forbid", "testdata/forbidden.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^o].bad.mm",
+		},
+		want: "testdata/typo.bad.mm:1: found forbidden boilerplate: lines 1 through 16\n",
+	}, {
+		name: "with both required and forbidden boilerplate",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--forbid", "testdata/forbidden.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^o].bad.mm",
+		},
+		want: boilerplate.Denormalize(`testdata/typo.bad.mm:1: found forbidden boilerplate: lines 1 through 16
+testdata/typo.bad.mm:2: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "Copyright YYYY Matt Moore"
+	+: "Copyright YYYY Matt More"
+`),
+	}, {
+		name: "with a template",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.tmpl",
+			"--file-extension", "mm",
+			"--exclude", "[^o].bad.mm",
+			"--boilerplate-template",
+			"--project", "Matt Moore",
+		},
+		want: boilerplate.Denormalize(`testdata/typo.bad.mm:2: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "Copyright YYYY Matt Moore"
+	+: "Copyright YYYY Matt More"
+`),
+	}, {
+		name: "with all mismatches",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^i].bad.mm",
+			"--report-all-mismatches",
+		},
+		want: boilerplate.Denormalize(`testdata/multi.bad.mm:2: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "Copyright YYYY Matt Moore"
+	+: "Copyright YYYY Matt More"
+testdata/multi.bad.mm:5: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "you may not use this file except in compliance with the License."
+	+: "you may not use this file expect in compliance with the License."
+`),
+	}, {
+		name: "with tab/space mismatch error",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^b].bad.mm",
+		},
+		want: `testdata/tab.bad.mm:8: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "    http://www.apache.org/licenses/LICENSE-2.0"
+	+: "\thttp://www.apache.org/licenses/LICENSE-2.0"
+`,
+	}, {
+		name: "with tab/space mismatch ignored",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^b].bad.mm",
+			"--ignore-leading-whitespace",
+		},
+	}, {
+		name: "with tab/space mismatch and a tab width",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^b].bad.mm",
+			"--tab-width", "4",
+		},
+	}, {
+		name: "with case mismatch ignored",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^r].bad.mm",
+			"--ignore-case",
+		},
+	}, {
+		name: "with smart quotes",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^y].bad.mm",
+		},
+		want: "testdata/curly.bad.mm:4: found mismatched boilerplate lines:\n" +
+			"{[]string}[0]:\n" +
+			"\t-: `Licensed under the Apache License, Version 2.0 (the \"License\");`\n" +
+			"\t+: \"Licensed under the Apache License, Version 2.0 (the “License”);\"\n",
+	}, {
+		name: "with smart quotes normalized",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^y].bad.mm",
+			"--normalize-unicode",
+		},
+	}, {
+		name: "with an alias for a former copyright holder",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^o].bad.mm",
+			"--alias", "Matt More=Matt Moore",
+		},
+	}, {
+		name: "with an alias for a former copyright holder, requiring the holder",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^o].bad.mm",
+			"--alias", "Matt More=Matt Moore",
+			"--require-holder", "Matt Moore",
+		},
+		want: `testdata/typo.bad.mm:2: wrong copyright holder: found "Matt More", expected "Matt Moore"
+`,
+	}, {
+		name: "with a wildcard line",
+		args: []string{
+			"--boilerplate", "testdata/wildcard.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^o].bad.mm",
+		},
+	}, {
+		name: "with list of years",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^a].bad.mm",
+		},
+		want: boilerplate.Denormalize(`testdata/comma.bad.mm:2: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "Copyright YYYY Matt Moore"
+	+: "Copyright YYYY, YYYY, YYYY Matt Moore"
+`),
+	}, {
+		name: "with years not normalized",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^d].good.mm|bad.mm",
+			"--no-normalize",
+		},
+		want: `testdata/old.good.mm:2: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "Copyright 2020 Matt Moore"
+	+: "Copyright 2019 Matt Moore"
+`,
+	}, {
+		name: "with list of years collapsed",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^a].bad.mm",
+			"--collapse-year-lists",
+		},
+	}, {
+		name: "with list of years collapsed, requiring the holder",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^a].bad.mm",
+			"--collapse-year-lists",
+			"--require-holder", "Matt Moore",
+		},
+	}, {
+		name: "with header after a stray comment within the bytes",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^y].good.mm|bad.mm",
+			"--max-header-bytes", "36",
+		},
+	}, {
+		name: "with header not at top",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^y].good.mm|bad.mm",
+			"--require-at-top",
+		},
+		want: "testdata/stray.good.mm:1: boilerplate is not at the top of the file: line 1 precedes the boilerplate at line 4\n",
+	}, {
+		name: "with spdx identifier",
+		args: []string{
+			"--spdx", "Apache-2.0",
+			"--copyright-pattern", `^// Copyright \d{4} Matt Moore$`,
+			"--file-extension", "mm",
+			"--exclude", "[^x].bad.mm|good.mm",
+		},
+	}, {
+		name: "with spdx identifier mismatch",
+		args: []string{
+			"--spdx", "MIT",
+			"--file-extension", "mm",
+			"--exclude", "[^x].bad.mm|good.mm",
+		},
+		want: `testdata/spdx.bad.mm:1: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "MIT"
+	+: "Apache-2.0"
+`,
+	}, {
+		name: "with outdated year",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "bad.mm|[^d].good.mm",
+			"--require-current-year",
+		},
+		want: boilerplate.Denormalize("testdata/old.good.mm:2: copyright year is out of date: found 2019, expected YYYY\n"),
+	}, {
+		name: "with missing boilerplate allowed by pattern",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^g].bad.mm",
+			"--allow-missing", "/missing",
+		},
+	}, {
+		name: "with missing boilerplate allowed by list",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^g].bad.mm",
+			"--allow-missing-from", "testdata/allow-missing.txt",
+		},
+	}, {
+		name: "with mismatch in a file allowed to lack boilerplate",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^s].bad.mm",
+			"--allow-missing", "https",
+		},
+		want: `testdata/https.bad.mm:8: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "    http://www.apache.org/licenses/LICENSE-2.0"
+	+: "    https://www.apache.org/licenses/LICENSE-2.0"
+`,
+	}, {
+		name: "with too short error",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^t].bad.mm",
+		},
+		want: `testdata/short.bad.mm:1: incomplete boilerplate, missing:
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+`,
+	}, {
+		name: "with no header error",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^g].bad.mm",
+		},
+		want: boilerplate.Denormalize(`testdata/missing.bad.mm:1: missing boilerplate:
+/*
+Copyright YYYY Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+`),
+	}, {
+		name: "with byte order mark",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", ".bad.mm",
+		},
+		want: "",
+	}, {
+		name: "with a root",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^o].bad.mm",
+			"--root", "testdata",
+		},
+		want: boilerplate.Denormalize(`typo.bad.mm:2: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "Copyright YYYY Matt Moore"
+	+: "Copyright YYYY Matt More"
+`),
+	}, {
+		name: "with leading build tags",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^e].bad.mm",
+		},
+		want: boilerplate.Denormalize(`testdata/prologue.bad.mm:1: missing boilerplate:
+/*
+Copyright YYYY Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+`),
+	}, {
+		name: "with leading build tags allowed",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^e].bad.mm",
+			"--allow-leading-lines",
+		},
+		want: "",
+	}, {
+		name: "with shebang and no header",
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^h].bad.mm",
+			"--allow-leading-lines",
+		},
+		want: boilerplate.Denormalize(`testdata/bash.bad.mm:3: missing boilerplate:
+/*
+Copyright YYYY Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+`),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			output := new(bytes.Buffer)
+			cmd.SetOut(output)
+			cmd.SetErr(new(bytes.Buffer))
+
+			cmd.SetArgs(test.args)
+
+			// Only runs that report violations should fail.
+			wantCode := 0
+			if test.want != "" {
+				wantCode = ExitViolations
+			}
+			if err := cmd.Execute(); ExitCode(err) != wantCode {
+				t.Errorf("Execute() = %v, wanted exit code %d", err, wantCode)
+			}
+
+			got := output.String()
+			if test.want != got {
+				t.Errorf("Execute() = %s, wanted %s", got, test.want)
+			}
+		})
+	}
+}
+
+// summaryFiles are the files checked by TestCheckSummary.
+const summaryFiles = `testdata/typo.bad.mm
+testdata/short.bad.mm
+testdata/old.good.mm
+testdata/tag.good.mm
+testdata/bom.good.mm
+`
+
+func TestCheckSummary(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantOut string
+		wantErr string
+	}{{
+		name: "text summary",
+		args: []string{"--exclude", "short"},
+		wantOut: boilerplate.Denormalize(`testdata/typo.bad.mm:2: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "Copyright YYYY Matt Moore"
+	+: "Copyright YYYY Matt More"
+`),
+		wantErr: "checked 4 files, 1 violations in 1 files\n",
+	}, {
+		name:    "quiet",
+		args:    []string{"--exclude", "short", "--quiet"},
+		wantOut: "",
+		wantErr: "checked 4 files, 1 violations in 1 files\n",
+	}, {
+		name:    "count by extension",
+		args:    []string{"--exclude", "short", "--quiet", "--count-by-extension"},
+		wantErr: "checked 4 files, 1 violations in 1 files\n  mm: 1\n",
+	}, {
+		name: "quiet without summary",
+		args: []string{"--exclude", "short", "--quiet", "--no-summary"},
+	}, {
+		name: "json count by extension",
+		args: []string{"--exclude", "short", "--format", "json", "--count-by-extension"},
+		wantOut: `{"violations":[{"path":"testdata/typo.bad.mm","line":2,"boilerplateLine":2,"kind":"mismatch","severity":"error","detail":` +
+			fmt.Sprintf("%q", boilerplate.Denormalize(`{[]string}[0]:
+	-: "Copyright YYYY Matt Moore"
+	+: "Copyright YYYY Matt More"
+`)) + `}],"summary":{"checked":4,"passed":3,"failed":1,"violations":1,"byExtension":{"mm":1}}}` + "\n",
+	}, {
+		name: "json",
+		args: []string{"--exclude", "short", "--format", "json"},
+		wantOut: `{"violations":[{"path":"testdata/typo.bad.mm","line":2,"boilerplateLine":2,"kind":"mismatch","severity":"error","detail":` +
+			fmt.Sprintf("%q", boilerplate.Denormalize(`{[]string}[0]:
+	-: "Copyright YYYY Matt Moore"
+	+: "Copyright YYYY Matt More"
+`)) + `}],"summary":{"checked":4,"passed":3,"failed":1,"violations":1}}` + "\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(stderr)
+			cmd.SetIn(strings.NewReader(summaryFiles))
+			cmd.SetArgs(append([]string{
+				"--boilerplate", "testdata/boilerplate.mm.txt",
+				"--file-extension", "mm",
+				"--files-from", "-",
+			}, test.args...))
+
+			if err := cmd.Execute(); ExitCode(err) != ExitViolations {
+				t.Errorf("Execute() = %v, wanted exit code %d", err, ExitViolations)
+			}
+			if got := stdout.String(); got != test.wantOut {
+				t.Errorf("stdout = %s, wanted %s", got, test.wantOut)
+			}
+			if got := stderr.String(); got != test.wantErr {
+				t.Errorf("stderr = %s, wanted %s", got, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckSeverity(t *testing.T) {
+	warned := boilerplate.Denormalize(`testdata/typo.bad.mm:2: warning: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "Copyright YYYY Matt Moore"
+	+: "Copyright YYYY Matt More"
+`)
+	tests := []struct {
+		name     string
+		args     []string
+		wantOut  string
+		wantErr  string
+		wantCode int
+	}{{
+		name:    "warn only",
+		args:    []string{"--warn-only"},
+		wantOut: warned,
+		wantErr: "checked 4 files, 0 violations in 0 files, 1 warnings\n",
+	}, {
+		name:    "mismatch is a warning",
+		args:    []string{"--severity", "missing=error,mismatch=warning"},
+		wantOut: warned,
+		wantErr: "checked 4 files, 0 violations in 0 files, 1 warnings\n",
+	}, {
+		name:     "the last severity of a kind wins",
+		args:     []string{"--severity", "mismatch=warning", "--severity", "mismatch=error", "--quiet"},
+		wantErr:  "checked 4 files, 1 violations in 1 files\n",
+		wantCode: ExitViolations,
+	}, {
+		name:    "warn only despite severity",
+		args:    []string{"--severity", "mismatch=error", "--warn-only", "--quiet"},
+		wantErr: "checked 4 files, 0 violations in 0 files, 1 warnings\n",
+	}, {
+		name:     "issues exit code",
+		args:     []string{"--issues-exit-code", "7", "--quiet"},
+		wantErr:  "checked 4 files, 1 violations in 1 files\n",
+		wantCode: 7,
+	}, {
+		name:    "issues exit code of warnings",
+		args:    []string{"--issues-exit-code", "7", "--warn-only", "--quiet"},
+		wantErr: "checked 4 files, 0 violations in 0 files, 1 warnings\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(stderr)
+			cmd.SetIn(strings.NewReader(summaryFiles))
+			cmd.SetArgs(append([]string{
+				"--boilerplate", "testdata/boilerplate.mm.txt",
+				"--file-extension", "mm",
+				"--files-from", "-",
+				"--exclude", "short",
+			}, test.args...))
+
+			if err := cmd.Execute(); ExitCode(err) != test.wantCode {
+				t.Errorf("Execute() = %v, wanted exit code %d", err, test.wantCode)
+			}
+			if got := stdout.String(); got != test.wantOut {
+				t.Errorf("stdout = %s, wanted %s", got, test.wantOut)
+			}
+			if got := stderr.String(); got != test.wantErr {
+				t.Errorf("stderr = %s, wanted %s", got, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckCount(t *testing.T) {
+	tests := []struct {
+		flag string
+		want string
+	}{{
+		flag: "--count",
+		want: "3\n",
+	}, {
+		flag: "--count-files",
+		want: "2\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.flag, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(stderr)
+			cmd.SetIn(strings.NewReader("testdata/multi.bad.mm\ntestdata/typo.bad.mm\ntestdata/old.good.mm\n"))
+			cmd.SetArgs([]string{
+				"--boilerplate", "testdata/boilerplate.mm.txt",
+				"--file-extension", "mm",
+				"--files-from", "-",
+				"--report-all-mismatches",
+				test.flag,
+			})
+
+			if err := cmd.Execute(); err != nil {
+				t.Errorf("Execute() = %v", err)
+			}
+			if got := stdout.String(); got != test.want {
+				t.Errorf("stdout = %q, wanted %q", got, test.want)
+			}
+			if got := stderr.String(); got != "" {
+				t.Errorf("stderr = %q, wanted none", got)
+			}
+		})
+	}
+}
+
+func TestCheckLongLines(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	// Minified files have no header, but one enormous line.
+	content := "package foo\n" + strings.Repeat("x", 100000) + "\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "min.mm"), []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{{
+		name: "by default",
+		want: "min.mm:1: missing boilerplate:\n",
+	}, {
+		name: "longer than --max-line-length",
+		args: []string{"--max-line-length", "65536"},
+		want: "min.mm: could not read: line 2 is longer than 65536 bytes\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			stdout := new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs(append([]string{
+				"--boilerplate", "testdata/boilerplate.mm.txt",
+				"--file-extension", "mm",
+				"--root", dir,
+			}, test.args...))
+
+			if err := cmd.Execute(); ExitCode(err) != ExitViolations {
+				t.Errorf("Execute() = %v, wanted exit code %d", err, ExitViolations)
+			}
+			if got := stdout.String(); !strings.HasPrefix(got, test.want) {
+				t.Errorf("stdout = %q, wanted prefix %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckPrintFiles(t *testing.T) {
+	tests := []struct {
+		mode string
+		want string
+	}{{
+		mode: "passing",
+		want: "testdata/old.good.mm\ntestdata/tag.good.mm\n",
+	}, {
+		mode: "failing",
+		want: "testdata/multi.bad.mm\ntestdata/typo.bad.mm\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.mode, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(stderr)
+			cmd.SetIn(strings.NewReader("testdata/multi.bad.mm\ntestdata/old.good.mm\ntestdata/boilerplate.mm.txt\n" +
+				"testdata/typo.bad.mm\ntestdata/short.bad.mm\ntestdata/tag.good.mm\n"))
+			cmd.SetArgs([]string{
+				"--boilerplate", "testdata/boilerplate.mm.txt",
+				"--file-extension", "mm",
+				"--exclude", "short",
+				"--files-from", "-",
+				"--print-files", test.mode,
+			})
+
+			if err := cmd.Execute(); err != nil {
+				t.Errorf("Execute() = %v", err)
+			}
+			if got := stdout.String(); got != test.want {
+				t.Errorf("stdout = %q, wanted %q", got, test.want)
+			}
+			if got := stderr.String(); got != "" {
+				t.Errorf("stderr = %q, wanted none", got)
+			}
+		})
+	}
+}
+
+func TestCheckFilesFrom(t *testing.T) {
+	tests := []struct {
+		name  string
+		flag  string
+		input string
+	}{{
+		name:  "newline delimited",
+		flag:  "--files-from",
+		input: "testdata/typo.bad.mm\ntestdata/short.bad.mm\n\ntestdata/old.good.mm\ntestdata/empty.txt\n",
+	}, {
+		name:  "NUL delimited",
+		flag:  "--files-from0",
+		input: "testdata/typo.bad.mm\x00testdata/short.bad.mm\x00testdata/old.good.mm\x00testdata/empty.txt",
+	}}
+
+	want := boilerplate.Denormalize(`testdata/typo.bad.mm:2: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "Copyright YYYY Matt Moore"
+	+: "Copyright YYYY Matt More"
+`)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			output := new(bytes.Buffer)
+			cmd.SetOut(output)
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetIn(strings.NewReader(test.input))
+			cmd.SetArgs([]string{
+				"--boilerplate", "testdata/boilerplate.mm.txt",
+				"--file-extension", "mm",
+				"--exclude", "short",
+				test.flag, "-",
+			})
+
+			if err := cmd.Execute(); ExitCode(err) != ExitViolations {
+				t.Errorf("Execute() = %v, wanted exit code %d", err, ExitViolations)
+			}
+			if got := output.String(); got != want {
+				t.Errorf("Execute() = %s, wanted %s", got, want)
+			}
+		})
+	}
+}
+
+func TestCheckGitHubActions(t *testing.T) {
+	os.Setenv("GITHUB_ACTIONS", "true")
+	defer os.Unsetenv("GITHUB_ACTIONS")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{{
+		name: "default format",
+		want: boilerplate.Denormalize(`::error file=testdata/typo.bad.mm,line=2::found mismatched boilerplate lines:%0A` +
+			"{[]string}[0]:%0A\t-: \"Copyright YYYY Matt Moore\"%0A\t+: \"Copyright YYYY Matt More\"\n"),
+	}, {
+		name: "explicit format",
+		args: []string{"--format", "text"},
+		want: boilerplate.Denormalize(`testdata/typo.bad.mm:2: found mismatched boilerplate lines:
+{[]string}[0]:
+	-: "Copyright YYYY Matt Moore"
+	+: "Copyright YYYY Matt More"
+`),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			stdout := new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs(append([]string{
+				"--boilerplate", "testdata/boilerplate.mm.txt",
+				"--file-extension", "mm",
+				"--exclude", "[^o].bad.mm",
+			}, test.args...))
+
+			if err := cmd.Execute(); ExitCode(err) != ExitViolations {
+				t.Errorf("Execute() = %v, wanted exit code %d", err, ExitViolations)
+			}
+			if got := stdout.String(); got != test.want {
+				t.Errorf("stdout = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckMaxFileSize(t *testing.T) {
+	info, err := os.Stat("testdata/typo.bad.mm")
+	if err != nil {
+		t.Fatalf("Stat() = %v", err)
+	}
+
+	cmd := NewCheckCommand()
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.SetIn(strings.NewReader("testdata/typo.bad.mm\ntestdata/tag.good.mm\n"))
+	cmd.SetArgs([]string{
+		"--boilerplate", "testdata/boilerplate.mm.txt",
+		"--file-extension", "mm",
+		"--files-from", "-",
+		"--max-file-size", fmt.Sprint(info.Size() - 1),
+	})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("Execute() = %v", err)
+	}
+	if got := stdout.String(); got != "" {
+		t.Errorf("stdout = %s, wanted none", got)
+	}
+	want := fmt.Sprintf("warning: testdata/typo.bad.mm: skipped: %d bytes is larger than --max-file-size %d\n",
+		info.Size(), info.Size()-1)
+	if got := stderr.String(); !strings.HasPrefix(got, want) {
+		t.Errorf("stderr = %s, wanted prefix %q", got, want)
+	}
+}
+
+func TestCheckOverrides(t *testing.T) {
+	apache, err := ioutil.ReadFile("testdata/old.good.mm")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	generated, err := ioutil.ReadFile("testdata/gen.bad.mm")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	override, err := ioutil.ReadFile("testdata/generated.mm.txt")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	for path, content := range map[string][]byte{
+		// Overrides above the root are not consulted.
+		".boilerplate":                 []byte("// Copyright 2020 Someone Else\n"),
+		"root/apache.mm":               apache,
+		"root/gen/.boilerplate":        override,
+		"root/gen/generated.mm":        generated,
+		"root/gen/apache.mm":           apache,
+		"root/gen/deeper/generated.mm": generated,
+		"root/gen/other/.boilerplate":  []byte("// Copyright 2020 Someone Else\n"),
+		"root/gen/other/generated.mm":  generated,
+	} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll() = %v", err)
+		}
+		if err := ioutil.WriteFile(path, content, 0644); err != nil {
+			t.Fatalf("WriteFile() = %v", err)
+		}
+	}
+
+	cmd := NewCheckCommand()
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"--boilerplate", "testdata/boilerplate.mm.txt",
+		"--file-extension", "mm",
+		"--root", filepath.Join(dir, "root"),
+		"--quiet",
+		"--format", "json",
+	})
+	if err := cmd.Execute(); ExitCode(err) != ExitViolations {
+		t.Errorf("Execute() = %v, wanted exit code %d", err, ExitViolations)
+	}
+
+	var got struct {
+		Violations []struct {
+			Path string `json:"path"`
+			Kind string `json:"kind"`
+		} `json:"violations"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	var paths []string
+	for _, v := range got.Violations {
+		paths = append(paths, v.Path+": "+v.Kind)
+	}
+	want := []string{
+		"gen/apache.mm: missing",
+		"gen/other/generated.mm: missing",
+	}
+	if !cmp.Equal(paths, want) {
+		t.Errorf("violations = %v, wanted %v", paths, want)
+	}
+}
+
+func TestCheckVerbose(t *testing.T) {
+	cmd := NewCheckCommand()
+	stderr := new(bytes.Buffer)
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(stderr)
+	cmd.SetArgs([]string{
+		"--boilerplate", "testdata/boilerplate.mm.txt",
+		"--file-extension", "mm",
+		"--exclude", "[^o].bad.mm",
+		"--verbose",
+	})
+
+	if err := cmd.Execute(); ExitCode(err) != ExitViolations {
+		t.Errorf("Execute() = %v, wanted exit code %d", err, ExitViolations)
+	}
+
+	got := stderr.String()
+	for _, want := range []string{
+		"testdata: directory\n",
+		"testdata/typo.bad.mm: checked\n",
+		"testdata/old.good.mm: checked\n",
+		"testdata/short.bad.mm: skipped: exclude [^o].bad.mm\n",
+		"testdata/boilerplate.mm.txt: skipped: extension\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("stderr = %s, wanted substring %q", got, want)
+		}
+	}
+}
+
+func TestCheckFailFast(t *testing.T) {
+	cmd := NewCheckCommand()
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.SetArgs([]string{
+		"--boilerplate", "testdata/boilerplate.mm.txt",
+		"--file-extension", "mm",
+		"--exclude", "[^os].bad.mm",
+		"--fail-fast",
+	})
+
+	if err := cmd.Execute(); ExitCode(err) != ExitViolations {
+		t.Errorf("Execute() = %v, wanted exit code %d", err, ExitViolations)
+	}
+	// The walk stops at https.bad.mm, before typo.bad.mm.
+	if got := stdout.String(); !strings.HasPrefix(got, "testdata/https.bad.mm:") || strings.Contains(got, "typo.bad.mm") {
+		t.Errorf("stdout = %q, wanted only the violations of https.bad.mm", got)
+	}
+	if got, want := stderr.String(), "checked 2 files, 1 violations in 1 files\n"; got != want {
+		t.Errorf("stderr = %q, wanted %q", got, want)
+	}
+}
+
+func TestCheckRequireComment(t *testing.T) {
+	tests := []struct {
+		style string
+		want  string
+		code  int
+	}{{
+		style: "mm=//,/*,*/",
+		want:  "",
+	}, {
+		// The boilerplate's /* is not a comment in this style.
+		style: "mm=#",
+		want:  "testdata/old.good.mm:1: boilerplate is not in a comment: /*\n",
+		code:  ExitViolations,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.style, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			stdout := new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs([]string{
+				"--boilerplate", "testdata/boilerplate.mm.txt",
+				"--file-extension", "mm",
+				"--exclude", "(bad|bom|stray|tag)",
+				"--require-comment",
+				"--comment-style", test.style,
+			})
+
+			err := cmd.Execute()
+			if got := stdout.String(); got != test.want {
+				t.Errorf("stdout = %q, wanted %q", got, test.want)
+			}
+			if ExitCode(err) != test.code {
+				t.Errorf("Execute() = %v, wanted exit code %d", err, test.code)
+			}
+		})
+	}
+}
+
+func TestCheckLicense(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+		code int
+	}{{
+		name: "any holder",
+		args: []string{"--license", "Apache-2.0"},
+	}, {
+		name: "project",
+		args: []string{"--license", "Apache-2.0", "--project", "Matt Moore"},
+	}, {
+		name: "other license",
+		args: []string{"--license", "MIT", "--project", "Matt Moore", "--exclude", "(bad|bom|stray|tag|typo)"},
+		want: "testdata/old.good.mm:1: incomplete boilerplate, missing:\n",
+		code: ExitViolations,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			stdout := new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(new(bytes.Buffer))
+			args := []string{
+				"--file-extension", "mm",
+				"--comment-style", "mm=//,/*,*/",
+				"--exclude", "(bad|bom|stray|tag)",
+			}
+			cmd.SetArgs(append(args, test.args...))
+
+			err := cmd.Execute()
+			// The rest of the message is the text of the license.
+			if got := stdout.String(); !strings.HasPrefix(got, test.want) || (got != "") != (test.want != "") {
+				t.Errorf("stdout = %q, wanted it to start with %q", got, test.want)
+			}
+			if ExitCode(err) != test.code {
+				t.Errorf("Execute() = %v, wanted exit code %d", err, test.code)
+			}
+		})
+	}
+}
+
+func TestCheckLicenseLineComments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	bts, err := ioutil.ReadFile("testdata/old.good.mm")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	// The header of old.good.mm, in line comments instead of a block.
+	lines := strings.Split(string(bts), "\n")
+	var header strings.Builder
+	for _, line := range lines[1:14] {
+		header.WriteString(strings.TrimSpace("// "+line) + "\n")
+	}
+	writeFiles(t, dir, map[string]string{
+		"block.go": string(bts),
+		"line.go":  header.String() + "\npackage foo\n",
+	})
+
+	cmd := NewCheckCommand()
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.SetArgs([]string{
+		"--root", dir,
+		"--license", "Apache-2.0",
+		"--file-extension", "go",
+	})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("Execute() = %v, stdout = %s", err, stdout)
+	}
+	if got, want := stderr.String(), "checked 2 files, 0 violations in 0 files\n"; got != want {
+		t.Errorf("stderr = %q, wanted %q", got, want)
+	}
+}
+
+func TestCheckFailOnNoMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		ext      string
+		wantErr  error
+		wantCode int
+	}{{
+		name:     "matches",
+		ext:      "mm",
+		wantCode: ExitViolations,
+	}, {
+		name:     "no matches",
+		ext:      "mmm",
+		wantErr:  ErrNoMatches,
+		wantCode: ExitError,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs([]string{
+				"--boilerplate", "testdata/boilerplate.mm.txt",
+				"--file-extension", test.ext,
+				"--fail-on-no-matches",
+			})
+
+			err := cmd.Execute()
+			if ExitCode(err) != test.wantCode {
+				t.Errorf("Execute() = %v, wanted exit code %d", err, test.wantCode)
+			}
+			if test.wantErr != nil && err != test.wantErr {
+				t.Errorf("Execute() = %v, wanted %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckExcludeDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	for _, path := range []string{"vendor/a.mm", "vendored/b.mm", "pkg/gen/c.mm", "pkg/lib/d.mm"} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll() = %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte("package foo\n"), 0644); err != nil {
+			t.Fatalf("WriteFile() = %v", err)
+		}
+	}
+	list := filepath.Join(dir, "files.txt")
+	if err := ioutil.WriteFile(list, []byte(filepath.Join(dir, "vendor/a.mm")+"\n"+filepath.Join(dir, "vendored/b.mm")+"\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{{
+		name: "by name",
+		args: []string{"--root", dir, "--exclude-dir", "vendor"},
+		want: "pkg/gen/c.mm\npkg/lib/d.mm\nvendored/b.mm\n",
+	}, {
+		name: "by path",
+		args: []string{"--root", dir, "--exclude-dir", "pkg/g.*"},
+		want: "pkg/lib/d.mm\nvendor/a.mm\nvendored/b.mm\n",
+	}, {
+		name: "repeated",
+		args: []string{"--root", dir, "--exclude-dir", "vendor", "--exclude-dir", "vendored"},
+		want: "pkg/gen/c.mm\npkg/lib/d.mm\n",
+	}, {
+		name: "with files-from",
+		args: []string{"--files-from", list, "--exclude-dir", "vendor"},
+		want: filepath.Join(dir, "vendored/b.mm") + "\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			stdout := new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs(append([]string{
+				"--boilerplate", "testdata/boilerplate.mm.txt",
+				"--file-extension", "mm",
+				"--print-files", "failing",
+			}, test.args...))
+
+			if err := cmd.Execute(); err != nil {
+				t.Errorf("Execute() = %v", err)
+			}
+			if got := stdout.String(); got != test.want {
+				t.Errorf("stdout = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckVerboseFound(t *testing.T) {
+	cmd := NewCheckCommand()
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"--boilerplate", "testdata/boilerplate.mm.txt",
+		"--file-extension", "mm",
+		"--exclude", "[^g].bad.mm",
+		"--verbose",
+	})
+
+	if err := cmd.Execute(); ExitCode(err) != ExitViolations {
+		t.Errorf("Execute() = %v, wanted exit code %d", err, ExitViolations)
+	}
+	if got, want := stdout.String(), "found instead:\n1 | package testdata\n"; !strings.HasSuffix(got, want) {
+		t.Errorf("stdout = %q, wanted suffix %q", got, want)
+	}
+}
+
+func TestCheckLogFormat(t *testing.T) {
+	cmd := NewCheckCommand()
+	stderr := new(bytes.Buffer)
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(stderr)
+	cmd.SetArgs([]string{
+		"--boilerplate", "testdata/boilerplate.mm.txt",
+		"--file-extension", "mm",
+		"--exclude", "[^o].bad.mm",
+		"--log-format", "json",
+		"--log-level", "debug",
+		"--no-summary",
+	})
+
+	if err := cmd.Execute(); ExitCode(err) != ExitViolations {
+		t.Errorf("Execute() = %v, wanted exit code %d", err, ExitViolations)
+	}
+
+	// Every line of stderr is a log entry.
+	logs := stderr.String()
+	found := make(map[logEntry]bool)
+	dec := json.NewDecoder(strings.NewReader(logs))
+	for dec.More() {
+		var entry logEntry
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatalf("Decode() = %v", err)
+		}
+		entry.Time = ""
+		if strings.HasPrefix(entry.Message, "checked ") {
+			entry.Message = "checked files"
+		}
+		found[entry] = true
+	}
+	for _, want := range []logEntry{
+		{Level: "debug", Path: "testdata/typo.bad.mm", Message: "checked"},
+		{Level: "debug", Path: "testdata/short.bad.mm", Message: "skipped: exclude [^o].bad.mm"},
+		{Level: "info", Message: "checked files"},
+	} {
+		if !found[want] {
+			t.Errorf("stderr = %s, wanted entry %+v", logs, want)
+		}
+	}
+}
+
+// slowReader reads from r once delay has passed, like the output of a
+// slow command.
+type slowReader struct {
+	delay time.Duration
+	r     io.Reader
+}
+
+func (sr *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(sr.delay)
+	sr.delay = 0
+	return sr.r.Read(p)
+}
+
+func TestCheckTimeout(t *testing.T) {
+	cmd := NewCheckCommand()
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	// The second file is listed only after the timeout.
+	cmd.SetIn(io.MultiReader(
+		strings.NewReader("testdata/typo.bad.mm\n"),
+		&slowReader{delay: 500 * time.Millisecond, r: strings.NewReader("testdata/short.bad.mm\n")}))
+	cmd.SetArgs([]string{
+		"--boilerplate", "testdata/boilerplate.mm.txt",
+		"--file-extension", "mm",
+		"--files-from", "-",
+		"--timeout", "100ms",
+		"--quiet",
+	})
+
+	if err := cmd.Execute(); ExitCode(err) != ExitTimeout {
+		t.Errorf("Execute() = %v, wanted exit code %d", err, ExitTimeout)
+	}
+	want := "warning: timed out after 100ms, per --timeout, so files may be left unchecked\n" +
+		"checked 1 files, 1 violations in 1 files\n"
+	if got := stderr.String(); got != want {
+		t.Errorf("stderr = %q, wanted %q", got, want)
+	}
+}
+
+func TestCheckUnreadable(t *testing.T) {
+	good, err := filepath.Abs("testdata/old.good.mm")
+	if err != nil {
+		t.Fatalf("Abs() = %v", err)
+	}
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Symlink(good, filepath.Join(dir, "link.mm")); err != nil {
+		t.Fatalf("Symlink() = %v", err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "nowhere"), filepath.Join(dir, "broken.mm")); err != nil {
+		t.Fatalf("Symlink() = %v", err)
+	}
+
+	for _, failOnError := range []bool{false, true} {
+		t.Run(fmt.Sprintf("fail-on-error=%v", failOnError), func(t *testing.T) {
+			cmd := NewCheckCommand()
+			stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(stderr)
+			cmd.SetArgs([]string{
+				"--boilerplate", "testdata/boilerplate.mm.txt",
+				"--file-extension", "mm",
+				"--root", dir,
+				"--fail-on-error=" + fmt.Sprint(failOnError),
+			})
+
+			err := cmd.Execute()
+			if failOnError {
+				if ExitCode(err) != ExitError {
+					t.Errorf("Execute() = %v, wanted exit code %d", err, ExitError)
+				}
+				return
+			}
+			if ExitCode(err) != ExitViolations {
+				t.Errorf("Execute() = %v, wanted exit code %d", err, ExitViolations)
+			}
+			if got, want := stdout.String(), "broken.mm: could not read: "; !strings.HasPrefix(got, want) {
+				t.Errorf("stdout = %q, wanted prefix %q", got, want)
+			}
+			if got, want := stderr.String(), "checked 2 files, 1 violations in 1 files\n"; got != want {
+				t.Errorf("stderr = %q, wanted %q", got, want)
+			}
+		})
+	}
+}
+
+func TestCheckSymlinkTargets(t *testing.T) {
+	cache, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(cache)
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	writeFiles(t, cache, map[string]string{
+		"a.mm": "// Copyright 2020 Acme\n\ncode\n",
+		"b.mm": "// Copyright 2020 Matt Moore\n\ncode\n",
+	})
+	writeFiles(t, dir, map[string]string{
+		"out/.boilerplate": "// Copyright 2020 Acme\n",
+	})
+	for link, target := range map[string]string{
+		"out/a.mm":      filepath.Join(cache, "a.mm"),
+		"out/b.mm":      filepath.Join(cache, "b.mm"),
+		"out/broken.mm": filepath.Join(cache, "nowhere.mm"),
+	} {
+		if err := os.Symlink(target, filepath.Join(dir, link)); err != nil {
+			t.Fatalf("Symlink() = %v", err)
+		}
+	}
+
+	cmd := NewCheckCommand()
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.SetArgs([]string{
+		"--boilerplate-literal", "// Copyright 2020 Matt Moore\n",
+		"--file-extension", "mm",
+		"--root", dir,
+		"--check-symlink-targets",
+	})
+
+	// The override beside the links applies, not whatever is beside
+	// their targets, and violations are reported by the links' paths.
+	err = cmd.Execute()
+	if ExitCode(err) != ExitViolations {
+		t.Errorf("Execute() = %v, wanted exit code %d", err, ExitViolations)
+	}
+	if got, want := stdout.String(), "out/b.mm:1: "; !strings.HasPrefix(got, want) {
+		t.Errorf("stdout = %q, wanted prefix %q", got, want)
+	}
+	if got, want := stderr.String(), "out/broken.mm: skipped: broken symlink: "; !strings.Contains(got, want) {
+		t.Errorf("stderr = %q, wanted it to contain %q", got, want)
+	}
+	if got, want := stderr.String(), "checked 2 files, 1 violations in 1 files\n"; !strings.HasSuffix(got, want) {
+		t.Errorf("stderr = %q, wanted suffix %q", got, want)
+	}
+}
+
+func TestCheckOpeningLine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	bts, err := ioutil.ReadFile("testdata/old.good.mm")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	writeFiles(t, dir, map[string]string{
+		"modeline.mm": "/* -*- mode: mm -*-" + strings.TrimPrefix(string(bts), "/*"),
+		"plain.mm":    string(bts),
+		"other.mm":    "/* vim: set ft=mm:" + strings.TrimPrefix(string(bts), "/*"),
+	})
+
+	cmd := NewCheckCommand()
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.SetArgs([]string{
+		"--boilerplate", "testdata/boilerplate.mm.txt",
+		"--file-extension", "mm",
+		"--root", dir,
+		"--opening-line", `/\* -\*- .* -\*-`,
+	})
+
+	// Only the opening lines that the pattern matches in full stand in
+	// for the first line of the boilerplate.
+	if err := cmd.Execute(); ExitCode(err) != ExitViolations {
+		t.Errorf("Execute() = %v, wanted exit code %d", err, ExitViolations)
+	}
+	if got, want := stdout.String(), "other.mm:1: missing boilerplate:"; !strings.HasPrefix(got, want) {
+		t.Errorf("stdout = %q, wanted prefix %q", got, want)
+	}
+	if got, want := stderr.String(), "checked 3 files, 1 violations in 1 files\n"; got != want {
+		t.Errorf("stderr = %q, wanted %q", got, want)
+	}
+}
+
+func TestCheckBoilerplateDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	bts, err := ioutil.ReadFile("testdata/old.good.mm")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	writeFiles(t, dir, map[string]string{
+		"hack/boilerplate/boilerplate.go.txt": "// Copyright 2020 Matt Moore\n",
+		"hack/boilerplate/boilerplate.sh.txt": "# Copyright 2020 Matt Moore\n",
+		"empty/boilerplate.md":                "# Copyright 2020 Matt Moore\n",
+		"bad/boilerplate..txt":                "# Copyright 2020 Matt Moore\n",
+		"src/good.go":                         "// Copyright 2019 Matt Moore\n\npackage foo\n",
+		"src/bad.go":                          "# Copyright 2019 Matt Moore\n\npackage foo\n",
+		"src/good.sh":                         "# Copyright 2019 Matt Moore\n\necho hello\n",
+		"src/good.mm":                         string(bts),
+		"src/x.py":                            "print('hello')\n",
+	})
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantOut string
+		wantErr string
+	}{{
+		name:    "extensions of the directory",
+		args:    []string{"--boilerplate-dir", filepath.Join(dir, "hack/boilerplate")},
+		wantOut: "bad.go:1: missing boilerplate:",
+		wantErr: "checked 3 files, 1 violations in 1 files\n",
+	}, {
+		name: "another extension with the boilerplate",
+		args: []string{
+			"--boilerplate-dir", filepath.Join(dir, "hack/boilerplate"),
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "go,sh,mm",
+		},
+		wantOut: "bad.go:1: missing boilerplate:",
+		wantErr: "checked 4 files, 1 violations in 1 files\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(stderr)
+			cmd.SetArgs(append([]string{"--root", filepath.Join(dir, "src")}, test.args...))
+
+			if err := cmd.Execute(); ExitCode(err) != ExitViolations {
+				t.Errorf("Execute() = %v, wanted exit code %d", err, ExitViolations)
+			}
+			if got := stdout.String(); !strings.HasPrefix(got, test.wantOut) {
+				t.Errorf("stdout = %q, wanted prefix %q", got, test.wantOut)
+			}
+			if got := stderr.String(); got != test.wantErr {
+				t.Errorf("stderr = %q, wanted %q", got, test.wantErr)
+			}
+		})
+	}
+
+	errs := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{{
+		name:    "no boilerplate files",
+		args:    []string{"--boilerplate-dir", filepath.Join(dir, "empty")},
+		wantErr: fmt.Sprintf("--boilerplate-dir %q has no boilerplate.EXT.txt files", filepath.Join(dir, "empty")),
+	}, {
+		name:    "no extension",
+		args:    []string{"--boilerplate-dir", filepath.Join(dir, "bad")},
+		wantErr: fmt.Sprintf("--boilerplate-dir file %q must be named boilerplate.EXT.txt, where EXT has no '.'", filepath.Join(dir, "bad/boilerplate..txt")),
+	}, {
+		name: "extension without a boilerplate",
+		args: []string{"--boilerplate-dir", filepath.Join(dir, "hack/boilerplate"), "--file-extension", "go,mm"},
+		wantErr: fmt.Sprintf("--file-extension %q has no boilerplate in --boilerplate-dir %q, and there is no --boilerplate",
+			"mm", filepath.Join(dir, "hack/boilerplate")),
+	}, {
+		// Rather than pass the files it matches.
+		name: "pattern without a boilerplate",
+		args: []string{"--boilerplate-dir", filepath.Join(dir, "hack/boilerplate"), "--file-pattern", "*.py"},
+		wantErr: fmt.Sprintf("%q has no boilerplate in --boilerplate-dir %q, and there is no --boilerplate",
+			"x.py", filepath.Join(dir, "hack/boilerplate")),
+	}, {
+		name:    "pattern without a comment style for license",
+		args:    []string{"--license", "MIT", "--file-pattern", "*.mm"},
+		wantErr: `"good.mm" has no comment style for --license, which --comment-style may give`,
+	}, {
+		name:    "with spdx",
+		args:    []string{"--boilerplate-dir", filepath.Join(dir, "hack/boilerplate"), "--spdx", "Apache-2.0"},
+		wantErr: ErrBoilerplateDirWithSPDX.Error(),
+	}}
+
+	for _, test := range errs {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs(append([]string{"--root", filepath.Join(dir, "src")}, test.args...))
+
+			if err := cmd.Execute(); err == nil || err.Error() != test.wantErr {
+				t.Errorf("Execute() = %v, wanted %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckLicenseRemnants(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	bts, err := ioutil.ReadFile("testdata/boilerplate.mm.txt")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	writeFiles(t, dir, map[string]string{
+		"remnant.mm": string(bts) + "\n// limitations under the License.\n\npackage remnant\n",
+		"doc.mm":     string(bts) + "\n// Package doc is licensed under the Apache License.\npackage doc\n",
+	})
+
+	cmd := NewCheckCommand()
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.SetArgs([]string{
+		"--boilerplate", "testdata/boilerplate.mm.txt",
+		"--file-extension", "mm",
+		"--root", dir,
+		"--forbid-license-remnants",
+	})
+
+	if err := cmd.Execute(); ExitCode(err) != ExitViolations {
+		t.Errorf("Execute() = %v, wanted exit code %d", err, ExitViolations)
+	}
+	if got, want := stdout.String(), "remnant.mm:17: license text after the boilerplate: // limitations under the License.\n"; got != want {
+		t.Errorf("stdout = %q, wanted %q", got, want)
+	}
+	if got, want := stderr.String(), "checked 2 files, 1 violations in 1 files\n"; got != want {
+		t.Errorf("stderr = %q, wanted %q", got, want)
+	}
+}
+
+func TestCheckMaxDepth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{
+		"a.mm":         "a\n",
+		"pkg/b.mm":     "b\n",
+		"pkg/gen/c.mm": "c\n",
+	})
+
+	for depth, want := range map[int]int{0: 3, 1: 1, 2: 2, 3: 3} {
+		t.Run(fmt.Sprint(depth), func(t *testing.T) {
+			cmd := NewCheckCommand()
+			stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(stderr)
+			cmd.SetArgs([]string{
+				"--boilerplate", "testdata/boilerplate.mm.txt",
+				"--file-extension", "mm",
+				"--root", dir,
+				"--max-depth", fmt.Sprint(depth),
+				"--quiet",
+			})
+
+			if err := cmd.Execute(); ExitCode(err) != ExitViolations {
+				t.Errorf("Execute() = %v, wanted exit code %d", err, ExitViolations)
+			}
+			if got, want := stderr.String(), fmt.Sprintf("checked %d files, %d violations in %d files\n", want, want, want); got != want {
+				t.Errorf("stderr = %q, wanted %q", got, want)
+			}
+		})
+	}
+}
+
+func TestCheckFix(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		args    []string
+		golden  string
+		mode    os.FileMode
+		wantErr bool
+	}{{
+		name:   "missing boilerplate",
+		input:  "testdata/missing.bad.mm",
+		golden: "testdata/fix/missing.golden",
+	}, {
+		name:   "missing boilerplate after shebang",
+		input:  "testdata/bash.bad.mm",
+		args:   []string{"--allow-leading-lines"},
+		golden: "testdata/fix/bash.golden",
+		mode:   0755,
+	}, {
+		name:   "missing boilerplate after byte order mark",
+		input:  "testdata/fix/bom.in",
+		golden: "testdata/fix/bom.golden",
+	}, {
+		name:   "incomplete boilerplate",
+		input:  "testdata/cutoff.bad.mm",
+		golden: "testdata/fix/cutoff.golden",
+	}, {
+		name:   "outdated year",
+		input:  "testdata/old.good.mm",
+		args:   []string{"--update-year"},
+		golden: "testdata/fix/old.golden",
+	}, {
+		name:   "no blank line after the boilerplate",
+		input:  "testdata/fix/unseparated.in",
+		args:   []string{"--collapse-blank-lines", "--require-trailing-blank"},
+		golden: "testdata/fix/unseparated.golden",
+	}, {
+		name:   "leading blank lines kept",
+		input:  "testdata/fix/blank.in",
+		args:   []string{"--skip-leading-blank"},
+		golden: "testdata/fix/blank.in",
+	}, {
+		name:   "leading blank lines removed",
+		input:  "testdata/fix/blank.in",
+		args:   []string{"--skip-leading-blank", "--leading-blank-policy", "remove"},
+		golden: "testdata/old.good.mm",
+	}, {
+		name:    "mismatched boilerplate",
+		input:   "testdata/typo.bad.mm",
+		golden:  "testdata/typo.bad.mm",
+		wantErr: true,
+	}, {
+		name:   "forced fix of a typo",
+		input:  "testdata/typo.bad.mm",
+		args:   []string{"--force-fix"},
+		golden: "testdata/fix/typo.golden",
+	}, {
+		name:   "forced fix of a changed url",
+		input:  "testdata/https.bad.mm",
+		args:   []string{"--force-fix"},
+		golden: "testdata/fix/https.golden",
+	}, {
+		name:   "forced fix after shebang",
+		input:  "testdata/fix/shebang.in",
+		args:   []string{"--allow-leading-lines", "--force-fix"},
+		golden: "testdata/fix/shebang.golden",
+		mode:   0755,
+	}}
+
+	boilerplateFile, err := filepath.Abs("testdata/boilerplate.mm.txt")
+	if err != nil {
+		t.Fatalf("Abs() = %v", err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd() = %v", err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input, err := ioutil.ReadFile(test.input)
+			if err != nil {
+				t.Fatalf("ReadFile() = %v", err)
+			}
+			golden, err := ioutil.ReadFile(test.golden)
+			if err != nil {
+				t.Fatalf("ReadFile() = %v", err)
+			}
+			want := boilerplate.Denormalize(string(golden))
+			if test.input == test.golden {
+				want = string(golden)
+			}
+
+			dir, err := ioutil.TempDir("", "boilerplate-check")
+			if err != nil {
+				t.Fatalf("TempDir() = %v", err)
+			}
+			defer os.RemoveAll(dir)
+			mode := test.mode
+			if mode == 0 {
+				mode = 0644
+			}
+			path := filepath.Join(dir, "file.mm")
+			if err := ioutil.WriteFile(path, input, mode); err != nil {
+				t.Fatalf("WriteFile() = %v", err)
+			}
+			// Make sure the umask didn't interfere.
+			if err := os.Chmod(path, mode); err != nil {
+				t.Fatalf("Chmod() = %v", err)
+			}
+
+			if err := os.Chdir(dir); err != nil {
+				t.Fatalf("Chdir() = %v", err)
+			}
+			defer os.Chdir(wd)
+
+			// Running a second time checks that fixing is idempotent.
+			for i := 0; i < 2; i++ {
+				cmd := NewCheckCommand()
+				cmd.SetOut(new(bytes.Buffer))
+				cmd.SetErr(new(bytes.Buffer))
+				cmd.SetArgs(append([]string{
+					"--boilerplate", boilerplateFile,
+					"--file-extension", "mm",
+					"--fix",
+				}, test.args...))
+
+				if err := cmd.Execute(); (err != nil) != test.wantErr {
+					t.Errorf("Execute() = %v, wanted error: %v", err, test.wantErr)
+				}
+
+				got, err := ioutil.ReadFile(path)
+				if err != nil {
+					t.Fatalf("ReadFile() = %v", err)
+				}
+				if string(got) != want {
+					t.Errorf("Run %d: got %q, wanted %q", i, string(got), want)
+				}
+
+				info, err := os.Stat(path)
+				if err != nil {
+					t.Fatalf("Stat() = %v", err)
+				}
+				if got := info.Mode().Perm(); got != mode {
+					t.Errorf("Run %d: mode = %v, wanted %v", i, got, mode)
+				}
+				// Make sure no temporary files were left behind.
+				if infos, err := ioutil.ReadDir(dir); err != nil {
+					t.Fatalf("ReadDir() = %v", err)
+				} else if len(infos) != 1 {
+					t.Errorf("Run %d: ReadDir() = %d entries, wanted 1", i, len(infos))
+				}
+			}
+		})
+	}
+}
+
+func TestCheckFixDryRun(t *testing.T) {
+	boilerplateFile, err := filepath.Abs("testdata/boilerplate.mm.txt")
+	if err != nil {
+		t.Fatalf("Abs() = %v", err)
+	}
+	input, err := ioutil.ReadFile("testdata/bash.bad.mm")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd() = %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "file.mm")
+	if err := ioutil.WriteFile(path, input, 0644); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir() = %v", err)
+	}
+	defer os.Chdir(wd)
+
+	cmd := NewCheckCommand()
+	output := new(bytes.Buffer)
+	cmd.SetOut(output)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"--boilerplate", boilerplateFile,
+		"--file-extension", "mm",
+		"--allow-leading-lines",
+		"--fix", "--dry-run",
+	})
+
+	if err := cmd.Execute(); err == nil {
+		t.Error("Execute() = nil, wanted error")
+	}
+
+	want := boilerplate.Denormalize(`--- file.mm
++++ file.mm
+@@ -1,3 +1,19 @@
+ #!/usr/bin/env bash
+ 
++/*
++Copyright YYYY Matt Moore
++
++Licensed under the Apache License, Version 2.0 (the "License");
++you may not use this file except in compliance with the License.
++You may obtain a copy of the License at
++
++    http://www.apache.org/licenses/LICENSE-2.0
++
++Unless required by applicable law or agreed to in writing, software
++distributed under the License is distributed on an "AS IS" BASIS,
++WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
++See the License for the specific language governing permissions and
++limitations under the License.
++*/
++
+ echo hello
+`)
+	if got := output.String(); got != want {
+		t.Errorf("Execute() = %s, wanted %s", got, want)
+	}
+
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	if string(got) != string(input) {
+		t.Errorf("--dry-run modified the file: %q", string(got))
+	}
+}
+
+// BenchmarkCheckTree checks a synthetic tree in which most files have
+// the boilerplate, as in most repositories.
+func BenchmarkCheckTree(b *testing.B) {
+	good, err := ioutil.ReadFile("testdata/old.good.mm")
+	if err != nil {
+		b.Fatalf("ReadFile() = %v", err)
+	}
+	bad, err := ioutil.ReadFile("testdata/missing.bad.mm")
+	if err != nil {
+		b.Fatalf("ReadFile() = %v", err)
+	}
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		b.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	for i := 0; i < 1000; i++ {
+		sub := filepath.Join(dir, fmt.Sprint(i/100))
+		if err := os.MkdirAll(sub, 0755); err != nil {
+			b.Fatalf("MkdirAll() = %v", err)
+		}
+		content := good
+		if i%100 == 0 {
+			content = bad
+		}
+		if err := ioutil.WriteFile(filepath.Join(sub, fmt.Sprintf("%d.mm", i)), content, 0644); err != nil {
+			b.Fatalf("WriteFile() = %v", err)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cmd := NewCheckCommand()
+		cmd.SetOut(ioutil.Discard)
+		cmd.SetErr(ioutil.Discard)
+		cmd.SetArgs([]string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--root", dir,
+		})
+		// Some of the files are bad.
+		if err := cmd.Execute(); ExitCode(err) != ExitViolations {
+			b.Fatalf("Execute() = %v", err)
+		}
+	}
+}
+
+func TestCheckSince(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	bad, err := ioutil.ReadFile("testdata/typo.bad.mm")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	writeFiles(t, dir, map[string]string{
+		"old.mm": string(bad),
+		"new.mm": string(bad),
+	})
+	old := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.Local)
+	if err := os.Chtimes(filepath.Join(dir, "old.mm"), old, old); err != nil {
+		t.Fatalf("Chtimes() = %v", err)
+	}
+
+	tests := []struct {
+		since string
+		want  string
+	}{{
+		since: "2024-01-01",
+		want:  "new.mm\n",
+	}, {
+		since: "2023-06-01",
+		want:  "new.mm\nold.mm\n",
+	}, {
+		since: "2023-06-01T13:00:00" + old.Format("Z07:00"),
+		want:  "new.mm\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.since, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			stdout := new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs([]string{
+				"--boilerplate", "testdata/boilerplate.mm.txt",
+				"--file-extension", "mm",
+				"--root", dir,
+				"--print-files", "failing",
+				"--since", test.since,
+			})
+
+			if err := cmd.Execute(); err != nil {
+				t.Errorf("Execute() = %v", err)
+			}
+			if got := stdout.String(); got != test.want {
+				t.Errorf("stdout = %q, wanted %q", got, test.want)
 			}
 		})
 	}
@@ -0,0 +1,94 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+	"github.com/spf13/cobra"
+)
+
+// checkFileIncompatible are the check flags that concern which files are
+// walked or how, which check-file does not support.
+var checkFileIncompatible = []string{"root", "files-from", "files-from0", "follow-symlinks", "max-depth", "check-symlink-targets", "watch", "watch-interval", "cache", "find-root", "root-marker", "since", "concurrency", "sniff-shebang", "interpreter", "fail-on-no-matches"}
+
+// NewCheckFileCommand implements the `check-file` sub-command
+func NewCheckFileCommand() *cobra.Command {
+	co := &checkOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "check-file FILE",
+		Short: "Checks that the header of a single file matches boilerplate files, without walking any directory.",
+		Example: `  boilerplate-check check-file pkg/foo/foo.go \
+    --boilerplate ./hack/boilerplate/boilerplate.go.txt --file-extension go`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: co.checkFilePreRunE,
+		RunE:    co.checkFileRunE,
+	}
+	co.AddFlags(cmd)
+	cmd.SetOut(os.Stdout)
+
+	return cmd
+}
+
+func (co *checkOptions) checkFilePreRunE(cmd *cobra.Command, args []string) error {
+	if err := rejectFlags(cmd, checkFileIncompatible); err != nil {
+		return err
+	}
+	return co.PreRunE(cmd, args)
+}
+
+func (co *checkOptions) checkFileRunE(cmd *cobra.Command, args []string) error {
+	return withIssuesExitCode(co.run(cmd, func() error {
+		return co.checkFile(cmd, args[0])
+	}), co.IssuesExitCode)
+}
+
+// checkFile checks the file at file, which is reported by that path.
+// The caller chose it, so unlike the files we walk, it is checked
+// whatever its extension and whether or not --exclude matches it.
+func (co *checkOptions) checkFile(cmd *cobra.Command, file string) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return co.record(file, co.failure(boilerplate.Unreadable), co.unreadable(file, err))
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%q is not a regular file", file)
+	}
+	co.matched++
+	if co.MaxFileSize > 0 && info.Size() > co.MaxFileSize {
+		co.log.logf(warnLevel, file, "skipped: %d bytes is larger than --max-file-size %d",
+			info.Size(), co.MaxFileSize)
+		return nil
+	}
+	// Overrides above the working directory don't apply, as for
+	// explain.
+	co.tops["."] = true
+	checkers, err := co.checkersFor(cmd, filepath.Dir(file))
+	if err != nil {
+		return err
+	}
+	if checkers, err = co.forFile(file, checkers); err != nil {
+		return err
+	}
+	co.log.logf(debugLevel, file, "checked")
+	result, err := co.check(cmd, checkers, co.open, file, file, info)
+	return co.record(file, result, err)
+}
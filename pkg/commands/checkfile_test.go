@@ -0,0 +1,148 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCheckFile(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		args []string
+		want string
+		code int
+	}{{
+		name: "good",
+		file: "testdata/old.good.mm",
+	}, {
+		name: "bad",
+		file: "testdata/typo.bad.mm",
+		want: "testdata/typo.bad.mm:2: found mismatched boilerplate lines:",
+		code: ExitViolations,
+	}, {
+		// The caller chose the file, so it is checked anyway.
+		name: "other extension",
+		file: "testdata/typo.bad.mm",
+		args: []string{"--file-extension", "go"},
+		want: "testdata/typo.bad.mm:2: found mismatched boilerplate lines:",
+		code: ExitViolations,
+	}, {
+		name: "excluded",
+		file: "testdata/typo.bad.mm",
+		args: []string{"--exclude", "typo"},
+		want: "testdata/typo.bad.mm:2: found mismatched boilerplate lines:",
+		code: ExitViolations,
+	}, {
+		name: "missing",
+		file: "testdata/missing.mm",
+		want: "testdata/missing.mm: could not read: ",
+		code: ExitViolations,
+	}, {
+		name: "issues exit code",
+		file: "testdata/typo.bad.mm",
+		args: []string{"--issues-exit-code", "4"},
+		want: "testdata/typo.bad.mm:2: found mismatched boilerplate lines:",
+		code: 4,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewCheckFileCommand()
+			stdout := new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(new(bytes.Buffer))
+			args := []string{
+				test.file,
+				"--boilerplate", "testdata/boilerplate.mm.txt",
+				"--file-extension", "mm",
+			}
+			cmd.SetArgs(append(args, test.args...))
+
+			err := cmd.Execute()
+			if got := stdout.String(); !strings.HasPrefix(got, test.want) || (got != "") != (test.want != "") {
+				t.Errorf("stdout = %q, wanted it to start with %q", got, test.want)
+			}
+			if ExitCode(err) != test.code {
+				t.Errorf("Execute() = %v, wanted exit code %d", err, test.code)
+			}
+		})
+	}
+}
+
+func TestCheckFileNoBoilerplate(t *testing.T) {
+	cmd := NewCheckFileCommand()
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"testdata/old.good.mm",
+		"--license", "Apache-2.0",
+		"--file-extension", "go",
+	})
+
+	err := cmd.Execute()
+	want := `"testdata/old.good.mm" has no comment style for --license, which --comment-style may give`
+	if err == nil || err.Error() != want {
+		t.Errorf("Execute() = %v, wanted %q", err, want)
+	}
+	if ExitCode(err) != ExitError {
+		t.Errorf("ExitCode() = %d, wanted %d", ExitCode(err), ExitError)
+	}
+}
+
+func TestCheckFilePreRunE(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{{
+		name:    "no file",
+		args:    []string{},
+		wantErr: "accepts 1 arg(s), received 0",
+	}, {
+		name:    "with --root",
+		args:    []string{"foo.mm", "--root", "testdata"},
+		wantErr: "--root may not be used with check-file",
+	}, {
+		name:    "with --files-from",
+		args:    []string{"foo.mm", "--files-from", "-"},
+		wantErr: "--files-from may not be used with check-file",
+	}, {
+		name:    "with --watch",
+		args:    []string{"foo.mm", "--watch"},
+		wantErr: "--watch may not be used with check-file",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewCheckFileCommand()
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs(append(test.args,
+				"--boilerplate", "testdata/boilerplate.mm.txt",
+				"--file-extension", "mm"))
+
+			err := cmd.Execute()
+			if err == nil || err.Error() != test.wantErr {
+				t.Errorf("Execute() = %v, wanted %q", err, test.wantErr)
+			}
+		})
+	}
+}
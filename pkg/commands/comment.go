@@ -0,0 +1,69 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+)
+
+// commentStyles returns the comment styles of common languages, and
+// those of --comment-style, by extension (or file name).
+func (co *checkOptions) commentStyles() (map[string]boilerplate.CommentStyle, error) {
+	styles := boilerplate.CommentStyles()
+	for _, value := range co.CommentStyles {
+		name, style, err := parseCommentStyle(value)
+		if err != nil {
+			return nil, err
+		}
+		styles[name] = style
+	}
+	return styles, nil
+}
+
+// parseCommentStyle parses a --comment-style of the form
+// EXT=LINE[,START,END], returning the extension (or file name) and the
+// comment style.  LINE may be empty for languages with only block
+// comments, e.g. css=,/*,*/.
+func parseCommentStyle(value string) (string, boilerplate.CommentStyle, error) {
+	var style boilerplate.CommentStyle
+	i := strings.Index(value, "=")
+	if i <= 0 {
+		return "", style, fmt.Errorf("--comment-style %q must be of the form EXT=LINE[,START,END]", value)
+	}
+	name := value[:i]
+	if strings.HasPrefix(name, ".") {
+		return "", style, fmt.Errorf("--comment-style %q may not start with '.'", value)
+	}
+	switch parts := strings.Split(value[i+1:], ","); len(parts) {
+	case 1:
+		style.Line = parts[0]
+	case 3:
+		style.Line, style.Start, style.End = parts[0], parts[1], parts[2]
+	default:
+		return "", style, fmt.Errorf("--comment-style %q must be of the form EXT=LINE[,START,END]", value)
+	}
+	if (style.Start == "") != (style.End == "") {
+		return "", style, fmt.Errorf("--comment-style %q must have both or neither of START and END", value)
+	}
+	if style.Line == "" && style.Start == "" {
+		return "", style, fmt.Errorf("--comment-style %q has no comment syntax", value)
+	}
+	return name, style, nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+)
+
+func TestParseCommentStyle(t *testing.T) {
+	tests := []struct {
+		value     string
+		wantName  string
+		wantStyle boilerplate.CommentStyle
+		wantErr   string
+	}{{
+		value:     "py=#",
+		wantName:  "py",
+		wantStyle: boilerplate.CommentStyle{Line: "#"},
+	}, {
+		value:     "lua=--,--[[,]]",
+		wantName:  "lua",
+		wantStyle: boilerplate.CommentStyle{Line: "--", Start: "--[[", End: "]]"},
+	}, {
+		value:     "css=,/*,*/",
+		wantName:  "css",
+		wantStyle: boilerplate.CommentStyle{Start: "/*", End: "*/"},
+	}, {
+		value:     "Dockerfile=#",
+		wantName:  "Dockerfile",
+		wantStyle: boilerplate.CommentStyle{Line: "#"},
+	}, {
+		value:   "#",
+		wantErr: `--comment-style "#" must be of the form EXT=LINE[,START,END]`,
+	}, {
+		value:   ".py=#",
+		wantErr: `--comment-style ".py=#" may not start with '.'`,
+	}, {
+		value:   "c=//,/*,",
+		wantErr: `--comment-style "c=//,/*," must have both or neither of START and END`,
+	}, {
+		value:   "c=",
+		wantErr: `--comment-style "c=" has no comment syntax`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			name, style, err := parseCommentStyle(test.value)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Errorf("parseCommentStyle() = %v, wanted %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCommentStyle() = %v", err)
+			}
+			if name != test.wantName {
+				t.Errorf("parseCommentStyle() name = %q, wanted %q", name, test.wantName)
+			}
+			if !cmp.Equal(style, test.wantStyle) {
+				t.Errorf("parseCommentStyle() (-want, +got): %s", cmp.Diff(test.wantStyle, style))
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCompletion(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		t.Run(shell, func(t *testing.T) {
+			root := &cobra.Command{Use: "boilerplate-check"}
+			AddAll(root)
+			completion, _, err := root.Find([]string{"completion"})
+			if err != nil {
+				t.Fatalf("Find() = %v", err)
+			}
+			stdout := new(bytes.Buffer)
+			completion.SetOut(stdout)
+			root.SetErr(new(bytes.Buffer))
+			root.SetArgs([]string{"completion", shell})
+
+			if err := root.Execute(); err != nil {
+				t.Fatalf("Execute() = %v", err)
+			}
+			if got := stdout.String(); !strings.Contains(got, "boilerplate-check") {
+				t.Errorf("stdout = %s, wanted a script for boilerplate-check", got)
+			}
+		})
+	}
+
+	root := &cobra.Command{Use: "boilerplate-check"}
+	AddAll(root)
+	root.SetOut(new(bytes.Buffer))
+	root.SetErr(new(bytes.Buffer))
+	root.SetArgs([]string{"completion", "tcsh"})
+	if err := root.Execute(); err == nil {
+		t.Error("Execute() = nil, wanted error")
+	}
+}
+
+func TestCompleteFlagValues(t *testing.T) {
+	tests := []struct {
+		flag string
+		want string
+	}{{
+		flag: "--format",
+		want: "checkstyle\ngithub\njson\nrdjsonl\nsarif\ntext\n",
+	}, {
+		flag: "--color",
+		want: "auto\nalways\nnever\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.flag, func(t *testing.T) {
+			root := &cobra.Command{Use: "boilerplate-check"}
+			AddAll(root)
+			// The completions are printed by the command being completed.
+			check, _, err := root.Find([]string{"check"})
+			if err != nil {
+				t.Fatalf("Find() = %v", err)
+			}
+			stdout := new(bytes.Buffer)
+			check.SetOut(stdout)
+			root.SetErr(new(bytes.Buffer))
+			root.SetArgs([]string{cobra.ShellCompRequestCmd, "check", test.flag, ""})
+
+			if err := root.Execute(); err != nil {
+				t.Fatalf("Execute() = %v", err)
+			}
+			// The values are followed by the directive to the shell.
+			if got := stdout.String(); !strings.HasPrefix(got, test.want) {
+				t.Errorf("stdout = %q, wanted prefix %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"os"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+	"github.com/spf13/cobra"
+)
+
+// pending is a file whose result is yet to be reported, because it, or a
+// file before it, is still being checked.
+type pending struct {
+	// done is closed once the file has been checked.
+	done chan struct{}
+	// report reports the result of checking the file.
+	report func() error
+}
+
+// checkLater checks file, reported by path, against the boilerplates of
+// checkers, alongside up to --concurrency others.  Its result is reported
+// once those of the files before it have been, so that the output is the
+// same, whatever --concurrency is.
+func (co *checkOptions) checkLater(cmd *cobra.Command, checkers []*boilerplate.Checker, file, path string, info os.FileInfo, cached bool) error {
+	var violations []boilerplate.Violation
+	var findErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		violations, findErr = find(checkers, co.forbidden, co.open, file, path)
+	}()
+	co.queue = append(co.queue, &pending{done: done, report: func() error {
+		result, err := co.report(cmd, file, path, info, violations, findErr)
+		if err == nil && cached {
+			co.cache.update(file, info, co.cacheKey, result == conforming)
+		}
+		return co.record(path, result, err)
+	}})
+	return co.flush(false)
+}
+
+// later calls report once the results of the files being checked have
+// been reported, e.g. to report a file that could not be checked in its
+// turn.
+func (co *checkOptions) later(report func() error) error {
+	if len(co.queue) == 0 {
+		return report()
+	}
+	done := make(chan struct{})
+	close(done)
+	co.queue = append(co.queue, &pending{done: done, report: report})
+	return co.flush(false)
+}
+
+// flush reports the results of the files that have been checked, in
+// order, up to the first still being checked.  With all, it waits for
+// each of them, and otherwise only while --concurrency files are, but
+// never past --timeout.
+func (co *checkOptions) flush(all bool) error {
+	for len(co.queue) > 0 {
+		next := co.queue[0]
+		if all || len(co.queue) >= co.Concurrency {
+			select {
+			case <-next.done:
+			case <-co.ctx.Done():
+				return errTimedOut
+			}
+		} else {
+			select {
+			case <-next.done:
+			default:
+				return nil
+			}
+		}
+		co.queue = co.queue[1:]
+		if err := next.report(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drain reports the results of the files that have been checked, in
+// order, up to the first still being checked, which is abandoned along
+// with those after it.
+func (co *checkOptions) drain() error {
+	for len(co.queue) > 0 {
+		next := co.queue[0]
+		select {
+		case <-next.done:
+		default:
+			return nil
+		}
+		co.queue = co.queue[1:]
+		if err := next.report(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
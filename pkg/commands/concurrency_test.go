@@ -0,0 +1,135 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCheckConcurrency(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	contents := make([]string, 0, 4)
+	for _, name := range []string{"old.good.mm", "typo.bad.mm", "short.bad.mm", "https.bad.mm"} {
+		content, err := ioutil.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("ReadFile() = %v", err)
+		}
+		contents = append(contents, string(content))
+	}
+	// Spread enough files over enough directories that workers finish
+	// out of order, including some whose headers are also forbidden and
+	// some that can't be read.
+	files := make(map[string]string)
+	for i := 0; i < 60; i++ {
+		files[fmt.Sprintf("pkg%d/file%d.mm", i%7, i)] = contents[i%len(contents)]
+	}
+	writeFiles(t, dir, files)
+	for i := 0; i < 5; i++ {
+		if err := os.Symlink("missing.mm", filepath.Join(dir, fmt.Sprintf("pkg%d/broken.mm", i))); err != nil {
+			t.Fatalf("Symlink() = %v", err)
+		}
+	}
+
+	run := func(t *testing.T, args ...string) string {
+		t.Helper()
+		cmd := NewCheckCommand()
+		stdout := new(bytes.Buffer)
+		cmd.SetOut(stdout)
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetArgs(append([]string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--forbid", "testdata/forbidden.mm.txt",
+			"--file-extension", "mm",
+		}, args...))
+		// The violations are in the output; only errors matter here.
+		if err := cmd.Execute(); ExitCode(err) == ExitError {
+			t.Fatalf("Execute() = %v", err)
+		}
+		return stdout.String()
+	}
+
+	for _, format := range []string{"text", "json", "print-files"} {
+		t.Run(format, func(t *testing.T) {
+			args := []string{"--root", dir, "--format", format}
+			if format == "print-files" {
+				args = []string{"--root", dir, "--print-files", "failing"}
+			}
+			want := run(t, append(args, "--concurrency", "1")...)
+			if want == "" {
+				t.Fatal("Execute() printed nothing")
+			}
+			for i := 0; i < 10; i++ {
+				if got := run(t, append(args, "--concurrency", "8")...); got != want {
+					t.Fatalf("--concurrency 8 printed:\n%s\nwanted, as with --concurrency 1:\n%s", got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("order", func(t *testing.T) {
+		var paths []string
+		for _, line := range strings.Split(run(t, "--root", dir, "--concurrency", "8", "--print-files", "failing"), "\n") {
+			if line != "" {
+				paths = append(paths, line)
+			}
+		}
+		if !sort.StringsAreSorted(paths) {
+			t.Errorf("--print-files failing = %v, wanted them sorted", paths)
+		}
+	})
+
+	t.Run("files-from", func(t *testing.T) {
+		// Listed files are reported in the order they are listed, which
+		// here is the reverse of a walk's.
+		var listed []string
+		for name := range files {
+			listed = append(listed, filepath.Join(dir, name))
+		}
+		sort.Sort(sort.Reverse(sort.StringSlice(listed)))
+		list := filepath.Join(dir, "files.txt")
+		if err := ioutil.WriteFile(list, []byte(strings.Join(listed, "\n")+"\n"), 0644); err != nil {
+			t.Fatalf("WriteFile() = %v", err)
+		}
+		args := []string{"--files-from", list, "--print-files", "failing"}
+		want := run(t, append(args, "--concurrency", "1")...)
+		for i := 0; i < 10; i++ {
+			if got := run(t, append(args, "--concurrency", "8")...); got != want {
+				t.Fatalf("--concurrency 8 printed:\n%s\nwanted, as with --concurrency 1:\n%s", got, want)
+			}
+		}
+		var paths []string
+		for _, line := range strings.Split(want, "\n") {
+			if line != "" {
+				paths = append(paths, line)
+			}
+		}
+		if len(paths) < 2 || !sort.IsSorted(sort.Reverse(sort.StringSlice(paths))) {
+			t.Errorf("--print-files failing = %v, wanted them in the order listed", paths)
+		}
+	})
+}
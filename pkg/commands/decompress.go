@@ -0,0 +1,57 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+)
+
+// decompressModes are the --decompress values.
+var decompressModes = []string{"none", "gzip"}
+
+// open opens file for checking, decompressing its content as directed
+// by --decompress.
+func (co *checkOptions) open(file string) (io.ReadCloser, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	switch co.Decompress {
+	case "gzip":
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		// Closing the gzip.Reader does not close the file.
+		return &decompressed{Reader: zr, file: f}, nil
+	default:
+		return f, nil
+	}
+}
+
+// decompressed reads the decompressed content of a file.
+type decompressed struct {
+	io.Reader
+	file *os.File
+}
+
+func (d *decompressed) Close() error {
+	return d.file.Close()
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want string
+	}{{
+		name: "no changes",
+		old:  "a\nb\n",
+		new:  "a\nb\n",
+		want: "",
+	}, {
+		name: "insert at the top",
+		old:  "a\nb\nc\nd\ne\n",
+		new:  "x\ny\na\nb\nc\nd\ne\n",
+		want: `--- f
++++ f
+@@ -1,3 +1,5 @@
++x
++y
+ a
+ b
+ c
+`,
+	}, {
+		name: "append without trailing newline",
+		old:  "a\nb",
+		new:  "a\nb\nc\n",
+		want: `--- f
++++ f
+@@ -1,2 +1,3 @@
+ a
+-b
+\ No newline at end of file
++b
++c
+`,
+	}, {
+		name: "insert into an empty file",
+		old:  "",
+		new:  "a\n",
+		want: `--- f
++++ f
+@@ -0,0 +1 @@
++a
+`,
+	}, {
+		name: "separate hunks",
+		old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+		new:  "1\nx\n3\n4\n5\n6\n7\n8\n9\ny\n",
+		want: `--- f
++++ f
+@@ -1,5 +1,5 @@
+ 1
+-2
++x
+ 3
+ 4
+ 5
+@@ -7,4 +7,4 @@
+ 7
+ 8
+ 9
+-10
++y
+`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := unifiedDiff("f", test.old, test.new); got != test.want {
+				t.Errorf("unifiedDiff() = %s, wanted %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// envFlags are the flags that fall back to environment variables, so
+// that CI systems can set defaults centrally.  The variable is only used
+// when neither its flag nor any of the flags that conflict with it are
+// passed.
+var envFlags = []struct {
+	env       string
+	flag      string
+	conflicts []string
+}{
+	{"BOILERPLATE_FILE", "boilerplate", []string{"boilerplate-literal", "boilerplate-dir", "license", "spdx"}},
+	{"BOILERPLATE_EXTENSION", "file-extension", nil},
+	{"BOILERPLATE_EXCLUDE", "exclude", nil},
+}
+
+// applyEnv sets the flags of cmd that were not passed from the
+// environment variables they fall back to, if those are set.
+func (co *checkOptions) applyEnv(cmd *cobra.Command) error {
+	for _, ef := range envFlags {
+		value := os.Getenv(ef.env)
+		if value == "" || passedAny(cmd, ef.flag, ef.conflicts) {
+			continue
+		}
+		if err := cmd.Flags().Set(ef.flag, value); err != nil {
+			return fmt.Errorf("error parsing $%s for --%s: %v", ef.env, ef.flag, err)
+		}
+		co.log.logf(debugLevel, "", "using $%s for --%s", ef.env, ef.flag)
+	}
+	return nil
+}
+
+// passedAny returns whether flag, or any of others, was passed to cmd.
+func passedAny(cmd *cobra.Command, flag string, others []string) bool {
+	for _, name := range append([]string{flag}, others...) {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
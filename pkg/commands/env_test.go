@@ -0,0 +1,120 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestCheckEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want string
+	}{{
+		name: "from the environment",
+		env: map[string]string{
+			"BOILERPLATE_FILE":      "testdata/boilerplate.mm.txt",
+			"BOILERPLATE_EXTENSION": "mm",
+			"BOILERPLATE_EXCLUDE":   "[^o].bad.mm",
+		},
+		want: "testdata/typo.bad.mm\n",
+	}, {
+		name: "flags take precedence",
+		env: map[string]string{
+			"BOILERPLATE_FILE":      "testdata/no-such-boilerplate.txt",
+			"BOILERPLATE_EXTENSION": "go",
+			"BOILERPLATE_EXCLUDE":   "[^o].bad.mm",
+		},
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^s].bad.mm",
+		},
+		want: "testdata/https.bad.mm\n",
+	}, {
+		name: "conflicting flags take precedence",
+		env: map[string]string{
+			"BOILERPLATE_FILE":    "testdata/boilerplate.mm.txt",
+			"BOILERPLATE_EXCLUDE": "good.mm|[^o].bad.mm",
+		},
+		args: []string{
+			"--spdx", "Apache-2.0",
+			"--file-extension", "mm",
+		},
+		want: "testdata/typo.bad.mm\n",
+	}, {
+		name: "--license takes precedence",
+		env: map[string]string{
+			"BOILERPLATE_FILE":    "testdata/no-such-boilerplate.txt",
+			"BOILERPLATE_EXCLUDE": "[^o].bad.mm",
+		},
+		args: []string{
+			"--license", "Apache-2.0",
+			"--project", "Matt Moore",
+			"--comment-style", "mm=//,/*,*/",
+			"--file-extension", "mm",
+		},
+		want: "testdata/typo.bad.mm\n",
+	}, {
+		name: "--boilerplate-dir takes precedence",
+		env: map[string]string{
+			"BOILERPLATE_FILE":    "testdata/no-such-boilerplate.txt",
+			"BOILERPLATE_EXCLUDE": "[^o].bad.mm",
+		},
+		args: []string{
+			"--boilerplate-dir", "testdata",
+			"--file-extension", "mm",
+		},
+		want: "testdata/typo.bad.mm\n",
+	}, {
+		name: "empty variables are ignored",
+		env: map[string]string{
+			"BOILERPLATE_EXCLUDE": "",
+		},
+		args: []string{
+			"--boilerplate", "testdata/boilerplate.mm.txt",
+			"--file-extension", "mm",
+			"--exclude", "[^s].bad.mm",
+		},
+		want: "testdata/https.bad.mm\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				os.Setenv(name, value)
+				defer os.Unsetenv(name)
+			}
+			cmd := NewCheckCommand()
+			stdout := new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs(append([]string{"--print-files", "failing"}, test.args...))
+
+			if err := cmd.Execute(); err != nil {
+				t.Errorf("Execute() = %v", err)
+			}
+			if got := stdout.String(); got != test.want {
+				t.Errorf("stdout = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
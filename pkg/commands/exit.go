@@ -0,0 +1,82 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// ExitViolations is the exit status of a check that found
+	// violations, including files that --fix could not correct.
+	ExitViolations = 1
+	// ExitError is the exit status when the tool itself fails, e.g.
+	// because of a bad flag or an unreadable boilerplate.
+	ExitError = 2
+	// ExitTimeout is the exit status of a check stopped by --timeout,
+	// whatever it found until then.
+	ExitTimeout = 3
+)
+
+// exitError is an error that calls for an exit status other than
+// ExitError.
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e *exitError) Error() string {
+	return e.msg
+}
+
+// ExitCode returns the status the tool should exit with after
+// returning err, or 0 if err is nil.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var ee *exitError
+	if errors.As(err, &ee) {
+		return ee.code
+	}
+	return ExitError
+}
+
+// validateIssuesExitCode returns an error if code may not be the exit
+// status of finding violations, for --issues-exit-code: it must be a
+// status, and one that the tool failing does not exit with.
+func validateIssuesExitCode(code int) error {
+	switch {
+	case code < 0 || code > 255:
+		return fmt.Errorf("--issues-exit-code %d must be between 0 and 255", code)
+	case code == ExitError || code == ExitTimeout:
+		return fmt.Errorf("--issues-exit-code %d may not be %d or %d, which report errors and timeouts",
+			code, ExitError, ExitTimeout)
+	}
+	return nil
+}
+
+// withIssuesExitCode returns err, but exiting with code instead of
+// ExitViolations, for --issues-exit-code.
+func withIssuesExitCode(err error, code int) error {
+	var ee *exitError
+	if errors.As(err, &ee) && ee.code == ExitViolations {
+		return &exitError{code, ee.msg}
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	violations := &exitError{ExitViolations, "found 1 violations in 1 file(s)"}
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{{
+		name: "success",
+		want: 0,
+	}, {
+		name: "violations",
+		err:  violations,
+		want: ExitViolations,
+	}, {
+		name: "wrapped violations",
+		err:  fmt.Errorf("checking: %w", violations),
+		want: ExitViolations,
+	}, {
+		name: "other error",
+		err:  errors.New("error compiling --exclude pattern"),
+		want: ExitError,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ExitCode(test.err); got != test.want {
+				t.Errorf("ExitCode() = %d, wanted %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWithIssuesExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{{
+		name: "success",
+		want: 0,
+	}, {
+		name: "violations",
+		err:  &exitError{ExitViolations, "found 1 violations in 1 file(s)"},
+		want: 7,
+	}, {
+		name: "timeout",
+		err:  &exitError{ExitTimeout, "timed out after 1s, per --timeout"},
+		want: ExitTimeout,
+	}, {
+		name: "other error",
+		err:  errors.New("error compiling --exclude pattern"),
+		want: ExitError,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := withIssuesExitCode(test.err, 7)
+			if got := ExitCode(err); got != test.want {
+				t.Errorf("ExitCode() = %d, wanted %d", got, test.want)
+			}
+			if test.err != nil && err.Error() != test.err.Error() {
+				t.Errorf("Error() = %q, wanted %q", err.Error(), test.err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+	"github.com/spf13/cobra"
+)
+
+// explainIncompatible are the check flags that concern which files are
+// checked, or how the results are reported, which explain does not
+// support.
+var explainIncompatible = []string{"root", "max-depth", "files-from", "files-from0", "fix", "watch", "format", "count", "count-files", "print-files", "stats", "cache", "find-root", "root-marker", "since", "concurrency", "timeout"}
+
+// NewExplainCommand implements the `explain` sub-command
+func NewExplainCommand() *cobra.Command {
+	co := &checkOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "explain FILE",
+		Short: "Explains, step by step, how check checks a file's header.",
+		Example: `  boilerplate-check explain pkg/foo/foo.go \
+    --boilerplate ./hack/boilerplate/boilerplate.go.txt --file-extension go`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: co.explainPreRunE,
+		RunE:    co.explainRunE,
+	}
+	co.AddFlags(cmd)
+	cmd.SetOut(os.Stdout)
+
+	return cmd
+}
+
+func (co *checkOptions) explainPreRunE(cmd *cobra.Command, args []string) error {
+	if err := rejectFlags(cmd, explainIncompatible); err != nil {
+		return err
+	}
+	return co.PreRunE(cmd, args)
+}
+
+func (co *checkOptions) explainRunE(cmd *cobra.Command, args []string) error {
+	// Errors past flag validation don't warrant usage, and are
+	// reported by our caller.
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	file := args[0]
+	out := cmd.OutOrStdout()
+	reason, ext := co.filter.SkipReason(file), ""
+	if reason != "" && co.sniffs(file) {
+		var err error
+		if ext, err = co.sniff(file, file); err != nil {
+			return err
+		}
+	}
+	switch {
+	case ext != "":
+		fmt.Fprintf(out, "%s: check would check it, as a .%s file per its shebang\n", file, ext)
+	case reason != "":
+		fmt.Fprintf(out, "%s: check would skip it: %s\n", file, reason)
+	default:
+		fmt.Fprintf(out, "%s: check would check it\n", file)
+	}
+
+	co.overrides = make(map[string][]*boilerplate.Checker)
+	co.tops = map[string]bool{".": true}
+	checkers, err := co.checkersFor(cmd, filepath.Dir(file))
+	if err != nil {
+		return err
+	}
+	found, err := co.forFile(file, checkers)
+	if err != nil {
+		return err
+	}
+	if !sameCheckers(found, checkers) {
+		if co.License != "" {
+			fmt.Fprintln(out, "the header of --license in the comment style of its extension applies")
+		} else {
+			fmt.Fprintln(out, "the boilerplate of --boilerplate-dir for its extension applies")
+		}
+		checkers = found
+	} else if !sameCheckers(checkers, co.checkers) {
+		fmt.Fprintf(out, "the boilerplate of a %s file applies\n", overrideFile)
+	}
+
+	for i, c := range checkers {
+		fmt.Fprintf(out, "boilerplate %d of %d:\n", i+1, len(checkers))
+		if err := co.explain(c, file, out); err != nil {
+			return err
+		}
+	}
+	for i, c := range co.forbidden {
+		fmt.Fprintf(out, "forbidden boilerplate %d of %d:\n", i+1, len(co.forbidden))
+		if err := co.explain(c, file, out); err != nil {
+			return err
+		}
+	}
+
+	// Report the violations that check would, from among those of each
+	// boilerplate.
+	violations, err := closest(checkers, co.open, file, file)
+	if err == nil {
+		var found []boilerplate.Violation
+		found, err = forbidden(co.forbidden, co.open, file, file)
+		violations = append(violations, found...)
+	}
+	if err != nil {
+		return fmt.Errorf("error reading %q: %v", file, err)
+	}
+	violations = co.dropAllowed(cmd, file, violations)
+	if len(violations) == 0 {
+		fmt.Fprintln(out, "result: no violations")
+		return nil
+	}
+	fmt.Fprintf(out, "result: %d violation(s):\n", len(violations))
+	for _, v := range violations {
+		s := v.String()
+		if s[len(s)-1] != '\n' {
+			s += "\n"
+		}
+		fmt.Fprint(out, s)
+	}
+	return &exitError{co.IssuesExitCode, fmt.Sprintf("found %d violations in %s", len(violations), file)}
+}
+
+// explain writes how c checks file to out.
+func (co *checkOptions) explain(c *boilerplate.Checker, file string, out io.Writer) error {
+	r, err := co.open(file)
+	if err != nil {
+		return fmt.Errorf("error reading %q: %v", file, err)
+	}
+	defer r.Close()
+	if _, err := c.Explain(file, r, out); err != nil {
+		return fmt.Errorf("error reading %q: %v", file, err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+		code int
+	}{{
+		name: "typo",
+		args: []string{"testdata/typo.bad.mm", "--file-extension", "mm"},
+		want: []string{
+			"testdata/typo.bad.mm: check would check it\n",
+			"boilerplate 1 of 1:\n",
+			"the boilerplate starts at line 1\n",
+			"line 1: ok: \"/*\"\n",
+			"line 2: differs:\n",
+			"result: 1 violation(s):\ntestdata/typo.bad.mm:2: found mismatched boilerplate lines:\n",
+		},
+		code: ExitViolations,
+	}, {
+		name: "good, but skipped",
+		args: []string{"testdata/old.good.mm", "--file-extension", "go"},
+		want: []string{
+			"testdata/old.good.mm: check would skip it: extension\n",
+			"line 16: ok: \"\"\n",
+			"result: no violations\n",
+		},
+	}, {
+		name: "missing",
+		args: []string{"testdata/missing.bad.mm", "--file-extension", "mm"},
+		want: []string{
+			"no line starts the boilerplate, whose first line is \"/*\"\n",
+			"result: 1 violation(s):\n",
+		},
+		code: ExitViolations,
+	}, {
+		name: "forbidden",
+		args: []string{"testdata/old.good.mm", "--file-extension", "mm", "--forbid", "testdata/forbidden.mm.txt"},
+		want: []string{
+			"boilerplate 1 of 1:\n",
+			"forbidden boilerplate 1 of 1:\n",
+		},
+	}, {
+		name: "script",
+		args: []string{"testdata/script", "--file-extension", "mm", "--allow-leading-lines", "--sniff-shebang", "--interpreter", "mmi=mm"},
+		want: []string{
+			"testdata/script: check would check it, as a .mm file per its shebang\n",
+			"result: no violations\n",
+		},
+	}, {
+		name: "incompatible",
+		args: []string{"testdata/old.good.mm", "--fix"},
+		code: ExitError,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewExplainCommand()
+			stdout := new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs(append([]string{"--boilerplate", "testdata/boilerplate.mm.txt"}, test.args...))
+
+			if got := ExitCode(cmd.Execute()); got != test.code {
+				t.Errorf("ExitCode() = %d, wanted %d", got, test.code)
+			}
+			got := stdout.String()
+			for _, want := range test.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("stdout = %s\nwanted it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ErrFromRequired = errors.New("--from is a required flag.")
+)
+
+// NewExtractCommand implements the `extract` sub-command
+func NewExtractCommand() *cobra.Command {
+	eo := &extractOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "extract",
+		Short:   "Writes a boilerplate file from the header of an existing file.",
+		PreRunE: eo.PreRunE,
+		RunE:    eo.RunE,
+	}
+	eo.AddFlags(cmd)
+	cmd.SetOut(os.Stdout)
+
+	return cmd
+}
+
+type extractOptions struct {
+	From   string
+	Lines  int
+	Output string
+}
+
+func (eo *extractOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&eo.From, "from", "", "",
+		"The path to a file whose header is correct.")
+	cmd.Flags().IntVarP(&eo.Lines, "lines", "", 0,
+		"The number of lines in the header, instead of the comment block at the top of the file.")
+	cmd.Flags().StringVarP(&eo.Output, "output", "", "",
+		"The path to write the boilerplate file to, instead of stdout.")
+}
+
+func (eo *extractOptions) PreRunE(cmd *cobra.Command, args []string) error {
+	if eo.From == "" {
+		return ErrFromRequired
+	}
+	if eo.Lines < 0 {
+		return fmt.Errorf("--lines %d may not be negative", eo.Lines)
+	}
+	return nil
+}
+
+func (eo *extractOptions) RunE(cmd *cobra.Command, args []string) error {
+	// Errors past flag validation don't warrant usage, and are
+	// reported by our caller.
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	f, err := os.Open(eo.From)
+	if err != nil {
+		return fmt.Errorf("error reading --from file %q: %v", eo.From, err)
+	}
+	defer f.Close()
+	lines, err := boilerplate.Extract(f, eo.Lines)
+	if err != nil {
+		return fmt.Errorf("error extracting header from %q: %v", eo.From, err)
+	}
+
+	// Each line is terminated, so the boilerplate requires the
+	// header to be followed by a blank line, like those we check.
+	var sb strings.Builder
+	for _, line := range lines {
+		sb.WriteString(line + "\n")
+	}
+	if eo.Output == "" {
+		cmd.Print(sb.String())
+		return nil
+	}
+	return ioutil.WriteFile(eo.Output, []byte(sb.String()), 0644)
+}
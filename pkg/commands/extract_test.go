@@ -0,0 +1,97 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+)
+
+func TestExtractPreRunE(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr error
+	}{{
+		name:    "no from",
+		wantErr: ErrFromRequired,
+	}, {
+		name:    "negative lines",
+		args:    []string{"--from", "testdata/old.good.mm", "--lines", "-1"},
+		wantErr: errors.New("--lines -1 may not be negative"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewExtractCommand()
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs(test.args)
+
+			if err := cmd.Execute(); err == nil || err.Error() != test.wantErr.Error() {
+				t.Errorf("Execute() = %v, wanted %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	bts, err := ioutil.ReadFile("testdata/boilerplate.mm.txt")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	want := boilerplate.Normalize(string(bts))
+
+	cmd := NewExtractCommand()
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetArgs([]string{"--from", "testdata/old.good.mm"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() = %v", err)
+	}
+	if got := stdout.String(); got != want {
+		t.Errorf("stdout = %q, wanted %q", got, want)
+	}
+
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	output := filepath.Join(dir, "boilerplate.txt")
+
+	cmd = NewExtractCommand()
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetArgs([]string{"--from", "testdata/old.good.mm", "--lines", "2", "--output", output})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() = %v", err)
+	}
+	got, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	if want := strings.Join(strings.SplitAfter(want, "\n")[:2], ""); string(got) != want {
+		t.Errorf("output = %q, wanted %q", string(got), want)
+	}
+}
@@ -0,0 +1,324 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+)
+
+// formatter renders the results of a check run.
+type formatter interface {
+	// Violation renders a violation as it is found.
+	Violation(v boilerplate.Violation) error
+	// Summary renders the summary once the run is complete.
+	Summary(s summary) error
+}
+
+// formatNames returns the sorted names of the --format values, which
+// are those of the formatters registered with the boilerplate package.
+func formatNames() []string {
+	return boilerplate.FormatterNames()
+}
+
+// hasFormat returns whether name is a --format value.
+func hasFormat(name string) bool {
+	_, ok := boilerplate.LookupFormatter(name)
+	return ok
+}
+
+// newFormatter returns the formatter for --format, which renders the
+// violations with the formatter registered by that name with the
+// boilerplate package, or nil if there is none.  Those that render each
+// violation on its own do so as it is found, and the rest once the run
+// is complete.  The summary is printed as text to stderr, except by the
+// json format, which adds it to the object it prints.
+func newFormatter(co *checkOptions, out, errOut io.Writer) formatter {
+	f, ok := boilerplate.LookupFormatter(co.Format)
+	if !ok {
+		return nil
+	}
+	if co.Format == "json" {
+		return &jsonFormatter{
+			out:        out,
+			f:          f,
+			violations: []boilerplate.Violation{},
+		}
+	}
+	tf := newTextFormatter(co, out, errOut)
+	if co.Format != "text" {
+		// Only the text format is colorized, or quieted.
+		tf.quiet, tf.color = false, false
+	}
+	if vf, ok := f.(boilerplate.ViolationFormatter); ok {
+		tf.f = vf
+		return tf
+	}
+	return &registeredFormatter{
+		textFormatter: tf,
+		f:             f,
+		violations:    []boilerplate.Violation{},
+	}
+}
+
+// colorModes are the --color values.
+var colorModes = []string{"auto", "always", "never"}
+
+// printFilesModes are the --print-files values.
+var printFilesModes = []string{"passing", "failing"}
+
+// fileFormatter is implemented by formatters that also render the
+// outcome of each file that is checked.
+type fileFormatter interface {
+	// File renders the outcome of checking the file reported by path.
+	File(path string, result outcome) error
+}
+
+// textFormatter prints each violation as it is found, by default in the
+// "path:line: message" form that reviewdog's errorformat consumes.  The
+// summary goes to stderr, so that it doesn't interfere with tools
+// parsing the violations.
+type textFormatter struct {
+	out, errOut io.Writer
+
+	// f renders each violation, unless it is colorized.
+	f boilerplate.ViolationFormatter
+
+	quiet     bool
+	noSummary bool
+	dryRun    bool
+	color     bool
+}
+
+func newTextFormatter(co *checkOptions, out, errOut io.Writer) *textFormatter {
+	f, _ := boilerplate.LookupFormatter("text")
+	return &textFormatter{
+		out:       out,
+		errOut:    errOut,
+		f:         f.(boilerplate.ViolationFormatter),
+		quiet:     co.Quiet,
+		noSummary: co.NoSummary,
+		dryRun:    co.DryRun,
+		color:     useColor(co.Color, out),
+	}
+}
+
+// ANSI escape sequences for colorized output, and to erase the rest of
+// the line for --progress.
+const (
+	ansiBold      = "\x1b[1m"
+	ansiRed       = "\x1b[31m"
+	ansiGreen     = "\x1b[32m"
+	ansiReset     = "\x1b[0m"
+	ansiClearLine = "\x1b[K"
+)
+
+// useColor returns whether output to w should be colorized for --color.
+func useColor(mode string, w io.Writer) bool {
+	switch mode {
+	case "always":
+		return true
+	case "auto":
+		// See https://no-color.org
+		if _, ok := os.LookupEnv("NO_COLOR"); ok {
+			return false
+		}
+		return isTerminal(w)
+	default:
+		return false
+	}
+}
+
+// isTerminal returns whether w is a terminal, rather than a file or pipe.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorize formats the violation with the location in bold and, for
+// mismatches, the removed and added lines of the diff in red and green.
+func colorize(v boilerplate.Violation) string {
+	return fmt.Sprintf("%s%s:%s %s", ansiBold, v.Location(), ansiReset, colorizeMessage(v))
+}
+
+// colorizeMessage formats the message of the violation, with the removed
+// and added lines of the diff of mismatches in red and green.
+func colorizeMessage(v boilerplate.Violation) string {
+	var sb strings.Builder
+	if v.Severity == boilerplate.SeverityWarning {
+		sb.WriteString("warning: ")
+	}
+	for _, line := range strings.SplitAfter(v.Message(), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		switch {
+		case v.Kind != boilerplate.Mismatch || line == "":
+			sb.WriteString(line)
+		case strings.HasPrefix(trimmed, "-"):
+			sb.WriteString(ansiRed + strings.TrimSuffix(line, "\n") + ansiReset + "\n")
+		case strings.HasPrefix(trimmed, "+"):
+			sb.WriteString(ansiGreen + strings.TrimSuffix(line, "\n") + ansiReset + "\n")
+		default:
+			sb.WriteString(line)
+		}
+	}
+	return sb.String()
+}
+
+func (tf *textFormatter) Violation(v boilerplate.Violation) error {
+	if tf.quiet {
+		return nil
+	}
+	if !tf.color {
+		return tf.f.FormatViolation(tf.out, v)
+	}
+	s := colorize(v)
+	// Not every message ends with a newline, e.g. those of
+	// violations that concern the whole file.
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	_, err := fmt.Fprint(tf.out, s)
+	return err
+}
+
+func (tf *textFormatter) Summary(s summary) error {
+	if tf.noSummary {
+		return nil
+	}
+	fmt.Fprintf(tf.errOut, "checked %d files, %d violations in %d files",
+		s.Checked, s.Violations, s.Failed)
+	if s.Warnings > 0 {
+		fmt.Fprintf(tf.errOut, ", %d warnings", s.Warnings)
+	}
+	if s.Fixed > 0 && !tf.dryRun {
+		fmt.Fprintf(tf.errOut, ", fixed %d files", s.Fixed)
+	}
+	if _, err := fmt.Fprintln(tf.errOut); err != nil {
+		return err
+	}
+	for _, ext := range s.extensions() {
+		if _, err := fmt.Fprintf(tf.errOut, "  %s: %d\n", ext, s.ByExtension[ext]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countFormatter prints only the number of violations (or of the
+// files with violations) once the run is complete, for --count.  Like
+// the exit status, it does not count warnings.
+type countFormatter struct {
+	out   io.Writer
+	files bool
+}
+
+func (cf *countFormatter) Violation(v boilerplate.Violation) error {
+	return nil
+}
+
+func (cf *countFormatter) Summary(s summary) error {
+	n := s.Violations
+	if cf.files {
+		n = s.Failed
+	}
+	_, err := fmt.Fprintln(cf.out, n)
+	return err
+}
+
+// filesFormatter prints only the paths of the files that pass (or those
+// that fail), one per line, for --print-files.
+type filesFormatter struct {
+	out     io.Writer
+	failing bool
+}
+
+func (ff *filesFormatter) Violation(v boilerplate.Violation) error {
+	return nil
+}
+
+func (ff *filesFormatter) Summary(s summary) error {
+	return nil
+}
+
+func (ff *filesFormatter) File(path string, result outcome) error {
+	if (result == violation) != ff.failing {
+		return nil
+	}
+	_, err := fmt.Fprintln(ff.out, path)
+	return err
+}
+
+// jsonFormatter prints a single JSON object holding the violations, as
+// the json formatter renders them, and the summary once the run is
+// complete.
+type jsonFormatter struct {
+	out io.Writer
+
+	f          boilerplate.Formatter
+	violations []boilerplate.Violation
+}
+
+func (jf *jsonFormatter) Violation(v boilerplate.Violation) error {
+	jf.violations = append(jf.violations, v)
+	return nil
+}
+
+func (jf *jsonFormatter) Summary(s summary) error {
+	var violations bytes.Buffer
+	if err := jf.f.Format(&violations, jf.violations); err != nil {
+		return err
+	}
+	return json.NewEncoder(jf.out).Encode(struct {
+		Violations json.RawMessage `json:"violations"`
+		Summary    summary         `json:"summary"`
+	}{
+		Violations: violations.Bytes(),
+		Summary:    s,
+	})
+}
+
+// registeredFormatter renders the violations with a formatter
+// registered with the boilerplate package once the run is complete.
+type registeredFormatter struct {
+	// The summary is printed as text to stderr.
+	*textFormatter
+
+	f          boilerplate.Formatter
+	violations []boilerplate.Violation
+}
+
+func (rf *registeredFormatter) Violation(v boilerplate.Violation) error {
+	rf.violations = append(rf.violations, v)
+	return nil
+}
+
+func (rf *registeredFormatter) Summary(s summary) error {
+	if err := rf.f.Format(rf.out, rf.violations); err != nil {
+		return err
+	}
+	return rf.textFormatter.Summary(s)
+}
@@ -0,0 +1,275 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+)
+
+func TestFormatters(t *testing.T) {
+	v := boilerplate.Violation{
+		Path:   "foo.go",
+		Line:   2,
+		Kind:   boilerplate.Mismatch,
+		Detail: "\t-: \"Copyright YYYY Matt Moore\"\n\t+: \"Copyright YYYY Matt More\"\n",
+	}
+	s := summary{Checked: 3, Passed: 1, Failed: 1, Violations: 1, Fixed: 1}
+
+	tests := []struct {
+		name       string
+		co         checkOptions
+		wantOut    string
+		wantErrOut string
+	}{{
+		name:       "text",
+		co:         checkOptions{Format: "text"},
+		wantOut:    "foo.go:2: found mismatched boilerplate lines:\n\t-: \"Copyright YYYY Matt Moore\"\n\t+: \"Copyright YYYY Matt More\"\n",
+		wantErrOut: "checked 3 files, 1 violations in 1 files, fixed 1 files\n",
+	}, {
+		name:       "text with dry run",
+		co:         checkOptions{Format: "text", DryRun: true},
+		wantOut:    "foo.go:2: found mismatched boilerplate lines:\n\t-: \"Copyright YYYY Matt Moore\"\n\t+: \"Copyright YYYY Matt More\"\n",
+		wantErrOut: "checked 3 files, 1 violations in 1 files\n",
+	}, {
+		name:       "quiet text",
+		co:         checkOptions{Format: "text", Quiet: true},
+		wantErrOut: "checked 3 files, 1 violations in 1 files, fixed 1 files\n",
+	}, {
+		name: "quiet text without summary",
+		co:   checkOptions{Format: "text", Quiet: true, NoSummary: true},
+	}, {
+		name: "colorized text",
+		co:   checkOptions{Format: "text", Color: "always"},
+		wantOut: "\x1b[1mfoo.go:2:\x1b[0m found mismatched boilerplate lines:\n" +
+			"\x1b[31m\t-: \"Copyright YYYY Matt Moore\"\x1b[0m\n" +
+			"\x1b[32m\t+: \"Copyright YYYY Matt More\"\x1b[0m\n",
+		wantErrOut: "checked 3 files, 1 violations in 1 files, fixed 1 files\n",
+	}, {
+		name:       "text to a non-terminal",
+		co:         checkOptions{Format: "text", Color: "auto"},
+		wantOut:    "foo.go:2: found mismatched boilerplate lines:\n\t-: \"Copyright YYYY Matt Moore\"\n\t+: \"Copyright YYYY Matt More\"\n",
+		wantErrOut: "checked 3 files, 1 violations in 1 files, fixed 1 files\n",
+	}, {
+		name: "rdjsonl",
+		co:   checkOptions{Format: "rdjsonl"},
+		wantOut: `{"message":"found mismatched boilerplate lines:\n\t-: \"Copyright YYYY Matt Moore\"\n\t+: \"Copyright YYYY Matt More\"\n",` +
+			`"location":{"path":"foo.go","range":{"start":{"line":2}}},"severity":"ERROR","source":{"name":"boilerplate-check"}}` + "\n",
+		wantErrOut: "checked 3 files, 1 violations in 1 files, fixed 1 files\n",
+	}, {
+		name:       "github",
+		co:         checkOptions{Format: "github"},
+		wantOut:    "::error file=foo.go,line=2::found mismatched boilerplate lines:%0A\t-: \"Copyright YYYY Matt Moore\"%0A\t+: \"Copyright YYYY Matt More\"\n",
+		wantErrOut: "checked 3 files, 1 violations in 1 files, fixed 1 files\n",
+	}, {
+		name: "json",
+		co:   checkOptions{Format: "json"},
+		wantOut: `{"violations":[{"path":"foo.go","line":2,"kind":"mismatch","severity":"error","detail":"\t-: \"Copyright YYYY Matt Moore\"\n\t+: \"Copyright YYYY Matt More\"\n"}],` +
+			`"summary":{"checked":3,"passed":1,"failed":1,"violations":1,"fixed":1}}` + "\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+			f := newFormatter(&test.co, out, errOut)
+			if err := f.Violation(v); err != nil {
+				t.Errorf("Violation() = %v", err)
+			}
+			if err := f.Summary(s); err != nil {
+				t.Errorf("Summary() = %v", err)
+			}
+			if got := out.String(); got != test.wantOut {
+				t.Errorf("out = %q, wanted %q", got, test.wantOut)
+			}
+			if got := errOut.String(); got != test.wantErrOut {
+				t.Errorf("errOut = %q, wanted %q", got, test.wantErrOut)
+			}
+		})
+	}
+}
+
+func TestFormattersWarning(t *testing.T) {
+	v := boilerplate.Violation{
+		Path:     "foo.go",
+		Line:     2,
+		Kind:     boilerplate.Outdated,
+		Severity: boilerplate.SeverityWarning,
+		Detail:   "2019",
+	}
+	s := summary{Checked: 1, Passed: 1, Warnings: 1}
+
+	tests := []struct {
+		name       string
+		co         checkOptions
+		wantOut    string
+		wantErrOut string
+	}{{
+		name:       "text",
+		co:         checkOptions{Format: "text"},
+		wantOut:    "foo.go:2: warning: copyright year is out of date: 2019\n",
+		wantErrOut: "checked 1 files, 0 violations in 0 files, 1 warnings\n",
+	}, {
+		name:       "colorized text",
+		co:         checkOptions{Format: "text", Color: "always"},
+		wantOut:    "\x1b[1mfoo.go:2:\x1b[0m warning: copyright year is out of date: 2019\n",
+		wantErrOut: "checked 1 files, 0 violations in 0 files, 1 warnings\n",
+	}, {
+		name: "rdjsonl",
+		co:   checkOptions{Format: "rdjsonl"},
+		wantOut: `{"message":"copyright year is out of date: 2019",` +
+			`"location":{"path":"foo.go","range":{"start":{"line":2}}},"severity":"WARNING","source":{"name":"boilerplate-check"}}` + "\n",
+		wantErrOut: "checked 1 files, 0 violations in 0 files, 1 warnings\n",
+	}, {
+		name:       "github",
+		co:         checkOptions{Format: "github"},
+		wantOut:    "::warning file=foo.go,line=2::copyright year is out of date: 2019\n",
+		wantErrOut: "checked 1 files, 0 violations in 0 files, 1 warnings\n",
+	}, {
+		name: "json",
+		co:   checkOptions{Format: "json"},
+		wantOut: `{"violations":[{"path":"foo.go","line":2,"kind":"outdated","severity":"warning","detail":"2019"}],` +
+			`"summary":{"checked":1,"passed":1,"failed":0,"violations":0,"warnings":1}}` + "\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+			f := newFormatter(&test.co, out, errOut)
+			if err := f.Violation(v); err != nil {
+				t.Errorf("Violation() = %v", err)
+			}
+			if err := f.Summary(s); err != nil {
+				t.Errorf("Summary() = %v", err)
+			}
+			if got := out.String(); got != test.wantOut {
+				t.Errorf("out = %q, wanted %q", got, test.wantOut)
+			}
+			if got := errOut.String(); got != test.wantErrOut {
+				t.Errorf("errOut = %q, wanted %q", got, test.wantErrOut)
+			}
+		})
+	}
+}
+
+func TestFormattersColumn(t *testing.T) {
+	v := boilerplate.Violation{
+		Path:   "foo.go",
+		Line:   2,
+		Column: 23,
+		Kind:   boilerplate.Mismatch,
+		Detail: "diff\n",
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{{
+		format: "text",
+		want:   "foo.go:2:23: found mismatched boilerplate lines:\ndiff\n",
+	}, {
+		format: "rdjsonl",
+		want: `{"message":"found mismatched boilerplate lines:\ndiff\n",` +
+			`"location":{"path":"foo.go","range":{"start":{"line":2,"column":23}}},"severity":"ERROR","source":{"name":"boilerplate-check"}}` + "\n",
+	}, {
+		format: "github",
+		want:   "::error file=foo.go,line=2,col=23::found mismatched boilerplate lines:%0Adiff\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			f := newFormatter(&checkOptions{Format: test.format}, out, new(bytes.Buffer))
+			if err := f.Violation(v); err != nil {
+				t.Errorf("Violation() = %v", err)
+			}
+			if got := out.String(); got != test.want {
+				t.Errorf("out = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTextFormatterNewline(t *testing.T) {
+	v := boilerplate.Violation{
+		Path:   "foo.go",
+		Kind:   boilerplate.Unreadable,
+		Detail: "permission denied",
+	}
+	for _, color := range []string{"never", "always"} {
+		out := new(bytes.Buffer)
+		f := newFormatter(&checkOptions{Format: "text", Color: color}, out, new(bytes.Buffer))
+		if err := f.Violation(v); err != nil {
+			t.Errorf("Violation() = %v", err)
+		}
+		if got := out.String(); !strings.HasSuffix(got, "could not read: permission denied\n") {
+			t.Errorf("out = %q, wanted a terminated line", got)
+		}
+	}
+}
+
+func TestRegisteredFormatter(t *testing.T) {
+	v := boilerplate.Violation{
+		Path:   "foo.go",
+		Line:   2,
+		Kind:   boilerplate.Mismatch,
+		Detail: "diff\n",
+	}
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	// The sarif formatter is registered with the boilerplate package.
+	f := newFormatter(&checkOptions{Format: "sarif"}, out, errOut)
+	if err := f.Violation(v); err != nil {
+		t.Errorf("Violation() = %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("out = %q before the summary, wanted nothing", out)
+	}
+	if err := f.Summary(summary{Checked: 1, Failed: 1, Violations: 1}); err != nil {
+		t.Errorf("Summary() = %v", err)
+	}
+	if want := `"ruleId": "mismatch"`; !strings.Contains(out.String(), want) {
+		t.Errorf("out = %s\nwanted it to contain %s", out, want)
+	}
+	if got, want := errOut.String(), "checked 1 files, 1 violations in 1 files\n"; got != want {
+		t.Errorf("errOut = %q, wanted %q", got, want)
+	}
+	if newFormatter(&checkOptions{Format: "yaml"}, out, errOut) != nil {
+		t.Error("newFormatter(yaml) = non-nil, wanted nil")
+	}
+}
+
+func TestTextFormatterByExtension(t *testing.T) {
+	s := summary{
+		Checked:     20,
+		Failed:      18,
+		Violations:  18,
+		Warnings:    2,
+		ByExtension: map[string]int{"go": 3, "sh": 15, "py": 3},
+	}
+	errOut := new(bytes.Buffer)
+	f := newFormatter(&checkOptions{Format: "text"}, new(bytes.Buffer), errOut)
+	if err := f.Summary(s); err != nil {
+		t.Errorf("Summary() = %v", err)
+	}
+	// The extensions with the most violations come first.
+	want := "checked 20 files, 18 violations in 18 files, 2 warnings\n  sh: 15\n  go: 3\n  py: 3\n"
+	if got := errOut.String(); got != want {
+		t.Errorf("errOut = %q, wanted %q", got, want)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+)
+
+// groupFormatter holds onto the violations until the run is complete,
+// and then prints them under the path of each file, in the order the
+// files were first reported, for --group-by-file.
+type groupFormatter struct {
+	// The summary is printed as text to stderr.
+	*textFormatter
+
+	paths      []string
+	violations map[string][]boilerplate.Violation
+}
+
+func newGroupFormatter(tf *textFormatter) *groupFormatter {
+	return &groupFormatter{
+		textFormatter: tf,
+		violations:    make(map[string][]boilerplate.Violation),
+	}
+}
+
+func (gf *groupFormatter) Violation(v boilerplate.Violation) error {
+	if gf.quiet {
+		return nil
+	}
+	if _, ok := gf.violations[v.Path]; !ok {
+		gf.paths = append(gf.paths, v.Path)
+	}
+	gf.violations[v.Path] = append(gf.violations[v.Path], v)
+	return nil
+}
+
+func (gf *groupFormatter) Summary(s summary) error {
+	for i, path := range gf.paths {
+		var sb strings.Builder
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		if gf.color {
+			sb.WriteString(ansiBold + path + ansiReset + "\n")
+		} else {
+			sb.WriteString(path + "\n")
+		}
+		for _, v := range gf.violations[path] {
+			sb.WriteString(gf.format(v))
+		}
+		if _, err := fmt.Fprint(gf.out, sb.String()); err != nil {
+			return err
+		}
+	}
+	return gf.textFormatter.Summary(s)
+}
+
+// format returns the violation as "line: message", indented beneath
+// the path that heads its group.
+func (gf *groupFormatter) format(v boilerplate.Violation) string {
+	var loc string
+	switch {
+	case v.Line == 0:
+	case v.Column == 0:
+		loc = fmt.Sprintf("%d", v.Line)
+	default:
+		loc = fmt.Sprintf("%d:%d", v.Line, v.Column)
+	}
+
+	s := v.Message()
+	if v.Severity == boilerplate.SeverityWarning {
+		s = "warning: " + s
+	}
+	if gf.color {
+		s = colorizeMessage(v)
+		if loc != "" {
+			loc = ansiBold + loc + ":" + ansiReset
+		}
+	} else if loc != "" {
+		loc += ":"
+	}
+	if loc != "" {
+		s = loc + " " + s
+	}
+	// The lines of the message are indented too, so that the next
+	// path stands out.
+	var sb strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+		if line != "" {
+			sb.WriteString("  ")
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+)
+
+func TestGroupFormatter(t *testing.T) {
+	violations := []boilerplate.Violation{{
+		Path:   "foo.go",
+		Line:   2,
+		Kind:   boilerplate.Mismatch,
+		Detail: "\t-: \"Copyright YYYY Matt Moore\"\n\t+: \"Copyright YYYY Matt More\"\n",
+	}, {
+		Path:   "bar.go",
+		Line:   1,
+		Kind:   boilerplate.Missing,
+		Detail: "/*\n\nCopyright YYYY Matt Moore\n*/\n",
+	}, {
+		Path:     "foo.go",
+		Line:     4,
+		Column:   81,
+		Kind:     boilerplate.Overlong,
+		Severity: boilerplate.SeverityWarning,
+		Detail:   "// Extra",
+	}}
+	s := summary{Checked: 3, Passed: 1, Failed: 2, Violations: 2, Warnings: 1}
+
+	tests := []struct {
+		name       string
+		co         checkOptions
+		wantOut    string
+		wantErrOut string
+	}{{
+		name: "text",
+		co:   checkOptions{Format: "text"},
+		wantOut: "foo.go\n" +
+			"  2: found mismatched boilerplate lines:\n" +
+			"  \t-: \"Copyright YYYY Matt Moore\"\n" +
+			"  \t+: \"Copyright YYYY Matt More\"\n" +
+			"  4:81: warning: header continues past the boilerplate: // Extra\n" +
+			"\n" +
+			"bar.go\n" +
+			"  1: missing boilerplate:\n" +
+			"  /*\n" +
+			"\n" +
+			"  Copyright YYYY Matt Moore\n" +
+			"  */\n",
+		wantErrOut: "checked 3 files, 2 violations in 2 files, 1 warnings\n",
+	}, {
+		name: "colorized text",
+		co:   checkOptions{Format: "text", Color: "always", NoSummary: true},
+		wantOut: "\x1b[1mfoo.go\x1b[0m\n" +
+			"  \x1b[1m2:\x1b[0m found mismatched boilerplate lines:\n" +
+			"  \x1b[31m\t-: \"Copyright YYYY Matt Moore\"\x1b[0m\n" +
+			"  \x1b[32m\t+: \"Copyright YYYY Matt More\"\x1b[0m\n" +
+			"  \x1b[1m4:81:\x1b[0m warning: header continues past the boilerplate: // Extra\n" +
+			"\n" +
+			"\x1b[1mbar.go\x1b[0m\n" +
+			"  \x1b[1m1:\x1b[0m missing boilerplate:\n" +
+			"  /*\n" +
+			"\n" +
+			"  Copyright YYYY Matt Moore\n" +
+			"  */\n",
+	}, {
+		name:       "quiet",
+		co:         checkOptions{Format: "text", Quiet: true},
+		wantErrOut: "checked 3 files, 2 violations in 2 files, 1 warnings\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+			f := newGroupFormatter(newTextFormatter(&test.co, out, errOut))
+			for _, v := range violations {
+				if err := f.Violation(v); err != nil {
+					t.Errorf("Violation() = %v", err)
+				}
+			}
+			// Nothing is printed until the run is complete.
+			if got := out.String(); got != "" {
+				t.Errorf("out before Summary() = %q, wanted nothing", got)
+			}
+			if err := f.Summary(s); err != nil {
+				t.Errorf("Summary() = %v", err)
+			}
+			if got := out.String(); got != test.wantOut {
+				t.Errorf("out = %q, wanted %q", got, test.wantOut)
+			}
+			if got := errOut.String(); got != test.wantErrOut {
+				t.Errorf("errOut = %q, wanted %q", got, test.wantErrOut)
+			}
+		})
+	}
+}
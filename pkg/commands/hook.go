@@ -0,0 +1,157 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	ErrCheckFlagsRequired = errors.New("the flags to run check with are required, after --.")
+)
+
+// hookMarker identifies the hooks that we installed, which we may
+// replace without --force.
+const hookMarker = "# Installed by `boilerplate-check hook install`"
+
+// NewHookCommand implements the `hook` sub-command
+func NewHookCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hook",
+		Short: "Manages a git pre-commit hook that checks the staged files.",
+	}
+	cmd.AddCommand(NewHookInstallCommand())
+	return cmd
+}
+
+// NewHookInstallCommand implements the `hook install` sub-command
+func NewHookInstallCommand() *cobra.Command {
+	ho := &hookOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "install -- CHECK_FLAGS...",
+		Short: "Installs a git pre-commit hook that checks the staged files.",
+		Example: `  boilerplate-check hook install -- \
+    --boilerplate ./hack/boilerplate/boilerplate.go.txt --file-extension go`,
+		PreRunE: ho.PreRunE,
+		RunE:    ho.RunE,
+	}
+	ho.AddFlags(cmd)
+	cmd.SetOut(os.Stdout)
+
+	return cmd
+}
+
+type hookOptions struct {
+	Force    bool
+	HooksDir string
+}
+
+func (ho *hookOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&ho.Force, "force", "", false,
+		"Replace an existing pre-commit hook that was not installed by boilerplate-check.")
+	cmd.Flags().StringVarP(&ho.HooksDir, "hooks-dir", "", "",
+		"The directory to install the hook in, instead of the repository's hooks directory.")
+}
+
+func (ho *hookOptions) PreRunE(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return ErrCheckFlagsRequired
+	}
+	// Catch mistakes in the flags now, rather than at the next commit.
+	check := NewCheckCommand()
+	if err := check.ParseFlags(args); err != nil {
+		return fmt.Errorf("error parsing the flags to run check with: %v", err)
+	}
+	for _, name := range []string{"files-from", "files-from0", "root"} {
+		if check.Flags().Changed(name) {
+			return fmt.Errorf("--%s may not be passed to check, since the hook passes the staged files", name)
+		}
+	}
+	return nil
+}
+
+func (ho *hookOptions) RunE(cmd *cobra.Command, args []string) error {
+	// Errors past flag validation don't warrant usage, and are
+	// reported by our caller.
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	dir := ho.HooksDir
+	if dir == "" {
+		// This respects core.hooksPath, and works in worktrees.
+		out, err := exec.Command("git", "rev-parse", "--git-path", "hooks").Output()
+		if err != nil {
+			return fmt.Errorf("error finding the git hooks directory: %v", err)
+		}
+		dir = strings.TrimSpace(string(out))
+	}
+	path := filepath.Join(dir, "pre-commit")
+	script := hookScript(args)
+
+	existing, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return err
+	case string(existing) == script:
+		cmd.Printf("%s is up to date\n", path)
+		return nil
+	case !strings.Contains(string(existing), hookMarker) && !ho.Force:
+		return fmt.Errorf("%s was not installed by boilerplate-check, pass --force to replace it", path)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, []byte(script), 0755); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file, which git
+	// ignores unless it is executable.
+	if err := os.Chmod(path, 0755); err != nil {
+		return err
+	}
+	cmd.Printf("installed %s\n", path)
+	return nil
+}
+
+// hookScript returns a pre-commit hook that runs check with args on
+// the files staged to be committed, failing if it reports violations.
+func hookScript(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	return "#!/bin/sh\n" +
+		hookMarker + ", which may be rerun to update it.\n" +
+		"git diff --cached -z --name-only --diff-filter=d |\n" +
+		"  boilerplate-check check --files-from0 - " + strings.Join(quoted, " ") + "\n"
+}
+
+// shellQuote quotes s as a single word for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHookInstallPreRunE(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr error
+	}{{
+		name:    "no check flags",
+		wantErr: ErrCheckFlagsRequired,
+	}, {
+		name:    "unknown check flag",
+		args:    []string{"--", "--bolierplate", "foo.txt"},
+		wantErr: errors.New("error parsing the flags to run check with: unknown flag: --bolierplate"),
+	}, {
+		name:    "files from",
+		args:    []string{"--", "--boilerplate", "foo.txt", "--files-from", "-"},
+		wantErr: errors.New("--files-from may not be passed to check, since the hook passes the staged files"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewHookInstallCommand()
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs(test.args)
+
+			if err := cmd.Execute(); err == nil || err.Error() != test.wantErr.Error() {
+				t.Errorf("Execute() = %v, wanted %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestHookInstall(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "hooks", "pre-commit")
+
+	install := func(args ...string) (string, error) {
+		cmd := NewHookInstallCommand()
+		stdout := new(bytes.Buffer)
+		cmd.SetOut(stdout)
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetArgs(append([]string{"--hooks-dir", filepath.Join(dir, "hooks")}, args...))
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	check := []string{"--", "--boilerplate", "it's.txt", "--file-extension", "go"}
+	if got, err := install(check...); err != nil {
+		t.Fatalf("Execute() = %v", err)
+	} else if want := "installed " + path + "\n"; got != want {
+		t.Errorf("stdout = %q, wanted %q", got, want)
+	}
+	bts, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	if want := `boilerplate-check check --files-from0 - '--boilerplate' 'it'\''s.txt' '--file-extension' 'go'`; !strings.Contains(string(bts), want) {
+		t.Errorf("hook = %s, wanted substring %q", bts, want)
+	}
+	if info, err := os.Stat(path); err != nil {
+		t.Fatalf("Stat() = %v", err)
+	} else if info.Mode()&0111 == 0 {
+		t.Errorf("hook mode = %v, wanted executable", info.Mode())
+	}
+
+	// Installing again changes nothing, and other flags update the hook.
+	if got, err := install(check...); err != nil {
+		t.Errorf("Execute() = %v", err)
+	} else if want := path + " is up to date\n"; got != want {
+		t.Errorf("stdout = %q, wanted %q", got, want)
+	}
+	if _, err := install("--", "--spdx", "Apache-2.0", "--file-extension", "go"); err != nil {
+		t.Errorf("Execute() = %v", err)
+	}
+
+	// Other hooks are only replaced with --force.
+	if err := ioutil.WriteFile(path, []byte("#!/bin/sh\nmake lint\n"), 0755); err != nil {
+		t.Fatalf("WriteFile() = %v", err)
+	}
+	if _, err := install(check...); err == nil {
+		t.Error("Execute() = nil, wanted error")
+	}
+	if bts, err := ioutil.ReadFile(path); err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	} else if got, want := string(bts), "#!/bin/sh\nmake lint\n"; got != want {
+		t.Errorf("hook = %s, wanted %s", got, want)
+	}
+	if _, err := install(append([]string{"--force"}, check...)...); err != nil {
+		t.Errorf("Execute() = %v", err)
+	}
+}
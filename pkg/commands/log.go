@@ -0,0 +1,87 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// logFormats are the --log-format values.
+var logFormats = []string{"text", "json"}
+
+// logLevel is how important a logged message is.
+type logLevel int
+
+const (
+	// debugLevel is for the fate of each file considered.
+	debugLevel logLevel = iota
+	// infoLevel is for the progress of the run as a whole, and the
+	// problems it continues past.
+	infoLevel
+	// warnLevel is for problems that may well cause a run to give the
+	// wrong results.  Problems that end a run are returned instead.
+	warnLevel
+)
+
+// logLevels are the --log-level values, indexed by logLevel.
+var logLevels = []string{"debug", "info", "warn"}
+
+// logger writes operational messages to stderr, apart from the results
+// of a run, as lines of text or of JSON.
+type logger struct {
+	out   io.Writer
+	json  bool
+	level logLevel
+}
+
+// logEntry is a message as it is logged in JSON.
+type logEntry struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"msg"`
+}
+
+// logf logs a message about path (or the run as a whole, if path is "")
+// at level, unless the logger is set to a more important level.
+func (l *logger) logf(level logLevel, path, format string, a ...interface{}) {
+	if level < l.level {
+		return
+	}
+	msg := fmt.Sprintf(format, a...)
+	if l.json {
+		// Encoding strings can't fail.
+		bts, _ := json.Marshal(logEntry{
+			Time:    time.Now().UTC().Format(time.RFC3339Nano),
+			Level:   logLevels[level],
+			Path:    path,
+			Message: msg,
+		})
+		fmt.Fprintf(l.out, "%s\n", bts)
+		return
+	}
+	if path != "" {
+		msg = path + ": " + msg
+	}
+	if level == warnLevel {
+		msg = "warning: " + msg
+	}
+	fmt.Fprintln(l.out, msg)
+}
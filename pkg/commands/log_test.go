@@ -0,0 +1,70 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestLoggerText(t *testing.T) {
+	out := new(bytes.Buffer)
+	l := &logger{out: out, level: infoLevel}
+	l.logf(debugLevel, "foo.go", "checked")
+	l.logf(infoLevel, "foo.go", "could not read, continuing: %s", "permission denied")
+	l.logf(warnLevel, "", "%s has trailing whitespace", "--boilerplate file")
+
+	want := "foo.go: could not read, continuing: permission denied\n" +
+		"warning: --boilerplate file has trailing whitespace\n"
+	if got := out.String(); got != want {
+		t.Errorf("logf() = %q, wanted %q", got, want)
+	}
+}
+
+func TestLoggerJSON(t *testing.T) {
+	out := new(bytes.Buffer)
+	l := &logger{out: out, json: true, level: debugLevel}
+	l.logf(debugLevel, "foo.go", "skipped: %s", "extension")
+	l.logf(warnLevel, "", "%s has trailing whitespace", "--boilerplate file")
+
+	want := []logEntry{{
+		Level:   "debug",
+		Path:    "foo.go",
+		Message: "skipped: extension",
+	}, {
+		Level:   "warn",
+		Message: "--boilerplate file has trailing whitespace",
+	}}
+	dec := json.NewDecoder(out)
+	for _, want := range want {
+		var got logEntry
+		if err := dec.Decode(&got); err != nil {
+			t.Fatalf("Decode() = %v", err)
+		}
+		if got.Time == "" {
+			t.Errorf("Time = %q, wanted a time", got.Time)
+		}
+		got.Time = ""
+		if got != want {
+			t.Errorf("Decode() = %+v, wanted %+v", got, want)
+		}
+	}
+	if dec.More() {
+		t.Errorf("logf() logged more than %d entries", len(want))
+	}
+}
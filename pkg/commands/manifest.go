@@ -0,0 +1,253 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	ErrManifestRequired = errors.New("--manifest is a required flag.")
+)
+
+// manifest lists the directories that check-all checks, each with the
+// flags to check it with.
+type manifest struct {
+	Directories []manifestEntry `json:"directories"`
+}
+
+// decodeManifest decodes the manifest in data, which is JSON or YAML.
+func decodeManifest(data []byte) (manifest, error) {
+	var m manifest
+	if !isJSON(data) {
+		var err error
+		if data, err = yaml.YAMLToJSON(data); err != nil {
+			return m, err
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// Catch misspelled fields, which would otherwise be ignored.
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&m)
+	return m, err
+}
+
+// isJSON returns whether data is a JSON manifest, i.e. an object, rather
+// than YAML, which we decode by converting it to JSON.
+func isJSON(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{'
+}
+
+// manifestEntry configures the check of a directory.  Its paths are
+// relative to the directory, which is relative to the manifest.
+type manifestEntry struct {
+	Dir         string   `json:"dir"`
+	Boilerplate []string `json:"boilerplate"`
+	Extensions  []string `json:"extensions"`
+	Exclude     string   `json:"exclude"`
+	// Flags are any other flags to run check with.
+	Flags []string `json:"flags"`
+}
+
+// NewCheckAllCommand implements the `check-all` sub-command
+func NewCheckAllCommand() *cobra.Command {
+	mo := &manifestOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "check-all --manifest MANIFEST [-- CHECK_FLAGS...]",
+		Short:   "Checks several directories, each with its own boilerplate, in one run.",
+		Example: `  boilerplate-check check-all --manifest repos.yaml -- --allow-leading-lines`,
+		PreRunE: mo.PreRunE,
+		RunE:    mo.RunE,
+	}
+	mo.AddFlags(cmd)
+	cmd.SetOut(os.Stdout)
+
+	return cmd
+}
+
+type manifestOptions struct {
+	Manifest       string
+	IssuesExitCode int
+
+	manifest manifest
+}
+
+func (mo *manifestOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&mo.Manifest, "manifest", "", "",
+		"A JSON or YAML file listing the directories to check and how.")
+	cmd.Flags().IntVarP(&mo.IssuesExitCode, "issues-exit-code", "", ExitViolations,
+		"The exit status when violations are found, like golangci-lint's.")
+}
+
+func (mo *manifestOptions) PreRunE(cmd *cobra.Command, args []string) error {
+	if mo.Manifest == "" {
+		return ErrManifestRequired
+	}
+	if err := validateIssuesExitCode(mo.IssuesExitCode); err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(mo.Manifest)
+	if err != nil {
+		return fmt.Errorf("error reading --manifest %q: %v", mo.Manifest, err)
+	}
+	if mo.manifest, err = decodeManifest(data); err != nil {
+		return fmt.Errorf("error parsing --manifest %q: %v", mo.Manifest, err)
+	}
+	if len(mo.manifest.Directories) == 0 {
+		return fmt.Errorf("--manifest %q lists no directories", mo.Manifest)
+	}
+	for i, entry := range mo.manifest.Directories {
+		if entry.Dir == "" {
+			return fmt.Errorf("directory %d of --manifest %q has no dir", i+1, mo.Manifest)
+		}
+	}
+	return nil
+}
+
+func (mo *manifestOptions) RunE(cmd *cobra.Command, args []string) error {
+	// Errors past flag validation don't warrant usage, and are
+	// reported by our caller.
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	var total summary
+	failed, broken := 0, 0
+	for _, entry := range mo.manifest.Directories {
+		root := filepath.Join(filepath.Dir(mo.Manifest), entry.Dir)
+		flags := append(append([]string{}, args...), entry.args(root)...)
+		s, err := checkDir(cmd, root, flags)
+		switch ExitCode(err) {
+		case 0:
+		case ExitViolations:
+			failed++
+		default:
+			// Report the error, and move on to the next directory.
+			fmt.Fprintf(cmd.ErrOrStderr(), "ERROR: %s: %v\n", root, err)
+			broken++
+			continue
+		}
+		total.Checked += s.Checked
+		total.Passed += s.Passed
+		total.Failed += s.Failed
+		total.Violations += s.Violations
+		total.Warnings += s.Warnings
+		total.Fixed += s.Fixed
+		for ext, n := range s.ByExtension {
+			if total.ByExtension == nil {
+				total.ByExtension = make(map[string]int)
+			}
+			total.ByExtension[ext] += n
+		}
+	}
+
+	n := len(mo.manifest.Directories)
+	fmt.Fprintf(cmd.ErrOrStderr(), "%d directories: ", n)
+	if err := (&textFormatter{errOut: cmd.ErrOrStderr()}).Summary(total); err != nil {
+		return err
+	}
+	switch {
+	case broken > 0:
+		return fmt.Errorf("could not check %d of %d directories", broken, n)
+	case failed > 0:
+		return &exitError{mo.IssuesExitCode, fmt.Sprintf("found %d violations in %d file(s) in %d of %d directories",
+			total.Violations, total.Failed, failed, n)}
+	}
+	return nil
+}
+
+// args returns the flags to check the directory at root with, less
+// those that the entry does not set.
+func (entry manifestEntry) args(root string) []string {
+	args := []string{"--root", root}
+	for _, b := range entry.Boilerplate {
+		args = append(args, "--boilerplate", filepath.Join(root, b))
+	}
+	for _, ext := range entry.Extensions {
+		args = append(args, "--file-extension", ext)
+	}
+	if entry.Exclude != "" {
+		args = append(args, "--exclude", entry.Exclude)
+	}
+	return append(args, entry.Flags...)
+}
+
+// checkDir runs check with args, reporting the files it checks by their
+// paths under root, and its summary prefixed by root.
+func checkDir(cmd *cobra.Command, root string, args []string) (summary, error) {
+	co := &checkOptions{prefix: root}
+	check := &cobra.Command{
+		Use:     "check",
+		PreRunE: co.PreRunE,
+		RunE: func(check *cobra.Command, _ []string) error {
+			// We print the summary ourselves, to say which directory
+			// it is for.
+			noSummary := co.NoSummary
+			co.NoSummary = true
+			err := co.run(check, func() error {
+				return co.checkAll(check)
+			})
+			if ExitCode(err) == ExitError || noSummary {
+				return err
+			}
+			fmt.Fprintf(check.ErrOrStderr(), "%s: ", root)
+			if serr := (&textFormatter{errOut: check.ErrOrStderr(), dryRun: co.DryRun}).Summary(co.summary); serr != nil {
+				return serr
+			}
+			return err
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	co.AddFlags(check)
+	check.SetArgs(args)
+	check.SetIn(cmd.InOrStdin())
+	check.SetOut(cmd.OutOrStdout())
+	check.SetErr(cmd.ErrOrStderr())
+	err := check.Execute()
+	return co.summary, err
+}
+
+// prefixFormatter reports the files checked under a directory by their
+// paths from where we run, rather than from the directory.
+type prefixFormatter struct {
+	formatter
+	prefix string
+}
+
+func (pf *prefixFormatter) Violation(v boilerplate.Violation) error {
+	v.Path = filepath.Join(pf.prefix, v.Path)
+	return pf.formatter.Violation(v)
+}
+
+func (pf *prefixFormatter) File(path string, result outcome) error {
+	if ff, ok := pf.formatter.(fileFormatter); ok {
+		return ff.File(filepath.Join(pf.prefix, path), result)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes the files, by path under dir, creating directories
+// as needed.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll() = %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile() = %v", err)
+		}
+	}
+}
+
+func TestCheckAll(t *testing.T) {
+	apache, err := ioutil.ReadFile("testdata/boilerplate.mm.txt")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	good, err := ioutil.ReadFile("testdata/old.good.mm")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{
+		"a/hack/boilerplate.txt": string(apache),
+		"a/good.mm":              string(good),
+		"a/bad.mm":               "package a\n",
+		"b/hack/boilerplate.txt": "// Copyright YYYY Someone Else\n",
+		"b/good.mm":              "// Copyright 2020 Someone Else\n\npackage b\n",
+		"b/skipped/bad.mm":       "package b\n",
+	})
+
+	tests := []struct {
+		name       string
+		file       string
+		manifest   string
+		args       []string
+		wantStdout string
+		wantStderr string
+		wantCode   int
+	}{{
+		name: "violations",
+		manifest: `{"directories": [
+			{"dir": "a", "boilerplate": ["hack/boilerplate.txt"], "extensions": ["mm"]},
+			{"dir": "b", "boilerplate": ["hack/boilerplate.txt"], "extensions": ["mm"], "exclude": "^skipped/"}
+		]}`,
+		wantStdout: "a/bad.mm:1: missing boilerplate:\n",
+		wantStderr: "a: checked 2 files, 1 violations in 1 files\n" +
+			"b: checked 1 files, 0 violations in 0 files\n" +
+			"2 directories: checked 3 files, 1 violations in 1 files\n",
+		wantCode: ExitViolations,
+	}, {
+		name: "flags for every directory",
+		manifest: `{"directories": [
+			{"dir": "a", "boilerplate": ["hack/boilerplate.txt"], "extensions": ["mm"], "flags": ["--quiet"]},
+			{"dir": "b", "boilerplate": ["hack/boilerplate.txt"], "extensions": ["mm"], "exclude": "^skipped/"}
+		]}`,
+		args:       []string{"--", "--no-summary"},
+		wantStderr: "2 directories: checked 3 files, 1 violations in 1 files\n",
+		wantCode:   ExitViolations,
+	}, {
+		name: "a directory that cannot be checked",
+		manifest: `{"directories": [
+			{"dir": "a", "boilerplate": ["hack/missing.txt"], "extensions": ["mm"]},
+			{"dir": "b", "boilerplate": ["hack/boilerplate.txt"], "extensions": ["mm"], "exclude": "^skipped/"}
+		]}`,
+		wantStderr: "ERROR: a: error reading --boilerplate file \"a/hack/missing.txt\"",
+		wantCode:   ExitError,
+	}, {
+		name: "yaml",
+		file: "repos.yaml",
+		manifest: `directories:
+- dir: a
+  boilerplate: [hack/boilerplate.txt]
+  extensions: [mm]
+- dir: b
+  boilerplate:
+  - hack/boilerplate.txt
+  extensions: [mm]
+  exclude: ^skipped/
+`,
+		wantStdout: "a/bad.mm:1: missing boilerplate:\n",
+		wantStderr: "a: checked 2 files, 1 violations in 1 files\n" +
+			"b: checked 1 files, 0 violations in 0 files\n" +
+			"2 directories: checked 3 files, 1 violations in 1 files\n",
+		wantCode: ExitViolations,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := test.file
+			if file == "" {
+				file = "repos.json"
+			}
+			manifest := filepath.Join(dir, file)
+			if err := ioutil.WriteFile(manifest, []byte(test.manifest), 0644); err != nil {
+				t.Fatalf("WriteFile() = %v", err)
+			}
+
+			cmd := NewCheckAllCommand()
+			stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(stderr)
+			cmd.SetArgs(append([]string{"--manifest", manifest}, test.args...))
+
+			if err := cmd.Execute(); ExitCode(err) != test.wantCode {
+				t.Errorf("Execute() = %v, wanted exit code %d", err, test.wantCode)
+			}
+			// Files are reported by their paths from where we run.
+			got := strings.ReplaceAll(stdout.String(), dir+string(filepath.Separator), "")
+			if !strings.HasPrefix(got, test.wantStdout) {
+				t.Errorf("stdout = %q, wanted prefix %q", got, test.wantStdout)
+			}
+			if got := strings.ReplaceAll(stderr.String(), dir+string(filepath.Separator), ""); !strings.HasPrefix(got, test.wantStderr) {
+				t.Errorf("stderr = %q, wanted prefix %q", got, test.wantStderr)
+			}
+		})
+	}
+}
+
+func TestCheckAllPreRunE(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	manifest := filepath.Join(dir, "repos.json")
+
+	tests := []struct {
+		name     string
+		manifest string
+		args     []string
+		wantErr  string
+	}{{
+		name:    "no manifest",
+		wantErr: ErrManifestRequired.Error(),
+	}, {
+		name:     "misspelled field",
+		manifest: `{"directories": [{"dir": "a", "extension": ["go"]}]}`,
+		args:     []string{"--manifest", manifest},
+		wantErr:  `error parsing --manifest "` + manifest + `": json: unknown field "extension"`,
+	}, {
+		name:     "no directories",
+		manifest: `{"directories": []}`,
+		args:     []string{"--manifest", manifest},
+		wantErr:  `--manifest "` + manifest + `" lists no directories`,
+	}, {
+		name:     "no dir",
+		manifest: `{"directories": [{"extensions": ["go"]}]}`,
+		args:     []string{"--manifest", manifest},
+		wantErr:  `directory 1 of --manifest "` + manifest + `" has no dir`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := ioutil.WriteFile(manifest, []byte(test.manifest), 0644); err != nil {
+				t.Fatalf("WriteFile() = %v", err)
+			}
+			cmd := NewCheckAllCommand()
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs(test.args)
+
+			err := cmd.Execute()
+			if err == nil || err.Error() != test.wantErr {
+				t.Errorf("Execute() = %v, wanted %q", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+	"github.com/spf13/cobra"
+)
+
+// overrideFile is the name of a file holding the boilerplate of the
+// files in its directory and those below it, instead of the flags'.
+const overrideFile = ".boilerplate"
+
+// checkersFor returns the checkers of the files in dir: those of the
+// nearest override in dir or the directories above it, up to the root
+// being walked, or else those of the flags.
+func (co *checkOptions) checkersFor(cmd *cobra.Command, dir string) ([]*boilerplate.Checker, error) {
+	if checkers, ok := co.overrides[dir]; ok {
+		return checkers, nil
+	}
+
+	var checkers []*boilerplate.Checker
+	file := filepath.Join(dir, overrideFile)
+	bts, err := ioutil.ReadFile(file)
+	switch {
+	case err == nil:
+		source := fmt.Sprintf("boilerplate override %q", file)
+		lines, err := co.parseBoilerplate(string(bts), source)
+		if err != nil {
+			return nil, err
+		}
+		co.log.logf(debugLevel, file, "overrides the boilerplate")
+		checkers = []*boilerplate.Checker{co.newChecker(lines)}
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("error reading boilerplate override %q: %v", file, err)
+	case co.tops[dir] || filepath.Dir(dir) == dir:
+		checkers = co.checkers
+	default:
+		checkers, err = co.checkersFor(cmd, filepath.Dir(dir))
+		if err != nil {
+			return nil, err
+		}
+	}
+	co.overrides[dir] = checkers
+	return checkers, nil
+}
+
+// forFile returns the checkers of the file reported by path, given those
+// of its directory: those of --boilerplate-dir (or --license) for its
+// extension (or, if it has none, its base name), unless an override
+// applies.  Rather than pass a file that no boilerplate applies to, e.g.
+// one that --file-pattern matches, it returns an error.
+func (co *checkOptions) forFile(path string, checkers []*boilerplate.Checker) ([]*boilerplate.Checker, error) {
+	if len(co.byExtension) == 0 || !sameCheckers(checkers, co.checkers) {
+		return checkers, nil
+	}
+	if found, ok := co.byExtension[extension(path)]; ok {
+		return found, nil
+	}
+	switch {
+	case len(checkers) > 0:
+		return checkers, nil
+	case co.License != "":
+		return nil, fmt.Errorf("%q has no comment style for --license, which --comment-style may give", path)
+	default:
+		return nil, fmt.Errorf("%q has no boilerplate in --boilerplate-dir %q, and there is no --boilerplate", path, co.BoilerplateDir)
+	}
+}
+
+// extension returns the extension of the file reported by path, without
+// the leading ".", or if it has none, its base name.
+func extension(path string) string {
+	if ext := strings.TrimPrefix(filepath.Ext(path), "."); ext != "" {
+		return ext
+	}
+	return filepath.Base(path)
+}
+
+// sameCheckers returns whether a and b are the same checkers, e.g.
+// whether those of a directory are the flags' rather than an override's.
+func sameCheckers(a, b []*boilerplate.Checker) bool {
+	return len(a) == len(b) && (len(a) == 0 || a[0] == b[0])
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// progressInterval is how often --progress redraws its line at most, often
+// enough to show that a run is moving without flooding the terminal.
+const progressInterval = 100 * time.Millisecond
+
+// progress shows how many files have been checked and violations found
+// so far on a line of the terminal, which it redraws as the run goes on,
+// for --progress.  The line is cleared before anything else is written to
+// the terminal, and once the run is complete.  A nil progress shows
+// nothing.
+type progress struct {
+	out   io.Writer
+	now   func() time.Time
+	last  time.Time
+	shown bool
+}
+
+// newProgress returns the progress for --progress of a run writing its
+// results to out and errOut, which is nil unless both are terminals.
+func newProgress(enabled bool, out, errOut io.Writer) *progress {
+	if !enabled || !isTerminal(out) || !isTerminal(errOut) {
+		return nil
+	}
+	return &progress{out: errOut, now: time.Now}
+}
+
+// update redraws the line with the counts of s, unless it was drawn less
+// than progressInterval ago.
+func (p *progress) update(s summary) {
+	if p == nil {
+		return
+	}
+	now := p.now()
+	if p.shown && now.Sub(p.last) < progressInterval {
+		return
+	}
+	p.last = now
+	fmt.Fprintf(p.out, "\rchecked %d files, %d violations%s", s.Checked, s.Violations, ansiClearLine)
+	p.shown = true
+}
+
+// clear erases the line, if it is shown.
+func (p *progress) clear() {
+	if p == nil || !p.shown {
+		return
+	}
+	fmt.Fprint(p.out, "\r"+ansiClearLine)
+	p.shown = false
+}
+
+// clearing returns w, but clearing the line before each write to it.
+func (p *progress) clearing(w io.Writer) io.Writer {
+	if p == nil {
+		return w
+	}
+	return &clearingWriter{p: p, w: w}
+}
+
+type clearingWriter struct {
+	p *progress
+	w io.Writer
+}
+
+func (cw *clearingWriter) Write(b []byte) (int, error) {
+	cw.p.clear()
+	return cw.w.Write(b)
+}
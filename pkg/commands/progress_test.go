@@ -0,0 +1,69 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	buf := new(bytes.Buffer)
+	now := time.Now()
+	p := &progress{out: buf, now: func() time.Time { return now }}
+	out := p.clearing(new(bytes.Buffer))
+
+	p.update(summary{Checked: 1})
+	// Too soon to redraw.
+	p.update(summary{Checked: 2})
+	now = now.Add(progressInterval)
+	p.update(summary{Checked: 3, Violations: 1})
+	if _, err := out.Write([]byte("foo.go:1: missing boilerplate:\n")); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	p.update(summary{Checked: 4, Violations: 1})
+	p.clear()
+	// Clearing twice is harmless.
+	p.clear()
+
+	want := "\rchecked 1 files, 0 violations\x1b[K" +
+		"\rchecked 3 files, 1 violations\x1b[K" +
+		"\r\x1b[K" +
+		"\rchecked 4 files, 1 violations\x1b[K" +
+		"\r\x1b[K"
+	if got := buf.String(); got != want {
+		t.Errorf("progress = %q, wanted %q", got, want)
+	}
+}
+
+func TestProgressDisabled(t *testing.T) {
+	buf := new(bytes.Buffer)
+	// Buffers are not terminals.
+	p := newProgress(true, buf, buf)
+	if p != nil {
+		t.Fatalf("newProgress() = %v, wanted nil", p)
+	}
+	p.update(summary{Checked: 1})
+	p.clear()
+	if w := p.clearing(buf); w != buf {
+		t.Errorf("clearing() = %v, wanted the writer itself", w)
+	}
+	if got := buf.String(); got != "" {
+		t.Errorf("progress = %q, wanted nothing", got)
+	}
+}
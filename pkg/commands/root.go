@@ -0,0 +1,95 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultRootMarker marks the root of a git repository.
+const defaultRootMarker = ".git"
+
+// findRoot finds the closest of wd and its parents that contains
+// --root-marker, and makes it the base that the paths given by our flags
+// are relative to, and that files are reported relative to, as if we had
+// been run from there.
+func (co *checkOptions) findRoot(wd string) error {
+	dir := wd
+	for {
+		if _, err := os.Stat(filepath.Join(dir, co.RootMarker)); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return fmt.Errorf("found no --root-marker %q in %q or its parents", co.RootMarker, wd)
+		}
+		dir = parent
+	}
+	// Keep the paths in messages relative, as they were given.
+	base, err := filepath.Rel(wd, dir)
+	if err != nil {
+		return err
+	}
+	co.log.logf(infoLevel, "", "found the repository root %q", base)
+	co.base = base
+
+	co.BoilerplateFiles = co.fromBaseAll(co.BoilerplateFiles)
+	co.Forbid = co.fromBaseAll(co.Forbid)
+	co.Roots = co.fromBaseAll(co.Roots)
+	co.AllowMissingFrom = co.fromBase(co.AllowMissingFrom)
+	co.Cache = co.fromBase(co.Cache)
+	if co.FilesFrom != "-" {
+		co.FilesFrom = co.fromBase(co.FilesFrom)
+	}
+	if co.FilesFrom0 != "-" {
+		co.FilesFrom0 = co.fromBase(co.FilesFrom0)
+	}
+	return nil
+}
+
+// fromBase returns where name, a path given relative to the repository
+// root found by --find-root, is relative to the working directory.
+// Absolute paths, URLs, and empty names are returned as is.
+func (co *checkOptions) fromBase(name string) string {
+	if co.base == "" || name == "" || filepath.IsAbs(name) ||
+		strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://") {
+		return name
+	}
+	return filepath.Join(co.base, name)
+}
+
+// fromBaseAll returns fromBase of each of names.
+func (co *checkOptions) fromBaseAll(names []string) []string {
+	ret := make([]string, 0, len(names))
+	for _, name := range names {
+		ret = append(ret, co.fromBase(name))
+	}
+	return ret
+}
+
+// rel returns the path that file, under root, is reported (and matched
+// against --exclude) by: relative to root, or with --find-root, to the
+// repository root.
+func (co *checkOptions) rel(root, file string) (string, error) {
+	if co.base != "" {
+		root = co.base
+	}
+	return filepath.Rel(root, file)
+}
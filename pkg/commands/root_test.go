@@ -0,0 +1,124 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckFindRoot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	apache, err := ioutil.ReadFile("testdata/boilerplate.mm.txt")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	good, err := ioutil.ReadFile("testdata/old.good.mm")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	bad, err := ioutil.ReadFile("testdata/typo.bad.mm")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	writeFiles(t, dir, map[string]string{
+		".git/HEAD":                "ref: refs/heads/main\n",
+		"MARKER":                   "",
+		"hack/boilerplate.mm.txt":  string(apache),
+		"pkg/a/good.mm":            string(good),
+		"pkg/b/bad.mm":             string(bad),
+		"pkg/b/vendor/bad.mm":      string(bad),
+		"third_party/other/bad.mm": string(bad),
+		"files.txt":                "pkg/a/good.mm\npkg/b/bad.mm\n",
+	})
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd() = %v", err)
+	}
+	defer os.Chdir(wd)
+	if err := os.Chdir(filepath.Join(dir, "pkg", "a")); err != nil {
+		t.Fatalf("Chdir() = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr error
+	}{{
+		name: "whole repository",
+		args: []string{"--find-root", "--exclude", "^third_party/|/vendor/"},
+		want: "pkg/b/bad.mm\n",
+	}, {
+		name: "root",
+		args: []string{"--find-root", "--root", "pkg", "--exclude-dir", "pkg/b/vendor"},
+		want: "pkg/b/bad.mm\n",
+	}, {
+		name: "files from",
+		args: []string{"--find-root", "--files-from", "files.txt"},
+		want: "pkg/b/bad.mm\n",
+	}, {
+		name: "root marker",
+		args: []string{"--find-root", "--root-marker", "MARKER", "--root", "third_party"},
+		want: "third_party/other/bad.mm\n",
+	}, {
+		name:    "missing root marker",
+		args:    []string{"--find-root", "--root-marker", "NO-SUCH-MARKER"},
+		wantErr: fmt.Errorf("found no --root-marker %q in %q or its parents", "NO-SUCH-MARKER", filepath.Join(dir, "pkg", "a")),
+	}, {
+		name:    "root marker without find root",
+		args:    []string{"--root-marker", "MARKER"},
+		wantErr: ErrMarkerRequiresFindRoot,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			stdout := new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs(append([]string{
+				"--boilerplate", "hack/boilerplate.mm.txt",
+				"--file-extension", "mm",
+				"--print-files", "failing",
+			}, test.args...))
+
+			err := cmd.Execute()
+			if test.wantErr != nil {
+				if err == nil || err.Error() != test.wantErr.Error() {
+					t.Errorf("Execute() = %v, wanted %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Execute() = %v", err)
+			}
+			if got := stdout.String(); got != test.want {
+				t.Errorf("stdout = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+)
+
+// parseSeverity parses a --severity value of the form KIND=LEVEL.
+func parseSeverity(value string) (boilerplate.Kind, boilerplate.Severity, error) {
+	i := strings.Index(value, "=")
+	if i < 0 {
+		return 0, 0, fmt.Errorf("--severity %q must be of the form KIND=LEVEL", value)
+	}
+	kind, ok := boilerplate.ParseKind(value[:i])
+	if !ok {
+		return 0, 0, fmt.Errorf("--severity %q must name one of the kinds: %s",
+			value, strings.Join(boilerplate.KindNames(), ", "))
+	}
+	severity, ok := boilerplate.ParseSeverity(value[i+1:])
+	if !ok {
+		return 0, 0, fmt.Errorf("--severity %q must give a LEVEL of error or warning", value)
+	}
+	return kind, severity, nil
+}
+
+// severity returns the severity of violations of kind k, which are
+// errors unless --severity or --warn-only say otherwise.
+func (co *checkOptions) severity(k boilerplate.Kind) boilerplate.Severity {
+	if co.WarnOnly {
+		return boilerplate.SeverityWarning
+	}
+	return co.severities[k]
+}
+
+// failure returns the outcome of a file with a violation of kind k: it
+// fails the check, unless the violation is only a warning.
+func (co *checkOptions) failure(k boilerplate.Kind) outcome {
+	if co.severity(k) == boilerplate.SeverityWarning {
+		return warned
+	}
+	return violation
+}
+
+// emit reports the violation, with its severity, and tallies it.
+func (co *checkOptions) emit(v boilerplate.Violation) error {
+	v.Severity = co.severity(v.Kind)
+	if v.Severity == boilerplate.SeverityWarning {
+		co.summary.Warnings++
+	} else {
+		co.summary.Violations++
+		if co.summary.ByExtension != nil {
+			co.summary.ByExtension[extension(v.Path)]++
+		}
+	}
+	return co.formatter.Violation(v)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"testing"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+)
+
+func TestParseSeverity(t *testing.T) {
+	tests := []struct {
+		value        string
+		wantKind     boilerplate.Kind
+		wantSeverity boilerplate.Severity
+		wantErr      string
+	}{{
+		value:        "outdated=warning",
+		wantKind:     boilerplate.Outdated,
+		wantSeverity: boilerplate.SeverityWarning,
+	}, {
+		value:        "missing=error",
+		wantKind:     boilerplate.Missing,
+		wantSeverity: boilerplate.SeverityError,
+	}, {
+		value:   "outdated",
+		wantErr: `--severity "outdated" must be of the form KIND=LEVEL`,
+	}, {
+		value:   "year=warning",
+		wantErr: `--severity "year=warning" must name one of the kinds: missing, incomplete, mismatch, unreadable, misplaced, outdated, duplicate, forbidden, uncommented, unseparated, misattributed, interposed, unmarked, remnant, overlong, misdated`,
+	}, {
+		value:   "outdated=",
+		wantErr: `--severity "outdated=" must give a LEVEL of error or warning`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			kind, severity, err := parseSeverity(test.value)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Errorf("parseSeverity() = %v, wanted %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSeverity() = %v", err)
+			}
+			if kind != test.wantKind || severity != test.wantSeverity {
+				t.Errorf("parseSeverity() = %v, %v, wanted %v, %v", kind, severity, test.wantKind, test.wantSeverity)
+			}
+		})
+	}
+}
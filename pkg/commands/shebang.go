@@ -0,0 +1,72 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+)
+
+// maxShebang is the most of a file that --sniff-shebang reads, as the
+// longest shebang line that Linux allows.
+const maxShebang = 256
+
+// parseInterpreter parses an --interpreter of the form NAME=EXT,
+// returning the interpreter's name and its language's extension.
+func parseInterpreter(value string) (string, string, error) {
+	i := strings.Index(value, "=")
+	if i <= 0 || i == len(value)-1 {
+		return "", "", fmt.Errorf("--interpreter %q must be of the form NAME=EXT", value)
+	}
+	name, ext := value[:i], value[i+1:]
+	if strings.HasPrefix(ext, ".") {
+		return "", "", fmt.Errorf("--interpreter %q may not have an EXT starting with '.'", value)
+	}
+	return name, ext, nil
+}
+
+// sniffs returns whether, with --sniff-shebang, the file reported by
+// path might be checked for its shebang, though our filters skip it:
+// whether it lacks an extension and isn't excluded.
+func (co *checkOptions) sniffs(path string) bool {
+	return co.interpreters != nil && filepath.Ext(path) == "" && co.filter.ExcludeReason(path) == ""
+}
+
+// sniff returns the extension of the language of file, reported by path,
+// if its shebang names the interpreter of one whose files we check, or
+// else "".  Only the first line of the file is read.
+func (co *checkOptions) sniff(file, path string) (string, error) {
+	f, err := co.open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(io.LimitReader(f, maxShebang)).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	ext := boilerplate.ShebangExtension(strings.TrimRight(line, "\r\n"), co.interpreters)
+	if ext == "" || co.filter.SkipReason(path+"."+ext) != "" {
+		return "", nil
+	}
+	return ext, nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseInterpreter(t *testing.T) {
+	tests := []struct {
+		value    string
+		wantName string
+		wantExt  string
+		wantErr  string
+	}{{
+		value:    "python3=py",
+		wantName: "python3",
+		wantExt:  "py",
+	}, {
+		value:   "python3",
+		wantErr: `--interpreter "python3" must be of the form NAME=EXT`,
+	}, {
+		value:   "=py",
+		wantErr: `--interpreter "=py" must be of the form NAME=EXT`,
+	}, {
+		value:   "python3=",
+		wantErr: `--interpreter "python3=" must be of the form NAME=EXT`,
+	}, {
+		value:   "python3=.py",
+		wantErr: `--interpreter "python3=.py" may not have an EXT starting with '.'`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			name, ext, err := parseInterpreter(test.value)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Errorf("parseInterpreter() = %v, wanted %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseInterpreter() = %v", err)
+			}
+			if name != test.wantName || ext != test.wantExt {
+				t.Errorf("parseInterpreter() = %q, %q, wanted %q, %q", name, ext, test.wantName, test.wantExt)
+			}
+		})
+	}
+}
+
+func TestCheckSniffShebang(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{
+		"boilerplate.py.txt": "# Copyright YYYY Matt Moore\n",
+		"bin/good":           "#!/usr/bin/env python3\n# Copyright 2019 Matt Moore\n\nprint('hi')\n",
+		"bin/bad":            "#!/usr/bin/env python3\nprint('hi')\n",
+		"bin/versioned":      "#!/usr/bin/python3.8 -u\nprint('hi')\n",
+		"bin/shell":          "#!/bin/bash\necho hi\n",
+		"bin/custom":         "#!/usr/bin/env mython\nprint('hi')\n",
+		"bin/excluded":       "#!/usr/bin/env python3\nprint('hi')\n",
+		"bin/data":           "print('hi')\n",
+		"bin/tool.txt":       "#!/usr/bin/env python3\nprint('hi')\n",
+		"lib/bad.py":         "print('hi')\n",
+	})
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr error
+	}{{
+		name: "without sniffing",
+		want: "lib/bad.py\n",
+	}, {
+		name: "with sniffing",
+		args: []string{"--sniff-shebang"},
+		want: "bin/bad\nbin/versioned\nlib/bad.py\n",
+	}, {
+		name: "with another interpreter",
+		args: []string{"--sniff-shebang", "--interpreter", "mython=py"},
+		want: "bin/bad\nbin/custom\nbin/versioned\nlib/bad.py\n",
+	}, {
+		name: "with an interpreter of another language",
+		args: []string{"--sniff-shebang", "--interpreter", "python3=rb"},
+		want: "bin/versioned\nlib/bad.py\n",
+	}, {
+		name:    "interpreter without sniffing",
+		args:    []string{"--interpreter", "mython=py"},
+		wantErr: ErrInterpreterWithoutSniff,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			stdout := new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs(append([]string{
+				"--boilerplate", filepath.Join(dir, "boilerplate.py.txt"),
+				"--file-extension", "py",
+				"--exclude", "excluded",
+				"--allow-leading-lines",
+				"--root", dir,
+				"--print-files", "failing",
+			}, test.args...))
+
+			err := cmd.Execute()
+			if test.wantErr != nil {
+				if err != test.wantErr {
+					t.Errorf("Execute() = %v, wanted %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Execute() = %v", err)
+			}
+			if got := stdout.String(); got != test.want {
+				t.Errorf("stdout = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
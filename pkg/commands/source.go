@@ -0,0 +1,166 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+)
+
+// fetchTimeout bounds how long we wait to download a --boilerplate URL.
+const fetchTimeout = 30 * time.Second
+
+// readBoilerplates returns the lines of each boilerplate that headers
+// may match, having checked that they are usable.
+func (co *checkOptions) readBoilerplates() ([][]string, error) {
+	if co.BoilerplateLiteral != "" {
+		lines, err := co.parseBoilerplate(co.BoilerplateLiteral, "--boilerplate-literal")
+		if err != nil {
+			return nil, err
+		}
+		return [][]string{lines}, nil
+	}
+	variants := make([][]string, 0, len(co.BoilerplateFiles))
+	for _, file := range co.BoilerplateFiles {
+		content, source, err := readBoilerplate("--boilerplate", file)
+		if err != nil {
+			return nil, err
+		}
+		lines, err := co.parseBoilerplate(content, source)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, lines)
+	}
+	return variants, nil
+}
+
+// licenseBoilerplates returns the lines of the headers of --license,
+// naming --project as the copyright holder, in the comment style of each
+// extension (or file name) that has one: in block comments, then in line
+// comments, where the style has both.
+func (co *checkOptions) licenseBoilerplates() (map[string][][]string, error) {
+	styles, err := co.commentStyles()
+	if err != nil {
+		return nil, err
+	}
+	byExtension := make(map[string][][]string, len(styles))
+	for name, style := range styles {
+		headers, ok := boilerplate.LicenseHeaders(co.License, co.Project, style)
+		if !ok {
+			return nil, fmt.Errorf("--license %q must be one of: %s", co.License, strings.Join(boilerplate.Licenses(), ", "))
+		}
+		byExtension[name] = headers
+	}
+	return byExtension, nil
+}
+
+// readBoilerplateDir returns the lines of each boilerplate.EXT.txt file
+// in --boilerplate-dir, as the only boilerplate of its EXT, having checked
+// that they are usable.
+func (co *checkOptions) readBoilerplateDir() (map[string][][]string, error) {
+	files, err := filepath.Glob(filepath.Join(co.BoilerplateDir, "boilerplate.*.txt"))
+	if err != nil {
+		return nil, fmt.Errorf("error reading --boilerplate-dir %q: %v", co.BoilerplateDir, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("--boilerplate-dir %q has no boilerplate.EXT.txt files", co.BoilerplateDir)
+	}
+	byExtension := make(map[string][][]string, len(files))
+	for _, file := range files {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(file), "boilerplate."), ".txt")
+		if name == "" || strings.Contains(name, ".") {
+			return nil, fmt.Errorf("--boilerplate-dir file %q must be named boilerplate.EXT.txt, where EXT has no '.'", file)
+		}
+		content, source, err := readBoilerplate("--boilerplate-dir", file)
+		if err != nil {
+			return nil, err
+		}
+		lines, err := co.parseBoilerplate(content, source)
+		if err != nil {
+			return nil, err
+		}
+		byExtension[name] = [][]string{lines}
+	}
+	return byExtension, nil
+}
+
+// readForbidden returns the lines of each boilerplate that headers may
+// not match, having checked that they are usable.  Unlike the required
+// boilerplate, these are never expanded as templates.
+func (co *checkOptions) readForbidden() ([][]string, error) {
+	forbidden := make([][]string, 0, len(co.Forbid))
+	for _, file := range co.Forbid {
+		content, source, err := readBoilerplate("--forbid", file)
+		if err != nil {
+			return nil, err
+		}
+		if content == "" {
+			return nil, fmt.Errorf("%s is empty", source)
+		}
+		lines := strings.Split(content, "\n")
+		if err := validateBoilerplate(co.log, source, lines, co.IgnoreTrailingWhitespace); err != nil {
+			return nil, err
+		}
+		forbidden = append(forbidden, lines)
+	}
+	return forbidden, nil
+}
+
+// readBoilerplate returns the content of the boilerplate at file (or
+// URL), given by flag, and how to refer to where it came from in
+// messages.
+func readBoilerplate(flag, file string) (string, string, error) {
+	if strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://") {
+		source := fmt.Sprintf("%s URL %q", flag, file)
+		content, err := fetch(file)
+		if err != nil {
+			return "", "", fmt.Errorf("error fetching %s: %v", source, err)
+		}
+		return content, source, nil
+	}
+	source := fmt.Sprintf("%s file %q", flag, file)
+	bts, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", "", fmt.Errorf("error reading %s: %v", source, err)
+	}
+	return string(bts), source, nil
+}
+
+// fetch returns the body of the resource at url.
+func fetch(url string) (string, error) {
+	client := &http.Client{Timeout: fetchTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	bts, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(bts), nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckBoilerplateSources(t *testing.T) {
+	bts, err := ioutil.ReadFile("testdata/boilerplate.mm.txt")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/boilerplate.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(bts)
+	}))
+	defer server.Close()
+
+	run := func(args ...string) (string, error) {
+		cmd := NewCheckCommand()
+		stdout := new(bytes.Buffer)
+		cmd.SetOut(stdout)
+		cmd.SetErr(new(bytes.Buffer))
+		cmd.SetArgs(append([]string{
+			"--file-extension", "mm",
+			"--exclude", "[^o].bad.mm",
+		}, args...))
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	want, err := run("--boilerplate", "testdata/boilerplate.mm.txt")
+	if ExitCode(err) != ExitViolations {
+		t.Fatalf("Execute() = %v, wanted exit code %d", err, ExitViolations)
+	}
+	if want == "" {
+		t.Fatal("Execute() reported no violations")
+	}
+
+	for _, args := range [][]string{
+		{"--boilerplate", server.URL + "/boilerplate.txt"},
+		{"--boilerplate-literal", string(bts)},
+	} {
+		if got, err := run(args...); ExitCode(err) != ExitViolations {
+			t.Errorf("Execute(%s) = %v, wanted exit code %d", args[0], err, ExitViolations)
+		} else if got != want {
+			t.Errorf("Execute(%s) = %q, wanted %q", args[0], got, want)
+		}
+	}
+
+	url := server.URL + "/missing.txt"
+	_, err = run("--boilerplate", url)
+	if want := `error fetching --boilerplate URL "` + url + `": unexpected status 404 Not Found`; err == nil || err.Error() != want {
+		t.Errorf("Execute() = %v, wanted %s", err, want)
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+)
+
+// statsKey is what --stats tallies violations by: the line of the
+// boilerplate that differs and how, for mismatches, or else the kind.
+type statsKey struct {
+	kind   boilerplate.Kind
+	line   int
+	detail string
+}
+
+// statsFormatter prints only the number of files with each line of the
+// boilerplate that differs (and how), or each other kind of violation,
+// most common first, once the run is complete, for --stats.
+type statsFormatter struct {
+	out    io.Writer
+	counts map[statsKey]int
+	// last is the path of the last file each key was counted for, so
+	// that files are counted once.
+	last map[statsKey]string
+}
+
+func newStatsFormatter(out io.Writer) *statsFormatter {
+	return &statsFormatter{
+		out:    out,
+		counts: make(map[statsKey]int),
+		last:   make(map[statsKey]string),
+	}
+}
+
+func (sf *statsFormatter) Violation(v boilerplate.Violation) error {
+	key := statsKey{kind: v.Kind}
+	if v.Kind == boilerplate.Mismatch {
+		key.line, key.detail = v.BoilerplateLine, v.Detail
+	}
+	if last, ok := sf.last[key]; ok && last == v.Path {
+		return nil
+	}
+	sf.last[key] = v.Path
+	sf.counts[key]++
+	return nil
+}
+
+func (sf *statsFormatter) Summary(s summary) error {
+	keys := make([]statsKey, 0, len(sf.counts))
+	for key := range sf.counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		switch {
+		case sf.counts[a] != sf.counts[b]:
+			return sf.counts[a] > sf.counts[b]
+		case a.kind != b.kind:
+			return a.kind < b.kind
+		case a.line != b.line:
+			return a.line < b.line
+		default:
+			return a.detail < b.detail
+		}
+	})
+	if len(keys) == 0 {
+		_, err := fmt.Fprintln(sf.out, "no violations")
+		return err
+	}
+	for _, key := range keys {
+		var err error
+		switch {
+		case key.line > 0:
+			_, err = fmt.Fprintf(sf.out, "%d file(s): line %d of the boilerplate differs:\n%s", sf.counts[key], key.line, withNewline(key.detail))
+		case key.kind == boilerplate.Mismatch:
+			_, err = fmt.Fprintf(sf.out, "%d file(s): mismatch:\n%s", sf.counts[key], withNewline(key.detail))
+		default:
+			_, err = fmt.Fprintf(sf.out, "%d file(s): %s\n", sf.counts[key], key.kind)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withNewline returns s, ending with a newline.
+func withNewline(s string) string {
+	if strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
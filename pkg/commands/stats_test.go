@@ -0,0 +1,123 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+)
+
+func TestCheckStats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	files := make(map[string]string)
+	for name, copies := range map[string][]string{
+		"https.bad.mm":   {"a.mm", "b.mm", "c.mm"},
+		"typo.bad.mm":    {"d.mm"},
+		"missing.bad.mm": {"e.mm", "f.mm"},
+		"old.good.mm":    {"g.mm"},
+	} {
+		content, err := ioutil.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("ReadFile() = %v", err)
+		}
+		for _, copy := range copies {
+			files[copy] = string(content)
+		}
+	}
+	// A file with both mismatches is counted for each.
+	files["h.mm"] = strings.Replace(files["a.mm"], "Matt Moore", "Matt More", 1)
+	writeFiles(t, dir, files)
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr error
+	}{{
+		name: "ranked",
+		want: boilerplate.Denormalize(`4 file(s): line 8 of the boilerplate differs:
+{[]string}[0]:
+	-: "    http://www.apache.org/licenses/LICENSE-2.0"
+	+: "    https://www.apache.org/licenses/LICENSE-2.0"
+2 file(s): missing
+2 file(s): line 2 of the boilerplate differs:
+{[]string}[0]:
+	-: "Copyright YYYY Matt Moore"
+	+: "Copyright YYYY Matt More"
+`),
+	}, {
+		name: "no violations",
+		args: []string{"--exclude", "[^g].mm"},
+		want: "no violations\n",
+	}, {
+		name:    "with format",
+		args:    []string{"--format", "json"},
+		wantErr: ErrStatsWithFormat,
+	}, {
+		name:    "with fix",
+		args:    []string{"--fix"},
+		wantErr: ErrStatsWithFix,
+	}, {
+		name:    "with count",
+		args:    []string{"--count"},
+		wantErr: ErrStatsWithCount,
+	}, {
+		name:    "with diff context",
+		args:    []string{"--show-diff-context", "1"},
+		wantErr: ErrStatsWithDiffContext,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewCheckCommand()
+			stdout := new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs(append([]string{
+				"--boilerplate", "testdata/boilerplate.mm.txt",
+				"--file-extension", "mm",
+				"--root", dir,
+				"--stats",
+			}, test.args...))
+
+			err := cmd.Execute()
+			if test.wantErr != nil {
+				if err != test.wantErr {
+					t.Errorf("Execute() = %v, wanted %v", err, test.wantErr)
+				}
+				return
+			}
+			// Like --count, --stats exits zero regardless.
+			if err != nil {
+				t.Errorf("Execute() = %v", err)
+			}
+			if got := stdout.String(); got != test.want {
+				t.Errorf("stdout = %s, wanted %s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+	"github.com/spf13/cobra"
+)
+
+// surveyFormats are the survey --format values.
+var surveyFormats = []string{"text", "json"}
+
+// NewSurveyCommand implements the `survey` sub-command
+func NewSurveyCommand() *cobra.Command {
+	so := &surveyOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "survey",
+		Short: "Counts the files of each extension, to help decide which to check.",
+		Example: `  boilerplate-check survey --exclude-dir .git --exclude-dir vendor \
+    --boilerplate ./hack/boilerplate/boilerplate.go.txt`,
+		PreRunE: so.PreRunE,
+		RunE:    so.RunE,
+	}
+	so.AddFlags(cmd)
+	cmd.SetOut(os.Stdout)
+
+	return cmd
+}
+
+type surveyOptions struct {
+	Roots            []string
+	ExcludePattern   string
+	ExcludeDirs      []string
+	FollowSymlinks   bool
+	MaxDepth         int
+	BoilerplateFiles []string
+	Format           string
+
+	exclude     *regexp.Regexp
+	excludeDirs []*regexp.Regexp
+	checkers    []*boilerplate.Checker
+}
+
+// surveyed is the survey of the files of one extension.
+type surveyed struct {
+	// Extension is the extension of the files, without its dot, or ""
+	// for files without one.
+	Extension string `json:"extension"`
+	Files     int    `json:"files"`
+	// Matching is how many of the files have headers that match a
+	// --boilerplate, if any was passed.
+	Matching *int `json:"matching,omitempty"`
+}
+
+func (so *surveyOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVarP(&so.Roots, "root", "", []string{"."},
+		"A directory to survey the files under, may be repeated.")
+	cmd.Flags().StringVarP(&so.ExcludePattern, "exclude", "", "",
+		"A regular expression of the paths of files to leave out of the survey.")
+	cmd.Flags().StringArrayVarP(&so.ExcludeDirs, "exclude-dir", "", nil,
+		"A regular expression of the names (or paths) of directories to skip without reading them, may be repeated.")
+	cmd.Flags().BoolVarP(&so.FollowSymlinks, "follow-symlinks", "", false,
+		"Descend into symlinks to directories, walking each directory at most once.")
+	cmd.Flags().IntVarP(&so.MaxDepth, "max-depth", "", 0,
+		"Descend at most this many directory levels below each --root (0 for no limit).")
+	cmd.Flags().StringArrayVarP(&so.BoilerplateFiles, "boilerplate", "", nil,
+		"A boilerplate file (or URL) to also count the files whose headers match, may be repeated.")
+	cmd.Flags().StringVarP(&so.Format, "format", "", "text",
+		"The output format, one of: "+strings.Join(surveyFormats, ", ")+".")
+}
+
+func (so *surveyOptions) PreRunE(cmd *cobra.Command, args []string) error {
+	known := false
+	for _, format := range surveyFormats {
+		known = known || so.Format == format
+	}
+	if !known {
+		return fmt.Errorf("--format %q must be one of: %s", so.Format, strings.Join(surveyFormats, ", "))
+	}
+	if so.MaxDepth < 0 {
+		return fmt.Errorf("--max-depth %d may not be negative", so.MaxDepth)
+	}
+	so.exclude = nil
+	if so.ExcludePattern != "" {
+		exclude, err := regexp.Compile(so.ExcludePattern)
+		if err != nil {
+			return fmt.Errorf("error compiling --exclude pattern %q: %v", so.ExcludePattern, err)
+		}
+		so.exclude = exclude
+	}
+	excludeDirs, err := compileExcludeDirs(so.ExcludeDirs)
+	if err != nil {
+		return err
+	}
+	so.excludeDirs = excludeDirs
+	return nil
+}
+
+func (so *surveyOptions) RunE(cmd *cobra.Command, args []string) error {
+	// Errors past flag validation don't warrant usage, and are
+	// reported by our caller.
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	log := &logger{out: cmd.ErrOrStderr(), level: infoLevel}
+	so.checkers = nil
+	for _, file := range so.BoilerplateFiles {
+		content, source, err := readBoilerplate("--boilerplate", file)
+		if err != nil {
+			return err
+		}
+		if content == "" {
+			return fmt.Errorf("%s is empty", source)
+		}
+		lines := strings.Split(content, "\n")
+		if err := validateBoilerplate(log, source, lines, false); err != nil {
+			return err
+		}
+		so.checkers = append(so.checkers, boilerplate.NewChecker(lines, nil, nil))
+	}
+
+	byExt := make(map[string]*surveyed)
+	for _, root := range so.Roots {
+		if err := so.walk(root, byExt); err != nil {
+			return err
+		}
+	}
+	exts := make([]surveyed, 0, len(byExt))
+	for _, s := range byExt {
+		exts = append(exts, *s)
+	}
+	// The most common extensions are the likeliest to check.
+	sort.Slice(exts, func(i, j int) bool {
+		if exts[i].Files != exts[j].Files {
+			return exts[i].Files > exts[j].Files
+		}
+		return exts[i].Extension < exts[j].Extension
+	})
+
+	out := cmd.OutOrStdout()
+	if so.Format == "json" {
+		return json.NewEncoder(out).Encode(struct {
+			Extensions []surveyed `json:"extensions"`
+		}{exts})
+	}
+	for _, s := range exts {
+		name := "." + s.Extension
+		if s.Extension == "" {
+			name = "(no extension)"
+		}
+		if s.Matching != nil {
+			fmt.Fprintf(out, "%7d %s (%d matching)\n", s.Files, name, *s.Matching)
+		} else {
+			fmt.Fprintf(out, "%7d %s\n", s.Files, name)
+		}
+	}
+	return nil
+}
+
+// walk tallies the regular files under root into byExt, by extension.
+// Like --watch, it passes over what it can't read.
+func (so *surveyOptions) walk(root string, byExt map[string]*surveyed) error {
+	walk := filepath.Walk
+	if so.FollowSymlinks {
+		walk = walkFollowing
+	}
+	return walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		path, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != "." && excludedDir(so.excludeDirs, so.ExcludeDirs, path) != "" {
+				return filepath.SkipDir
+			}
+			if so.MaxDepth > 0 && depth(root, file) >= so.MaxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if so.exclude != nil && so.exclude.MatchString(path) {
+			return nil
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if info, err = os.Stat(file); err != nil {
+				return nil
+			}
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		ext := strings.TrimPrefix(filepath.Ext(path), ".")
+		s, ok := byExt[ext]
+		if !ok {
+			s = &surveyed{Extension: ext}
+			if len(so.checkers) > 0 {
+				s.Matching = new(int)
+			}
+			byExt[ext] = s
+		}
+		s.Files++
+		if len(so.checkers) > 0 && so.matches(file, path) {
+			*s.Matching++
+		}
+		return nil
+	})
+}
+
+// matches returns whether the header of file, reported by path, matches
+// any --boilerplate.  Files that can't be read don't.
+func (so *surveyOptions) matches(file, path string) bool {
+	for _, c := range so.checkers {
+		f, err := os.Open(file)
+		if err != nil {
+			return false
+		}
+		violations, err := c.Check(path, f)
+		f.Close()
+		if err == nil && len(violations) == 0 {
+			return true
+		}
+	}
+	return false
+}
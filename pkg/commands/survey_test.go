@@ -0,0 +1,117 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSurveyPreRunE(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr error
+	}{{
+		name:    "bad format",
+		args:    []string{"--format", "yaml"},
+		wantErr: errors.New(`--format "yaml" must be one of: text, json`),
+	}, {
+		name:    "bad exclude",
+		args:    []string{"--exclude", "("},
+		wantErr: errors.New("error compiling --exclude pattern \"(\": error parsing regexp: missing closing ): `(`"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewSurveyCommand()
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs(test.args)
+
+			if err := cmd.Execute(); err == nil || err.Error() != test.wantErr.Error() {
+				t.Errorf("Execute() = %v, wanted %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestSurvey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{
+		"boilerplate.txt":  "// Copyright 2020 Matt Moore\n",
+		"tree/a.go":        "// Copyright 2019 Matt Moore\n\npackage a\n",
+		"tree/b.go":        "package b\n",
+		"tree/c.go":        "// Copyright 2020 Matt Moore\n\npackage c\n",
+		"tree/hack/d.sh":   "#!/bin/bash\n",
+		"tree/Makefile":    "all:\n",
+		"tree/vendor/e.go": "package e\n",
+		"tree/gen/f.pb.go": "package f\n",
+	})
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{{
+		name: "text",
+		args: []string{"--exclude-dir", "vendor"},
+		want: "      4 .go\n" +
+			"      1 (no extension)\n" +
+			"      1 .sh\n",
+	}, {
+		name: "matching",
+		args: []string{"--exclude", `\.pb\.go$`, "--boilerplate", filepath.Join(dir, "boilerplate.txt")},
+		want: "      4 .go (2 matching)\n" +
+			"      1 (no extension) (0 matching)\n" +
+			"      1 .sh (0 matching)\n",
+	}, {
+		name: "max depth",
+		args: []string{"--max-depth", "1"},
+		want: "      3 .go\n" +
+			"      1 (no extension)\n",
+	}, {
+		name: "json",
+		args: []string{"--exclude-dir", "vendor|gen", "--format", "json"},
+		want: `{"extensions":[{"extension":"go","files":3},{"extension":"","files":1},{"extension":"sh","files":1}]}` + "\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd := NewSurveyCommand()
+			stdout := new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs(append([]string{"--root", filepath.Join(dir, "tree")}, test.args...))
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() = %v", err)
+			}
+			if got := stdout.String(); got != test.want {
+				t.Errorf("stdout = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
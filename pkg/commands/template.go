@@ -0,0 +1,61 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+)
+
+// expandTemplate runs the content of a --boilerplate-template through
+// text/template, which may refer to {{.Year}}, {{.Env.NAME}} for the
+// environment variable NAME, {{.Wildcard}} for a line that matches any
+// line, and {{.Project}} if it is given.  The boilerplate is referred to
+// by source in messages.
+func expandTemplate(content, source, project string) (string, error) {
+	tmpl, err := template.New("boilerplate").Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("error parsing %s as a template: %v", source, err)
+	}
+
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if i := strings.Index(kv, "="); i > 0 {
+			env[kv[:i]] = kv[i+1:]
+		}
+	}
+	// Leaving out what isn't given makes referring to it an error.
+	data := map[string]interface{}{
+		"Year":     time.Now().Year(),
+		"Env":      env,
+		"Wildcard": boilerplate.WildcardLine,
+	}
+	if project != "" {
+		data["Project"] = project
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("error expanding %s: %v", source, err)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	os.Setenv("BOILERPLATE_CHECK_LICENSE", "Apache License, Version 2.0")
+	defer os.Unsetenv("BOILERPLATE_CHECK_LICENSE")
+
+	tests := []struct {
+		name    string
+		content string
+		project string
+		want    string
+		// The details of template errors vary between Go releases.
+		wantErr string
+	}{{
+		name:    "year and project",
+		content: "Copyright {{.Year}} {{.Project}}\n",
+		project: "The Authors",
+		want:    fmt.Sprintf("Copyright %d The Authors\n", time.Now().Year()),
+	}, {
+		name:    "environment",
+		content: "Licensed under the {{.Env.BOILERPLATE_CHECK_LICENSE}}\n",
+		want:    "Licensed under the Apache License, Version 2.0\n",
+	}, {
+		name:    "wildcard",
+		content: "{{.Wildcard}}\n",
+		want:    "{{*}}\n",
+	}, {
+		name:    "no project",
+		content: "Copyright {{.Year}} {{.Project}}\n",
+		wantErr: `map has no entry for key "Project"`,
+	}, {
+		name:    "undefined environment variable",
+		content: "{{.Env.BOILERPLATE_CHECK_UNDEFINED}}\n",
+		wantErr: `map has no entry for key "BOILERPLATE_CHECK_UNDEFINED"`,
+	}, {
+		name:    "bad syntax",
+		content: "Copyright {{.Year}\n",
+		wantErr: "error parsing boilerplate.tmpl as a template",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := expandTemplate(test.content, "boilerplate.tmpl", test.project)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("expandTemplate() = %v, wanted %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expandTemplate() = %v", err)
+			}
+			if got != test.want {
+				t.Errorf("expandTemplate() = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
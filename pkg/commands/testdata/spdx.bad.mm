@@ -0,0 +1,4 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright 2020 Matt Moore
+
+package testdata
@@ -0,0 +1,17 @@
+/*
+COPYRIGHT 2019 MATT MOORE
+
+LICENSED UNDER THE APACHE LICENSE, VERSION 2.0 (THE "LICENSE");
+YOU MAY NOT USE THIS FILE EXCEPT IN COMPLIANCE WITH THE LICENSE.
+YOU MAY OBTAIN A COPY OF THE LICENSE AT
+
+    HTTP://WWW.APACHE.ORG/LICENSES/LICENSE-2.0
+
+UNLESS REQUIRED BY APPLICABLE LAW OR AGREED TO IN WRITING, SOFTWARE
+DISTRIBUTED UNDER THE LICENSE IS DISTRIBUTED ON AN "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, EITHER EXPRESS OR IMPLIED.
+SEE THE LICENSE FOR THE SPECIFIC LANGUAGE GOVERNING PERMISSIONS AND
+LIMITATIONS UNDER THE LICENSE.
+*/
+
+package testdata
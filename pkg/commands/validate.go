@@ -0,0 +1,290 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// manifestSchema is the JSON Schema of a check-all manifest, for editors
+// to complete and validate manifests with.
+const manifestSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "$id": "https://github.com/mattmoor/boilerplate-check/manifest.schema.json",
+  "title": "boilerplate-check check-all manifest",
+  "type": "object",
+  "required": ["directories"],
+  "additionalProperties": false,
+  "properties": {
+    "directories": {
+      "description": "The directories to check, and how.",
+      "type": "array",
+      "minItems": 1,
+      "items": {
+        "type": "object",
+        "required": ["dir"],
+        "additionalProperties": false,
+        "properties": {
+          "dir": {
+            "description": "The directory to check, relative to the manifest.",
+            "type": "string",
+            "minLength": 1
+          },
+          "boilerplate": {
+            "description": "The boilerplate files (or URLs) required of its files, relative to the directory, like --boilerplate.",
+            "type": "array",
+            "items": {"type": "string"}
+          },
+          "extensions": {
+            "description": "The extensions of the files to check, like --file-extension.",
+            "type": "array",
+            "items": {"type": "string"}
+          },
+          "exclude": {
+            "description": "A regular expression matching the paths of files to skip, like --exclude.",
+            "type": "string"
+          },
+          "flags": {
+            "description": "Any other flags to check the directory with.",
+            "type": "array",
+            "items": {"type": "string"}
+          }
+        }
+      }
+    }
+  }
+}
+`
+
+// NewManifestCommand implements the `manifest` sub-command, which holds
+// those for working with check-all manifests.
+func NewManifestCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "manifest",
+		Short: "Validates check-all manifests, or prints their JSON Schema.",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:     "validate MANIFEST",
+		Short:   "Reports the problems with a check-all manifest, without checking any files.",
+		Example: `  boilerplate-check manifest validate repos.yaml`,
+		Args:    cobra.ExactArgs(1),
+		RunE:    validateRunE,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:     "schema",
+		Short:   "Prints the JSON Schema of check-all manifests, for editors to use.",
+		Example: `  boilerplate-check manifest schema > manifest.schema.json`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, err := fmt.Fprint(cmd.OutOrStdout(), manifestSchema)
+			return err
+		},
+	})
+	cmd.SetOut(os.Stdout)
+
+	return cmd
+}
+
+// manifestProblem is a problem with a manifest, found at a line and
+// column of it, or in one of its fields.
+type manifestProblem struct {
+	line, column int
+	field        string
+	msg          string
+}
+
+// in returns the problem as found in the manifest called name, in the
+// "name:line:column: message" form of compilers (less the column, if it
+// is unknown), or else naming the field.
+func (p manifestProblem) in(name string) string {
+	switch {
+	case p.field != "":
+		return fmt.Sprintf("%s: %s: %s", name, p.field, p.msg)
+	case p.column == 0:
+		return fmt.Sprintf("%s:%d: %s", name, p.line, p.msg)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", name, p.line, p.column, p.msg)
+}
+
+func validateRunE(cmd *cobra.Command, args []string) error {
+	// Errors past flag validation don't warrant usage, and are
+	// reported by our caller.
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	name := args[0]
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("error reading manifest %q: %v", name, err)
+	}
+	problems := validateManifest(filepath.Dir(name), data)
+	out := cmd.OutOrStdout()
+	for _, p := range problems {
+		fmt.Fprintln(out, p.in(name))
+	}
+	if len(problems) > 0 {
+		return &exitError{ExitViolations, fmt.Sprintf("found %d problem(s) in manifest %q", len(problems), name)}
+	}
+	fmt.Fprintf(out, "%s: ok\n", name)
+	return nil
+}
+
+// validateManifest returns the problems with the manifest in data, whose
+// directories are relative to dir: that it does not parse, or that a
+// directory or its boilerplate does not exist, or that its exclude or
+// flags are invalid.
+func validateManifest(dir string, data []byte) []manifestProblem {
+	m, err := decodeManifest(data)
+	if err != nil {
+		return []manifestProblem{decodeProblem(data, err)}
+	}
+	if len(m.Directories) == 0 {
+		return []manifestProblem{{field: "directories", msg: "lists no directories"}}
+	}
+
+	var problems []manifestProblem
+	for i, entry := range m.Directories {
+		field := fmt.Sprintf("directories[%d]", i)
+		n := len(problems)
+		if entry.Dir == "" {
+			problems = append(problems, manifestProblem{field: field + ".dir", msg: "is required"})
+			continue
+		}
+		root := filepath.Join(dir, entry.Dir)
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			problems = append(problems, manifestProblem{field: field + ".dir", msg: fmt.Sprintf("%q is not a directory", entry.Dir)})
+			continue
+		}
+		for j, b := range entry.Boilerplate {
+			if _, err := os.Stat(filepath.Join(root, b)); err != nil {
+				problems = append(problems, manifestProblem{
+					field: fmt.Sprintf("%s.boilerplate[%d]", field, j),
+					msg:   fmt.Sprintf("%q does not exist in %q", b, entry.Dir),
+				})
+			}
+		}
+		if entry.Exclude != "" {
+			if _, err := regexp.Compile(entry.Exclude); err != nil {
+				problems = append(problems, manifestProblem{field: field + ".exclude", msg: err.Error()})
+			}
+		}
+		if len(problems) > n {
+			continue
+		}
+
+		// Validate the rest as check-all would check the directory,
+		// e.g. the regular expressions passed as flags.
+		co := &checkOptions{prefix: root}
+		check := &cobra.Command{Use: "check"}
+		co.AddFlags(check)
+		if err := check.ParseFlags(entry.args(root)); err != nil {
+			problems = append(problems, manifestProblem{field: field + ".flags", msg: err.Error()})
+			continue
+		}
+		if err := co.PreRunE(check, check.Flags().Args()); err != nil {
+			problems = append(problems, manifestProblem{field: field, msg: err.Error()})
+		}
+	}
+	return problems
+}
+
+// decodeProblem returns the problem of err, from decoding data, at the
+// line and column it was found, or else in the field it concerns.
+func decodeProblem(data []byte, err error) manifestProblem {
+	if !isJSON(data) {
+		return decodeYAMLProblem(data, err)
+	}
+	var offset int64 = -1
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// These carry no offset, so find the field ourselves.
+		if name, uerr := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field ")); uerr == nil {
+			re := regexp.MustCompile(regexp.QuoteMeta(strconv.Quote(name)) + `\s*:`)
+			if loc := re.FindIndex(data); loc != nil {
+				offset = int64(loc[0]) + 1
+			}
+		}
+	}
+	if offset < 0 {
+		return manifestProblem{field: "directories", msg: err.Error()}
+	}
+	line, column := position(data, offset)
+	return manifestProblem{line: line, column: column, msg: err.Error()}
+}
+
+var (
+	// yamlErrorLine matches the line that YAML syntax errors are found at.
+	yamlErrorLine = regexp.MustCompile(`yaml: line (\d+):`)
+	// jsonIndex matches the array indices in the field of a JSON error.
+	jsonIndex = regexp.MustCompile(`\.(\d+)`)
+)
+
+// decodeYAMLProblem is decodeProblem for YAML manifests, which are decoded
+// as the JSON they convert to, so that the offsets of JSON errors are no
+// use: syntax errors carry their line, misspelled fields are found by
+// their key, and the rest are reported in their field.
+func decodeYAMLProblem(data []byte, err error) manifestProblem {
+	if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return manifestProblem{line: line, msg: err.Error()}
+	}
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		// Name the field as we do those of the other problems.
+		return manifestProblem{field: jsonIndex.ReplaceAllString(typeErr.Field, "[$1]"), msg: err.Error()}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		if name, uerr := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field ")); uerr == nil {
+			re := regexp.MustCompile(`(?m)^[\t ]*(?:-[\t ]+)*["']?(` + regexp.QuoteMeta(name) + `)["']?[\t ]*:`)
+			if loc := re.FindSubmatchIndex(data); loc != nil {
+				line, column := position(data, int64(loc[2])+1)
+				return manifestProblem{line: line, column: column, msg: err.Error()}
+			}
+		}
+	}
+	return manifestProblem{field: "directories", msg: err.Error()}
+}
+
+// position returns the line and column, counting from 1, of the byte
+// before offset in data, which is where the decoder stopped.
+func position(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset > 0 {
+		offset--
+	}
+	before := data[:offset]
+	return bytes.Count(before, []byte("\n")) + 1, len(before) - bytes.LastIndexByte(before, '\n')
+}
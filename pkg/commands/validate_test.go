@@ -0,0 +1,185 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestManifestValidate(t *testing.T) {
+	apache, err := ioutil.ReadFile("testdata/boilerplate.mm.txt")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{
+		"a/hack/boilerplate.txt": string(apache),
+		"b/file.txt":             "not a directory\n",
+	})
+
+	tests := []struct {
+		name     string
+		file     string
+		manifest string
+		want     string
+		wantCode int
+	}{{
+		name:     "valid",
+		manifest: `{"directories": [{"dir": "a", "boilerplate": ["hack/boilerplate.txt"], "extensions": ["mm"]}]}`,
+		want:     "repos.json: ok\n",
+	}, {
+		name:     "syntax error",
+		manifest: "{\"directories\": [\n  {\"dir\": \"a\",, }\n]}\n",
+		want:     "repos.json:2:15: invalid character ',' looking for beginning of object key string\n",
+		wantCode: ExitViolations,
+	}, {
+		name:     "misspelled field",
+		manifest: "{\"directories\": [\n  {\"dir\": \"a\",\n   \"extension\": [\"mm\"]}\n]}\n",
+		want:     "repos.json:3:4: json: unknown field \"extension\"\n",
+		wantCode: ExitViolations,
+	}, {
+		name:     "no directories",
+		manifest: `{"directories": []}`,
+		want:     "repos.json: directories: lists no directories\n",
+		wantCode: ExitViolations,
+	}, {
+		name: "bad fields",
+		manifest: `{"directories": [
+			{"dir": "a", "boilerplate": ["hack/missing.txt"], "exclude": "("},
+			{"dir": "b/file.txt"},
+			{"extensions": ["mm"]}
+		]}`,
+		want: "repos.json: directories[0].boilerplate[0]: \"hack/missing.txt\" does not exist in \"a\"\n" +
+			"repos.json: directories[0].exclude: error parsing regexp: missing closing ): `(`\n" +
+			"repos.json: directories[1].dir: \"b/file.txt\" is not a directory\n" +
+			"repos.json: directories[2].dir: is required\n",
+		wantCode: ExitViolations,
+	}, {
+		name: "bad flags",
+		manifest: `{"directories": [
+			{"dir": "a", "boilerplate": ["hack/boilerplate.txt"], "flags": ["--bogus"]},
+			{"dir": "a", "boilerplate": ["hack/boilerplate.txt"], "extensions": ["mm"], "flags": ["--allow-missing", "("]}
+		]}`,
+		want: "repos.json: directories[0].flags: unknown flag: --bogus\n" +
+			"repos.json: directories[1]: error compiling --allow-missing pattern \"(\": error parsing regexp: missing closing ): `(`\n",
+		wantCode: ExitViolations,
+	}, {
+		name:     "valid yaml",
+		file:     "repos.yaml",
+		manifest: "directories:\n- dir: a\n  boilerplate: [hack/boilerplate.txt]\n  extensions: [mm]\n",
+		want:     "repos.yaml: ok\n",
+	}, {
+		name:     "yaml syntax error",
+		file:     "repos.yaml",
+		manifest: "directories:\n- dir: a\n   extensions: : [mm]\n",
+		want:     "repos.yaml:3: yaml: line 3: mapping values are not allowed in this context\n",
+		wantCode: ExitViolations,
+	}, {
+		name:     "misspelled yaml field",
+		file:     "repos.yaml",
+		manifest: "directories:\n- dir: a\n  extension: [mm]\n",
+		want:     "repos.yaml:3:3: json: unknown field \"extension\"\n",
+		wantCode: ExitViolations,
+	}, {
+		name:     "mistyped yaml field",
+		file:     "repos.yaml",
+		manifest: "directories:\n- dir: a\n  extensions: mm\n",
+		want:     "repos.yaml: directories[0].extensions: json: cannot unmarshal string into Go struct field manifest.directories.0.extensions of type []string\n",
+		wantCode: ExitViolations,
+	}, {
+		name:     "bad yaml fields",
+		file:     "repos.yaml",
+		manifest: "directories:\n- dir: a\n  boilerplate: [hack/missing.txt]\n",
+		want:     "repos.yaml: directories[0].boilerplate[0]: \"hack/missing.txt\" does not exist in \"a\"\n",
+		wantCode: ExitViolations,
+	}}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd() = %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir() = %v", err)
+	}
+	defer os.Chdir(wd)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := test.file
+			if file == "" {
+				file = "repos.json"
+			}
+			if err := ioutil.WriteFile(file, []byte(test.manifest), 0644); err != nil {
+				t.Fatalf("WriteFile() = %v", err)
+			}
+
+			cmd := NewManifestCommand()
+			stdout := new(bytes.Buffer)
+			cmd.SetOut(stdout)
+			cmd.SetErr(new(bytes.Buffer))
+			cmd.SetArgs([]string{"validate", file})
+
+			if err := cmd.Execute(); ExitCode(err) != test.wantCode {
+				t.Errorf("Execute() = %v, wanted exit code %d", err, test.wantCode)
+			}
+			if got := stdout.String(); got != test.want {
+				t.Errorf("stdout = %q, wanted %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestManifestSchema(t *testing.T) {
+	cmd := NewManifestCommand()
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetArgs([]string{"schema"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() = %v", err)
+	}
+
+	var schema struct {
+		Properties struct {
+			Directories struct {
+				Items struct {
+					Properties map[string]interface{} `json:"properties"`
+				} `json:"items"`
+			} `json:"directories"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &schema); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	// Every field of a directory must be in the schema, which forbids
+	// any others.
+	typ := reflect.TypeOf(manifestEntry{})
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i).Tag.Get("json")
+		if _, ok := schema.Properties.Directories.Items.Properties[field]; !ok {
+			t.Errorf("field %q is missing from the schema", field)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// walkFollowing is like filepath.Walk, but also descends into symlinks to
+// directories.  Each directory is walked at most once, however many links
+// lead to it: directories are tracked by the real path they resolve to,
+// and any directory (including root) that was already walked is skipped.
+// This keeps symlink cycles from causing infinite recursion.  As with
+// filepath.Walk, fn may return filepath.SkipDir to skip a directory.
+func walkFollowing(root string, fn filepath.WalkFunc) error {
+	info, err := os.Lstat(root)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+	w := &walker{fn: fn, visited: make(map[string]bool)}
+	return w.walk(root, info)
+}
+
+type walker struct {
+	fn      filepath.WalkFunc
+	visited map[string]bool
+}
+
+func (w *walker) walk(file string, info os.FileInfo) error {
+	if info.Mode()&os.ModeSymlink != 0 {
+		if target, err := os.Stat(file); err == nil && target.IsDir() {
+			info = target
+		}
+	}
+	if !info.IsDir() {
+		return w.fn(file, info, nil)
+	}
+
+	real, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		return w.fn(file, info, err)
+	}
+	if w.visited[real] {
+		return nil
+	}
+	w.visited[real] = true
+
+	switch err := w.fn(file, info, nil); err {
+	case nil:
+	case filepath.SkipDir:
+		return nil
+	default:
+		return err
+	}
+	names, err := readDirNames(file)
+	if err != nil {
+		return w.fn(file, info, err)
+	}
+	for _, name := range names {
+		child := filepath.Join(file, name)
+		info, err := os.Lstat(child)
+		if err != nil {
+			if err := w.fn(child, nil, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := w.walk(child, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDirNames returns the sorted names of the entries in dir, so that
+// we walk in the same order as filepath.Walk.
+func readDirNames(dir string) ([]string, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	names, err := f.Readdirnames(-1)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
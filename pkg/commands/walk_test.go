@@ -0,0 +1,126 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWalkFollowing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// tree/
+	//   a.mm
+	//   link -> ../other
+	//   loop -> .
+	//   again -> ../other
+	// other/
+	//   b.mm
+	//   up -> ../tree
+	for _, d := range []string{"tree", "other"} {
+		if err := os.Mkdir(filepath.Join(dir, d), 0755); err != nil {
+			t.Fatalf("Mkdir() = %v", err)
+		}
+	}
+	for _, f := range []string{"tree/a.mm", "other/b.mm"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+			t.Fatalf("WriteFile() = %v", err)
+		}
+	}
+	for link, target := range map[string]string{
+		"tree/link":  "../other",
+		"tree/loop":  ".",
+		"tree/again": "../other",
+		"other/up":   "../tree",
+	} {
+		if err := os.Symlink(target, filepath.Join(dir, link)); err != nil {
+			t.Fatalf("Symlink() = %v", err)
+		}
+	}
+
+	root := filepath.Join(dir, "tree")
+	var got []string
+	if err := walkFollowing(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		path, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		got = append(got, path)
+		return nil
+	}); err != nil {
+		t.Fatalf("walkFollowing() = %v", err)
+	}
+
+	// "link" leads to the directory already walked through "again",
+	// and "loop" and "up" lead back to the root.
+	want := []string{".", "a.mm", "again", "again/b.mm"}
+	if !cmp.Equal(got, want) {
+		t.Errorf("walkFollowing() (-want, +got): %s", cmp.Diff(want, got))
+	}
+}
+
+func TestWalkFollowingSkipDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	for _, f := range []string{"a.mm", "skipped/b.mm", "walked/c.mm"} {
+		path := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll() = %v", err)
+		}
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("WriteFile() = %v", err)
+		}
+	}
+
+	var got []string
+	if err := walkFollowing(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		path, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		got = append(got, path)
+		if path == "skipped" {
+			return filepath.SkipDir
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("walkFollowing() = %v", err)
+	}
+
+	want := []string{".", "a.mm", "skipped", "walked", "walked/c.mm"}
+	if !cmp.Equal(got, want) {
+		t.Errorf("walkFollowing() (-want, +got): %s", cmp.Diff(want, got))
+	}
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/mattmoor/boilerplate-check/pkg/boilerplate"
+	"github.com/spf13/cobra"
+)
+
+// watchEvent is a file under a --root that was created, changed, or
+// removed, as --watch is told of it.
+type watchEvent struct {
+	root    string
+	file    string
+	removed bool
+}
+
+// watch checks the files under each --root, and then re-checks those that
+// events report were created or changed, until interrupted.  So that a
+// burst of saves is checked once, and not while it is still being
+// written, a file is only re-checked once it is unchanged for a tick.
+func (co *checkOptions) watch(cmd *cobra.Command, events <-chan watchEvent, ticks <-chan time.Time, interrupt <-chan os.Signal) error {
+	if err := co.watchRun(cmd, func() error { return co.checkAll(cmd) }); err != nil {
+		return err
+	}
+
+	// The files changed since the last tick, and those changed in the
+	// tick before it.
+	changed, pending := make(map[string]watchEvent), make(map[string]watchEvent)
+	for {
+		select {
+		case <-interrupt:
+			return nil
+		case e, ok := <-events:
+			if !ok {
+				// Nothing more will change.
+				events = nil
+				continue
+			}
+			path, err := co.rel(e.root, e.file)
+			if err != nil || co.filter.SkipReason(path) != "" && !co.sniffs(path) {
+				continue
+			}
+			if e.removed {
+				delete(changed, e.file)
+				delete(pending, e.file)
+				co.log.logf(infoLevel, path, "deleted")
+				continue
+			}
+			changed[e.file] = e
+			continue
+		case <-ticks:
+		}
+
+		var ready []string
+		for file := range pending {
+			if _, ok := changed[file]; !ok {
+				ready = append(ready, file)
+			}
+		}
+		settled := pending
+		changed, pending = make(map[string]watchEvent), changed
+		if len(ready) == 0 {
+			continue
+		}
+
+		sort.Strings(ready)
+		if err := co.watchRun(cmd, func() error {
+			for _, file := range ready {
+				e := settled[file]
+				path, err := co.rel(e.root, file)
+				if err != nil {
+					return err
+				}
+				// Overrides above the root don't apply to it.
+				co.tops[filepath.Clean(e.root)] = true
+				info, err := os.Lstat(file)
+				if err != nil {
+					if err := co.later(func() error {
+						return co.record(path, co.failure(boilerplate.Unreadable), co.unreadable(path, err))
+					}); err != nil {
+						return err
+					}
+					continue
+				}
+				if !isRegular(file, info) {
+					continue
+				}
+				if err := co.visit(cmd, file, path, info); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// isRegular returns whether file, whose Lstat is info, is a regular file
+// or a symlink to one.
+func isRegular(file string, info os.FileInfo) bool {
+	if info.Mode()&os.ModeSymlink != 0 {
+		var err error
+		if info, err = os.Stat(file); err != nil {
+			return false
+		}
+	}
+	return info.Mode().IsRegular()
+}
+
+// watchRun checks the files that visit visits, like run, but violations
+// don't stop us from watching: they have already been reported.
+func (co *checkOptions) watchRun(cmd *cobra.Command, visit func() error) error {
+	if err := co.run(cmd, visit); ExitCode(err) != ExitViolations {
+		return err
+	}
+	return nil
+}
+
+// dirWatcher watches the directories under each --root with fsnotify, so
+// that --watch learns of changes without re-reading the tree.  Directories
+// that are created are watched in turn, and the files already in them
+// reported, since they may have been written before the watch was added.
+type dirWatcher struct {
+	co      *checkOptions
+	watcher *fsnotify.Watcher
+	// roots holds the --root that each watched directory is under.
+	roots  map[string]string
+	events chan watchEvent
+	done   chan struct{}
+}
+
+// newDirWatcher starts watching the directories under each --root, whose
+// changes are sent to the events of the dirWatcher until it is closed.
+func (co *checkOptions) newDirWatcher() (*dirWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	dw := &dirWatcher{
+		co:      co,
+		watcher: watcher,
+		roots:   make(map[string]string),
+		events:  make(chan watchEvent),
+		done:    make(chan struct{}),
+	}
+	for _, root := range co.Roots {
+		if err := dw.add(root, root, nil); err != nil {
+			watcher.Close()
+			return nil, err
+		}
+	}
+	go dw.run()
+	return dw, nil
+}
+
+// Close stops watching, after which no more events are sent.  It may only
+// be called once.
+func (dw *dirWatcher) Close() error {
+	close(dw.done)
+	return dw.watcher.Close()
+}
+
+// send sends e, and returns whether it was sent before we were closed.
+func (dw *dirWatcher) send(e watchEvent) bool {
+	select {
+	case dw.events <- e:
+		return true
+	case <-dw.done:
+		return false
+	}
+}
+
+// add watches dir and the directories under it that we would descend
+// into from root, and calls found with the files in them, if it is
+// non-nil.
+func (dw *dirWatcher) add(root, dir string, found func(file string)) error {
+	walk := filepath.Walk
+	if dw.co.FollowSymlinks {
+		walk = walkFollowing
+	}
+	return walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			if file == root {
+				return err
+			}
+			// It may well have been removed already.
+			return nil
+		}
+		if !info.IsDir() {
+			if found != nil {
+				found(file)
+			}
+			return nil
+		}
+		path, err := dw.co.rel(root, file)
+		if err != nil {
+			return nil
+		}
+		if path != "." && dw.co.excludedDir(path) != "" {
+			return filepath.SkipDir
+		}
+		if dw.co.MaxDepth > 0 && depth(root, file) >= dw.co.MaxDepth {
+			return filepath.SkipDir
+		}
+		if err := dw.watcher.Add(file); err != nil {
+			if file == root {
+				return err
+			}
+			dw.co.log.logf(warnLevel, path, "cannot watch for changes: %v", err)
+			return filepath.SkipDir
+		}
+		dw.roots[file] = root
+		return nil
+	})
+}
+
+// run sends the changes to the files in the watched directories as
+// events, and watches the directories that are created, until closed.
+func (dw *dirWatcher) run() {
+	defer close(dw.events)
+	for {
+		select {
+		case ev, ok := <-dw.watcher.Events:
+			if !ok {
+				return
+			}
+			root, ok := dw.roots[filepath.Dir(ev.Name)]
+			if !ok || ev.Op == fsnotify.Chmod {
+				continue
+			}
+			if ev.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+				// The watches of removed directories are dropped.
+				delete(dw.roots, ev.Name)
+				if !dw.send(watchEvent{root: root, file: ev.Name, removed: true}) {
+					return
+				}
+				continue
+			}
+			if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+				if ev.Op&fsnotify.Create != 0 {
+					var files []string
+					dw.add(root, ev.Name, func(file string) { files = append(files, file) })
+					for _, file := range files {
+						if !dw.send(watchEvent{root: root, file: file}) {
+							return
+						}
+					}
+				}
+				continue
+			}
+			if !dw.send(watchEvent{root: root, file: ev.Name}) {
+				return
+			}
+		case err, ok := <-dw.watcher.Errors:
+			if !ok {
+				return
+			}
+			dw.co.log.logf(warnLevel, "", "watching for changes: %v", err)
+		}
+	}
+}
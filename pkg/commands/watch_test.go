@@ -0,0 +1,199 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	good, err := ioutil.ReadFile("testdata/old.good.mm")
+	if err != nil {
+		t.Fatalf("ReadFile() = %v", err)
+	}
+	write := func(name string, content []byte) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			t.Fatalf("WriteFile() = %v", err)
+		}
+	}
+	write("good.mm", good)
+	write("bad.mm", []byte("package bad\n"))
+
+	events := make(chan watchEvent)
+	ticks := make(chan time.Time)
+	interrupt := make(chan os.Signal)
+	co := &checkOptions{}
+	cmd := &cobra.Command{
+		PreRunE: co.PreRunE,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return co.watch(cmd, events, ticks, interrupt)
+		},
+	}
+	co.AddFlags(cmd)
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.SetArgs([]string{
+		"--boilerplate", "testdata/boilerplate.mm.txt",
+		"--file-extension", "mm",
+		"--root", dir,
+	})
+	done := make(chan error)
+	go func() {
+		done <- cmd.Execute()
+	}()
+
+	// Each event and tick is only received once the previous one is
+	// handled.
+	tick := func() {
+		ticks <- time.Now()
+	}
+	notify := func(name string, removed bool) {
+		events <- watchEvent{root: dir, file: filepath.Join(dir, name), removed: removed}
+	}
+	tick()
+
+	// Fixing a file re-checks it, once it stops changing.
+	write("bad.mm", good)
+	notify("bad.mm", false)
+	tick()
+	tick()
+
+	// So does creating one, while deleting one is not checked.  Nor are
+	// files we don't check.
+	write("new.mm", []byte("package new\n"))
+	notify("new.mm", false)
+	if err := os.Remove(filepath.Join(dir, "good.mm")); err != nil {
+		t.Fatalf("Remove() = %v", err)
+	}
+	notify("good.mm", true)
+	write("README", []byte("readme\n"))
+	notify("README", false)
+	tick()
+	tick()
+
+	// A file that keeps changing waits until it stops.
+	write("bad.mm", []byte("package bad\n"))
+	notify("bad.mm", false)
+	tick()
+	notify("bad.mm", false)
+	tick()
+	tick()
+
+	// Nothing has changed since.
+	tick()
+	close(interrupt)
+	if err := <-done; err != nil {
+		t.Errorf("Execute() = %v", err)
+	}
+
+	if got, want := stderr.String(), strings.Join([]string{
+		"checked 2 files, 1 violations in 1 files",
+		"checked 1 files, 0 violations in 0 files",
+		"checked 1 files, 1 violations in 1 files",
+		"checked 1 files, 1 violations in 1 files",
+		"",
+	}, "\n"); got != want {
+		t.Errorf("stderr = %q, wanted %q", got, want)
+	}
+	got := stdout.String()
+	if !strings.HasPrefix(got, "bad.mm:1: missing boilerplate") || !strings.Contains(got, "\nnew.mm:1: missing boilerplate") {
+		t.Errorf("stdout = %q, wanted violations in bad.mm and then new.mm", got)
+	}
+}
+
+func TestDirWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "boilerplate-check")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{
+		"a.go":          "package a\n",
+		"vendor/b.go":   "package b\n",
+		"sub/deep/c.go": "package c\n",
+	})
+
+	co := &checkOptions{
+		Roots:       []string{dir},
+		ExcludeDirs: []string{"vendor"},
+		MaxDepth:    2,
+	}
+	if co.excludeDirs, err = compileExcludeDirs(co.ExcludeDirs); err != nil {
+		t.Fatalf("compileExcludeDirs() = %v", err)
+	}
+	dw, err := co.newDirWatcher()
+	if err != nil {
+		t.Fatalf("newDirWatcher() = %v", err)
+	}
+	defer dw.Close()
+
+	// await waits for an event for name, which may follow others, e.g.
+	// since a change may be reported more than once.
+	unwatched := map[string]bool{
+		filepath.Join(dir, "vendor", "b.go"):      true,
+		filepath.Join(dir, "sub", "deep", "c.go"): true,
+	}
+	await := func(name string, removed bool) {
+		t.Helper()
+		want := watchEvent{root: dir, file: filepath.Join(dir, name), removed: removed}
+		for {
+			select {
+			case e := <-dw.events:
+				if unwatched[e.file] {
+					t.Errorf("event = %+v, wanted none for unwatched directories", e)
+				}
+				if e == want {
+					return
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatalf("timed out waiting for %+v", want)
+			}
+		}
+	}
+
+	writeFiles(t, dir, map[string]string{"a.go": "package a // changed\n"})
+	await("a.go", false)
+
+	// Excluded directories, and those below --max-depth, aren't watched.
+	writeFiles(t, dir, map[string]string{
+		"vendor/b.go":   "package b // changed\n",
+		"sub/deep/c.go": "package c // changed\n",
+	})
+	// The files in new directories are reported, since they may have
+	// been written before the directory was watched.
+	writeFiles(t, dir, map[string]string{"new/d.go": "package d\n"})
+	await("new/d.go", false)
+
+	if err := os.Remove(filepath.Join(dir, "a.go")); err != nil {
+		t.Fatalf("Remove() = %v", err)
+	}
+	await("a.go", true)
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// matchYearRange matches a --year-range, whose end may be left off.
+var matchYearRange = regexp.MustCompile(`^([0-9]{4})-([0-9]{4})?$`)
+
+// parseYearRange parses a --year-range of the form MIN-MAX, returning the
+// first and last years allowed, or MIN-, for which the last year is zero,
+// meaning the current year.
+func parseYearRange(value string) (int, int, error) {
+	m := matchYearRange.FindStringSubmatch(value)
+	if m == nil {
+		return 0, 0, fmt.Errorf("--year-range %q must be of the form MIN-MAX, or MIN- to end in the current year", value)
+	}
+	// The years are four digits, so these cannot fail.
+	min, _ := strconv.Atoi(m[1])
+	if m[2] == "" {
+		return min, 0, nil
+	}
+	max, _ := strconv.Atoi(m[2])
+	if max < min {
+		return 0, 0, fmt.Errorf("--year-range %q may not end before it starts", value)
+	}
+	return min, max, nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2020 Matt Moore
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import "testing"
+
+func TestParseYearRange(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantMin int
+		wantMax int
+		wantErr string
+	}{{
+		value:   "2015-2025",
+		wantMin: 2015,
+		wantMax: 2025,
+	}, {
+		value:   "2020-2020",
+		wantMin: 2020,
+		wantMax: 2020,
+	}, {
+		value:   "2015-",
+		wantMin: 2015,
+	}, {
+		value:   "2015",
+		wantErr: `--year-range "2015" must be of the form MIN-MAX, or MIN- to end in the current year`,
+	}, {
+		value:   "15-25",
+		wantErr: `--year-range "15-25" must be of the form MIN-MAX, or MIN- to end in the current year`,
+	}, {
+		value:   "2025-2015",
+		wantErr: `--year-range "2025-2015" may not end before it starts`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			min, max, err := parseYearRange(test.value)
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Errorf("parseYearRange() = %v, wanted %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseYearRange() = %v", err)
+			}
+			if min != test.wantMin || max != test.wantMax {
+				t.Errorf("parseYearRange() = %d, %d, wanted %d, %d", min, max, test.wantMin, test.wantMax)
+			}
+		})
+	}
+}